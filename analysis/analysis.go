@@ -0,0 +1,201 @@
+// Package analysis turns metric snapshots into upgrade recommendations.
+//
+// Each check is a named Rule (for example "memory.swap") that produces at
+// most one Recommendation per snapshot. Callers choose which rules run via
+// Options; the package has no terminal or I/O side effects.
+package analysis
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/xmarkclx/bottleneck-check/metrics"
+)
+
+// Severity ranks how urgently a recommendation should be acted on
+type Severity string
+
+const (
+	SeverityLow      Severity = "LOW"
+	SeverityMedium   Severity = "MEDIUM"
+	SeverityHigh     Severity = "HIGH"
+	SeverityCritical Severity = "CRITICAL"
+)
+
+// Severities lists all severities from most to least urgent
+var Severities = []Severity{SeverityCritical, SeverityHigh, SeverityMedium, SeverityLow}
+
+// Rank orders severities: 1 for LOW up to 4 for CRITICAL, 0 if unknown
+func (s Severity) Rank() int {
+	switch s {
+	case SeverityLow:
+		return 1
+	case SeverityMedium:
+		return 2
+	case SeverityHigh:
+		return 3
+	case SeverityCritical:
+		return 4
+	}
+	return 0
+}
+
+// ParseSeverity accepts a severity name in any case
+func ParseSeverity(s string) (Severity, error) {
+	for _, sev := range Severities {
+		if strings.EqualFold(string(sev), s) {
+			return sev, nil
+		}
+	}
+	return "", fmt.Errorf("unknown severity %q", s)
+}
+
+// Recommendation represents an upgrade suggestion
+type Recommendation struct {
+	Rule string // ID of the rule that produced it, e.g. "memory.swap"
+	// Subject identifies what the recommendation is about when a rule can
+	// fire more than once, e.g. the PID of a leaking process
+	Subject    string
+	Component  string // "CPU", "Memory" or "GPU"
+	Severity   Severity
+	Reason     string
+	Suggestion string
+	// Since and Duration report how long a sustained condition has held.
+	// They are only set by an Evaluator.
+	Since    time.Time
+	Duration time.Duration
+	// Evidence holds the readings the recommendation is based on, keyed by
+	// metric name or a rule-specific name such as "eta_seconds"
+	Evidence map[string]float64
+}
+
+// ID identifies a recommendation across evaluations: the rule ID, plus the
+// subject when there is one, e.g. "process.leak:1234"
+func (r Recommendation) ID() string {
+	if r.Subject != "" {
+		return r.Rule + ":" + r.Subject
+	}
+	return r.Rule
+}
+
+// Rule is a single named check against a snapshot
+type Rule struct {
+	ID        string
+	Component string
+	// Sustained marks rules on fluctuating metrics, which an Evaluator only
+	// raises once they hold over a time window. Other rules describe fixed
+	// properties such as installed RAM and apply immediately.
+	Sustained bool
+	// Metrics names the values the rule reads; they are attached to its
+	// recommendations as evidence
+	Metrics []string
+	// Check returns a recommendation and true when the rule fires. Rule and
+	// Component are filled in by Analyze.
+	Check func(m *metrics.SystemMetrics, t *Thresholds) (Recommendation, bool)
+}
+
+// Options selects which rules Analyze runs
+type Options struct {
+	// Rules lists the rule IDs to run. Empty means all.
+	Rules []string
+	// Thresholds overrides the usage limits; nil means DefaultThresholds
+	Thresholds *Thresholds
+}
+
+func (o Options) thresholds() *Thresholds {
+	if o.Thresholds != nil {
+		return o.Thresholds
+	}
+	t := DefaultThresholds()
+	return &t
+}
+
+// Rules returns all built-in rules in evaluation order
+func Rules() []Rule {
+	var rules []Rule
+	rules = append(rules, cpuRules...)
+	rules = append(rules, memoryRules...)
+	rules = append(rules, gpuRules...)
+	return rules
+}
+
+// Analyze runs the selected rules against a snapshot
+func Analyze(m *metrics.SystemMetrics, opts Options) ([]Recommendation, error) {
+	rules, err := selectRules(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	thresholds := opts.thresholds()
+	var recommendations []Recommendation
+	for _, rule := range rules {
+		rec, ok := rule.Check(m, thresholds)
+		if !ok {
+			continue
+		}
+		rec.Rule = rule.ID
+		rec.Component = rule.Component
+		rec.Evidence = evidence(rule, m)
+		recommendations = append(recommendations, rec)
+	}
+	return recommendations, nil
+}
+
+// evidence picks the values a rule reads out of a snapshot
+func evidence(rule Rule, m *metrics.SystemMetrics) map[string]float64 {
+	if len(rule.Metrics) == 0 {
+		return nil
+	}
+	values := m.Values()
+	picked := make(map[string]float64, len(rule.Metrics))
+	for _, name := range rule.Metrics {
+		if v, ok := values[name]; ok {
+			picked[name] = v
+		}
+	}
+	return picked
+}
+
+func selectRules(opts Options) ([]Rule, error) {
+	all := Rules()
+	if len(opts.Rules) == 0 {
+		return all, nil
+	}
+
+	wanted := make(map[string]bool)
+	for _, id := range opts.Rules {
+		wanted[id] = true
+	}
+	var rules []Rule
+	for _, rule := range all {
+		if wanted[rule.ID] {
+			rules = append(rules, rule)
+			delete(wanted, rule.ID)
+		}
+	}
+	for id := range wanted {
+		return nil, fmt.Errorf("unknown rule %q", id)
+	}
+	return rules, nil
+}
+
+// GroupBySeverity splits recommendations by severity, preserving order
+func GroupBySeverity(recommendations []Recommendation) map[Severity][]Recommendation {
+	groups := make(map[Severity][]Recommendation)
+	for _, rec := range recommendations {
+		groups[rec.Severity] = append(groups[rec.Severity], rec)
+	}
+	return groups
+}
+
+// Worst returns the most urgent severity present, or "" if there is none
+func Worst(recommendations []Recommendation) Severity {
+	var worst Severity
+	for _, rec := range recommendations {
+		if rec.Severity.Rank() > worst.Rank() {
+			worst = rec.Severity
+		}
+	}
+	return worst
+}
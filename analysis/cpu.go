@@ -0,0 +1,62 @@
+package analysis
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/xmarkclx/bottleneck-check/metrics"
+)
+
+var cpuRules = []Rule{
+	{ID: "cpu.usage", Component: "CPU", Sustained: true, Metrics: []string{metrics.MetricCPUUsage}, Check: checkCPUUsage},
+	{ID: "cpu.load", Component: "CPU", Sustained: true, Metrics: []string{metrics.MetricLoad1, metrics.MetricCPUCores}, Check: checkCPULoad},
+	{ID: "cpu.generation", Component: "CPU", Check: checkCPUGeneration},
+}
+
+func checkCPUUsage(m *metrics.SystemMetrics, t *Thresholds) (Recommendation, bool) {
+	if m.CPUUsage > t.CPUCritical {
+		return Recommendation{
+			Severity:   SeverityCritical,
+			Reason:     fmt.Sprintf("CPU usage is very high (%.1f%%)", m.CPUUsage),
+			Suggestion: "Consider upgrading to a faster CPU or adding more cores. Close unnecessary applications.",
+		}, true
+	} else if m.CPUUsage > t.CPUHigh {
+		return Recommendation{
+			Severity:   SeverityHigh,
+			Reason:     fmt.Sprintf("CPU usage is high (%.1f%%)", m.CPUUsage),
+			Suggestion: "Monitor CPU usage patterns. Consider CPU upgrade if consistently high.",
+		}, true
+	}
+	return Recommendation{}, false
+}
+
+// checkCPULoad compares the load average to the core count
+func checkCPULoad(m *metrics.SystemMetrics, t *Thresholds) (Recommendation, bool) {
+	if m.CPUCores == 0 || m.LoadAverage[0] <= float64(m.CPUCores)*t.LoadPerCore {
+		return Recommendation{}, false
+	}
+	return Recommendation{
+		Severity:   SeverityHigh,
+		Reason:     fmt.Sprintf("Load average (%.2f) is high for %d cores", m.LoadAverage[0], m.CPUCores),
+		Suggestion: "System is overloaded. Consider upgrading to more CPU cores or optimizing running processes.",
+	}, true
+}
+
+// checkCPUGeneration flags old CPU architectures (basic heuristic)
+func checkCPUGeneration(m *metrics.SystemMetrics, _ *Thresholds) (Recommendation, bool) {
+	model := strings.ToLower(m.CPUModel)
+	if strings.Contains(model, "intel") &&
+		(strings.Contains(model, "core 2") ||
+			strings.Contains(model, "core i3") ||
+			strings.Contains(model, "core i5") &&
+				!strings.Contains(model, "11th") &&
+				!strings.Contains(model, "12th") &&
+				!strings.Contains(model, "13th")) {
+		return Recommendation{
+			Severity:   SeverityMedium,
+			Reason:     "CPU model appears to be older generation",
+			Suggestion: "Consider upgrading to a newer CPU for better performance and efficiency.",
+		}, true
+	}
+	return Recommendation{}, false
+}
@@ -0,0 +1,59 @@
+package analysis
+
+import (
+	"strings"
+
+	"github.com/xmarkclx/bottleneck-check/metrics"
+)
+
+// Basic GPU analysis based on model name
+var gpuRules = []Rule{
+	{ID: "gpu.integrated", Component: "GPU", Check: checkIntegratedGPU},
+	{ID: "gpu.legacy", Component: "GPU", Check: checkLegacyGPU},
+	{ID: "gpu.apple", Component: "GPU", Check: checkAppleGPU},
+}
+
+func gpuModel(m *metrics.SystemMetrics) string {
+	if m.GPUModel == "" || m.GPUModel == "Unknown GPU" {
+		return ""
+	}
+	return strings.ToLower(m.GPUModel)
+}
+
+// checkIntegratedGPU checks for integrated vs dedicated GPU
+func checkIntegratedGPU(m *metrics.SystemMetrics, _ *Thresholds) (Recommendation, bool) {
+	gpuLower := gpuModel(m)
+	if strings.Contains(gpuLower, "intel") && (strings.Contains(gpuLower, "hd") || strings.Contains(gpuLower, "iris")) {
+		return Recommendation{
+			Severity:   SeverityMedium,
+			Reason:     "Using integrated Intel graphics",
+			Suggestion: "For gaming or graphics-intensive work, consider a system with dedicated GPU.",
+		}, true
+	}
+	return Recommendation{}, false
+}
+
+// checkLegacyGPU checks for old AMD integrated graphics
+func checkLegacyGPU(m *metrics.SystemMetrics, _ *Thresholds) (Recommendation, bool) {
+	gpuLower := gpuModel(m)
+	if strings.Contains(gpuLower, "radeon") && (strings.Contains(gpuLower, "r5") || strings.Contains(gpuLower, "r7")) {
+		return Recommendation{
+			Severity:   SeverityMedium,
+			Reason:     "Using older integrated AMD graphics",
+			Suggestion: "Consider upgrading to a system with newer integrated or dedicated graphics.",
+		}, true
+	}
+	return Recommendation{}, false
+}
+
+// checkAppleGPU notes Apple Silicon graphics
+func checkAppleGPU(m *metrics.SystemMetrics, _ *Thresholds) (Recommendation, bool) {
+	if strings.Contains(gpuModel(m), "apple") {
+		return Recommendation{
+			Severity:   SeverityLow,
+			Reason:     "Using Apple Silicon integrated GPU",
+			Suggestion: "Apple Silicon GPUs are generally excellent. Consider Mac Studio/Pro for intensive GPU work.",
+		}, true
+	}
+	return Recommendation{}, false
+}
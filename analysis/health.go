@@ -0,0 +1,50 @@
+package analysis
+
+// Health statuses reported by HealthSummary
+const (
+	HealthOK       = "ok"
+	HealthDegraded = "degraded"
+	HealthCritical = "critical"
+)
+
+// healthPenalty is how many points one recommendation of each severity
+// takes off a perfect score of 100
+var healthPenalty = map[Severity]int{
+	SeverityCritical: 40,
+	SeverityHigh:     20,
+	SeverityMedium:   10,
+	SeverityLow:      2,
+}
+
+// HealthSummary condenses a set of recommendations into one score
+type HealthSummary struct {
+	// Score runs from 100, nothing to act on, down to 0
+	Score int
+	// Status is HealthOK, HealthDegraded with high or medium findings, or
+	// HealthCritical with at least one critical finding
+	Status string
+	// Worst is the most urgent severity, empty if there are none
+	Worst  Severity
+	Counts map[Severity]int
+}
+
+// Health scores a set of recommendations
+func Health(recommendations []Recommendation) HealthSummary {
+	h := HealthSummary{Score: 100, Status: HealthOK, Counts: make(map[Severity]int, len(Severities))}
+	for _, sev := range Severities {
+		h.Counts[sev] = 0
+	}
+	for _, rec := range recommendations {
+		h.Counts[rec.Severity]++
+		h.Score -= healthPenalty[rec.Severity]
+	}
+	h.Score = max(h.Score, 0)
+	h.Worst = Worst(recommendations)
+	switch {
+	case h.Worst == SeverityCritical:
+		h.Status = HealthCritical
+	case h.Worst.Rank() >= SeverityMedium.Rank():
+		h.Status = HealthDegraded
+	}
+	return h
+}
@@ -0,0 +1,169 @@
+package analysis
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/xmarkclx/bottleneck-check/metrics"
+)
+
+var memoryRules = []Rule{
+	{ID: "memory.usage", Component: "Memory", Sustained: true, Metrics: []string{metrics.MetricMemoryUsedPercent, metrics.MetricMemoryUsedBytes, metrics.MetricMemoryTotalBytes}, Check: checkMemoryUsage},
+	{ID: "memory.swap", Component: "Memory", Sustained: true, Metrics: []string{metrics.MetricSwapUsedBytes, metrics.MetricMemoryUsedPercent}, Check: checkSwap},
+	{ID: "memory.pressure", Component: "Memory", Sustained: true, Metrics: []string{metrics.MetricMemoryPressure}, Check: checkMemoryPressure},
+	{ID: "memory.capacity", Component: "Memory", Metrics: []string{metrics.MetricMemoryTotalBytes, metrics.MetricMemoryUsedPercent, metrics.MetricSwapUsedBytes}, Check: checkMemoryCapacity},
+}
+
+const gib = 1024 * 1024 * 1024
+
+func checkMemoryUsage(m *metrics.SystemMetrics, t *Thresholds) (Recommendation, bool) {
+	if m.MemoryTotal == 0 {
+		return Recommendation{}, false
+	}
+	memUsagePercent := m.MemoryPercent()
+	currentRAMGB := float64(m.MemoryTotal) / gib
+	swapUsageGB := float64(m.SwapUsed) / gib
+	usedRAMGB := (memUsagePercent / 100) * currentRAMGB
+
+	// Calculate recommended RAM based on usage patterns
+	recommendedRAM := RecommendedRAM(currentRAMGB, memUsagePercent, swapUsageGB)
+
+	if memUsagePercent > t.MemoryCritical {
+		// For critical usage, be more conservative
+		conservativeRAM := ConservativeRAM(currentRAMGB, memUsagePercent, swapUsageGB)
+		return Recommendation{
+			Severity:   SeverityCritical,
+			Reason:     fmt.Sprintf("Memory usage is critical (%.1fGB/%.1fGB = %.1f%% used)", usedRAMGB, currentRAMGB, memUsagePercent),
+			Suggestion: fmt.Sprintf("Urgently need more RAM. Minimum upgrade: %.0fGB (gives you %.1fGB headroom). Close applications immediately.", conservativeRAM, conservativeRAM-usedRAMGB),
+		}, true
+	} else if memUsagePercent > t.MemoryHigh {
+		return Recommendation{
+			Severity:   SeverityHigh,
+			Reason:     fmt.Sprintf("Memory usage is high (%.1fGB/%.1fGB = %.1f%% used)", usedRAMGB, currentRAMGB, memUsagePercent),
+			Suggestion: fmt.Sprintf("Consider upgrading to %.0fGB RAM to prevent slowdowns (provides %.1fGB buffer).", recommendedRAM, recommendedRAM-usedRAMGB),
+		}, true
+	} else if memUsagePercent > t.MemoryMedium {
+		return Recommendation{
+			Severity:   SeverityMedium,
+			Reason:     fmt.Sprintf("Memory usage is moderate (%.1fGB/%.1fGB = %.1f%% used)", usedRAMGB, currentRAMGB, memUsagePercent),
+			Suggestion: fmt.Sprintf("Monitor memory usage. Consider %.0fGB for intensive tasks.", recommendedRAM),
+		}, true
+	}
+	return Recommendation{}, false
+}
+
+func checkSwap(m *metrics.SystemMetrics, t *Thresholds) (Recommendation, bool) {
+	if m.SwapUsed == 0 || m.MemoryTotal == 0 {
+		return Recommendation{}, false
+	}
+	memUsagePercent := m.MemoryPercent()
+	currentRAMGB := float64(m.MemoryTotal) / gib
+	swapUsageGB := float64(m.SwapUsed) / gib
+	usedRAMGB := (memUsagePercent / 100) * currentRAMGB
+	totalMemoryNeed := usedRAMGB + swapUsageGB
+
+	// Calculate both conservative and optimal recommendations
+	conservativeRAM := ConservativeRAM(currentRAMGB, memUsagePercent, swapUsageGB)
+	optimalRAM := RecommendedRAM(currentRAMGB, memUsagePercent, swapUsageGB)
+
+	if swapUsageGB > t.SwapHighGB {
+		return Recommendation{
+			Severity:   SeverityHigh,
+			Reason:     fmt.Sprintf("Heavy swap usage (%.1fGB) - system is using disk as memory", swapUsageGB),
+			Suggestion: fmt.Sprintf("Add more RAM immediately. Memory needed: %.1fGB (%.1fGB used + %.1fGB swap). Minimum: %.0fGB, Optimal: %.0fGB for headroom.", totalMemoryNeed, usedRAMGB, swapUsageGB, conservativeRAM, optimalRAM),
+		}, true
+	} else if swapUsageGB > t.SwapMediumGB {
+		return Recommendation{
+			Severity:   SeverityMedium,
+			Reason:     fmt.Sprintf("Moderate swap usage (%.1fGB)", swapUsageGB),
+			Suggestion: fmt.Sprintf("Consider upgrading to %.0fGB RAM to eliminate swap (total need: %.1fGB with buffer).", conservativeRAM, totalMemoryNeed*1.15),
+		}, true
+	}
+	return Recommendation{}, false
+}
+
+func checkMemoryPressure(m *metrics.SystemMetrics, _ *Thresholds) (Recommendation, bool) {
+	if m.MemPressure == "critical" || m.MemPressure == "urgent" {
+		return Recommendation{
+			Severity:   SeverityCritical,
+			Reason:     fmt.Sprintf("Memory pressure is %s", m.MemPressure),
+			Suggestion: "System is under severe memory pressure. Upgrade RAM immediately.",
+		}, true
+	} else if m.MemPressure == "warning" {
+		return Recommendation{
+			Severity:   SeverityHigh,
+			Reason:     "Memory pressure warning detected",
+			Suggestion: "Consider upgrading RAM to prevent performance issues.",
+		}, true
+	}
+	return Recommendation{}, false
+}
+
+// checkMemoryCapacity checks the total memory amount
+func checkMemoryCapacity(m *metrics.SystemMetrics, _ *Thresholds) (Recommendation, bool) {
+	if m.MemoryTotal == 0 {
+		return Recommendation{}, false
+	}
+	currentRAMGB := float64(m.MemoryTotal) / gib
+
+	if currentRAMGB < 8 {
+		return Recommendation{
+			Severity:   SeverityHigh,
+			Reason:     fmt.Sprintf("Total RAM (%.1fGB) is below modern standards", currentRAMGB),
+			Suggestion: fmt.Sprintf("Upgrade to at least 16GB RAM for modern applications (current: %.1fGB → recommended: 16GB+).", currentRAMGB),
+		}, true
+	} else if currentRAMGB < 16 {
+		return Recommendation{
+			Severity:   SeverityMedium,
+			Reason:     fmt.Sprintf("Total RAM (%.1fGB) may be limiting for intensive tasks", currentRAMGB),
+			Suggestion: fmt.Sprintf("Consider upgrading to 32GB RAM for development/content creation (current: %.1fGB → recommended: 32GB).", currentRAMGB),
+		}, true
+	} else if currentRAMGB < 32 && (m.MemoryPercent() > 80 || m.SwapUsed > 0) {
+		// For systems with 16-32GB that are still running out of memory
+		return Recommendation{
+			Severity:   SeverityMedium,
+			Reason:     fmt.Sprintf("Despite having %.1fGB RAM, still experiencing memory pressure", currentRAMGB),
+			Suggestion: fmt.Sprintf("Upgrade to 64GB RAM for heavy workloads (current: %.1fGB → recommended: 64GB).", currentRAMGB),
+		}, true
+	}
+	return Recommendation{}, false
+}
+
+// RecommendedRAM suggests optimal RAM in GB based on current usage patterns
+func RecommendedRAM(currentRAMGB, memUsagePercent, swapUsageGB float64) float64 {
+	// More conservative approach: actual memory need + reasonable buffer
+	usedRAMGB := (memUsagePercent / 100) * currentRAMGB
+	totalMemoryNeed := usedRAMGB + swapUsageGB
+
+	// Add a reasonable buffer (25%) but don't be overly generous
+	targetRAM := totalMemoryNeed * 1.25
+
+	// Round up to common RAM sizes, but prefer the next logical step
+	commonSizes := []float64{8, 16, 24, 32, 48, 64, 96, 128, 192, 256}
+
+	for _, size := range commonSizes {
+		if targetRAM <= size {
+			return size
+		}
+	}
+
+	// If we need more than 256GB, round up to nearest 32GB
+	return math.Ceil(targetRAM/32) * 32
+}
+
+// ConservativeRAM provides a more conservative estimate in GB
+func ConservativeRAM(currentRAMGB, memUsagePercent, swapUsageGB float64) float64 {
+	// Simple calculation: current usage + swap + 15% buffer
+	usedRAMGB := (memUsagePercent / 100) * currentRAMGB
+	totalNeed := usedRAMGB + swapUsageGB
+	conservativeTarget := totalNeed * 1.15
+
+	// Round to next common size
+	commonSizes := []float64{16, 24, 32, 48, 64, 96, 128}
+	for _, size := range commonSizes {
+		if conservativeTarget <= size {
+			return size
+		}
+	}
+	return 128
+}
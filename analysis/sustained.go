@@ -0,0 +1,222 @@
+package analysis
+
+import (
+	"time"
+
+	"github.com/xmarkclx/bottleneck-check/metrics"
+)
+
+// EvaluatorOptions configures sustained-condition detection
+type EvaluatorOptions struct {
+	// Window is how far back conditions are evaluated
+	Window time.Duration
+	// Warmup is the minimum history needed before a sustained rule can fire
+	Warmup time.Duration
+	// RaiseRatio is the share of the window a condition must hold for it to
+	// be raised or escalated, e.g. 0.8 for "80% of the last 5 minutes"
+	RaiseRatio float64
+	// ClearRatio is the share below which an active condition is lowered or
+	// resolved. Keeping it under RaiseRatio stops alerts from flapping.
+	ClearRatio float64
+	// Rules selects which rules run
+	Rules Options
+}
+
+// Defaults used when EvaluatorOptions leaves a field zero
+const (
+	DefaultWindow     = 5 * time.Minute
+	DefaultWarmup     = time.Minute
+	DefaultRaiseRatio = 0.8
+	DefaultClearRatio = 0.5
+)
+
+// Evaluator raises recommendations for sustained rules only when their
+// condition holds over a time window, with hysteresis between raising and
+// clearing. Non-sustained rules are checked against the newest sample.
+// An Evaluator keeps state between calls and is not safe for concurrent use.
+type Evaluator struct {
+	opts       EvaluatorOptions
+	rules      []Rule
+	thresholds *Thresholds
+	active     map[string]*alertState
+}
+
+type alertState struct {
+	rank  int
+	since time.Time
+}
+
+// NewEvaluator returns an Evaluator for the given options
+func NewEvaluator(opts EvaluatorOptions) (*Evaluator, error) {
+	if opts.Window <= 0 {
+		opts.Window = DefaultWindow
+	}
+	if opts.Warmup <= 0 {
+		opts.Warmup = min(DefaultWarmup, opts.Window)
+	}
+	if opts.RaiseRatio <= 0 {
+		opts.RaiseRatio = DefaultRaiseRatio
+	}
+	if opts.ClearRatio <= 0 {
+		opts.ClearRatio = min(DefaultClearRatio, opts.RaiseRatio)
+	}
+
+	rules, err := selectRules(opts.Rules)
+	if err != nil {
+		return nil, err
+	}
+	return &Evaluator{
+		opts:       opts,
+		rules:      rules,
+		thresholds: opts.Rules.thresholds(),
+		active:     make(map[string]*alertState),
+	}, nil
+}
+
+// Window returns the evaluation window
+func (e *Evaluator) Window() time.Duration {
+	return e.opts.Window
+}
+
+// Evaluate updates alert state from samples (oldest first) and returns the
+// active recommendations. Samples older than the window are ignored.
+func (e *Evaluator) Evaluate(samples []metrics.SystemMetrics) []Recommendation {
+	if len(samples) == 0 {
+		return nil
+	}
+	latest := &samples[len(samples)-1]
+	window := samplesInWindow(samples, e.opts.Window)
+	weights, covered := sampleWeights(window)
+	warm := covered > 0 && covered >= e.opts.Warmup
+
+	var recommendations []Recommendation
+	for _, rule := range e.rules {
+		if !rule.Sustained {
+			if rec, ok := rule.Check(latest, e.thresholds); ok {
+				rec.Rule = rule.ID
+				rec.Component = rule.Component
+				rec.Evidence = evidence(rule, latest)
+				recommendations = append(recommendations, rec)
+			}
+			continue
+		}
+
+		// Time spent at or above each severity rank, plus the newest
+		// recommendation and first firing time per rank
+		var held [5]time.Duration
+		var newest [5]*Recommendation
+		var firstFired [5]time.Time
+		for i := range window {
+			rec, ok := rule.Check(&window[i], e.thresholds)
+			if !ok {
+				continue
+			}
+			rank := rec.Severity.Rank()
+			for k := 1; k <= rank; k++ {
+				held[k] += weights[i]
+				if firstFired[k].IsZero() {
+					firstFired[k] = window[i].Timestamp
+				}
+			}
+			newest[rank] = &rec
+		}
+		ratio := func(rank int) float64 {
+			if covered <= 0 {
+				return 0
+			}
+			return float64(held[rank]) / float64(covered)
+		}
+
+		target := 0
+		if warm {
+			for k := 4; k >= 1; k-- {
+				if ratio(k) >= e.opts.RaiseRatio {
+					target = k
+					break
+				}
+			}
+		}
+		state := e.active[rule.ID]
+		if state != nil && target < state.rank {
+			// Hold the current level until it drops below the clear ratio,
+			// then step down to the highest level that still holds
+			target = 0
+			for k := state.rank; k >= 1; k-- {
+				if ratio(k) >= e.opts.ClearRatio {
+					target = k
+					break
+				}
+			}
+		}
+		if target == 0 {
+			delete(e.active, rule.ID)
+			continue
+		}
+		if state == nil {
+			state = &alertState{since: firstFired[target]}
+			e.active[rule.ID] = state
+		}
+		state.rank = target
+
+		rec := pickRecommendation(newest, target)
+		if rec == nil {
+			continue
+		}
+		out := *rec
+		out.Rule = rule.ID
+		out.Component = rule.Component
+		out.Severity = severityForRank(target)
+		out.Since = state.since
+		out.Duration = latest.Timestamp.Sub(state.since)
+		out.Evidence = evidence(rule, latest)
+		recommendations = append(recommendations, out)
+	}
+	return recommendations
+}
+
+// pickRecommendation prefers the newest recommendation at exactly rank,
+// falling back to the nearest higher one
+func pickRecommendation(newest [5]*Recommendation, rank int) *Recommendation {
+	for k := rank; k < len(newest); k++ {
+		if newest[k] != nil {
+			return newest[k]
+		}
+	}
+	return nil
+}
+
+func severityForRank(rank int) Severity {
+	for _, sev := range Severities {
+		if sev.Rank() == rank {
+			return sev
+		}
+	}
+	return ""
+}
+
+func samplesInWindow(samples []metrics.SystemMetrics, window time.Duration) []metrics.SystemMetrics {
+	cutoff := samples[len(samples)-1].Timestamp.Add(-window)
+	for i := range samples {
+		if samples[i].Timestamp.After(cutoff) {
+			return samples[i:]
+		}
+	}
+	return samples[len(samples)-1:]
+}
+
+// sampleWeights gives each sample the time until the next one; the newest
+// sample gets the previous gap. It also returns the total time covered.
+func sampleWeights(samples []metrics.SystemMetrics) ([]time.Duration, time.Duration) {
+	weights := make([]time.Duration, len(samples))
+	var covered time.Duration
+	for i := range samples {
+		switch {
+		case i+1 < len(samples):
+			weights[i] = samples[i+1].Timestamp.Sub(samples[i].Timestamp)
+		case i > 0:
+			weights[i] = weights[i-1]
+		}
+		covered += weights[i]
+	}
+	return weights, covered
+}
@@ -0,0 +1,137 @@
+package analysis
+
+import (
+	"testing"
+	"time"
+
+	"github.com/xmarkclx/bottleneck-check/metrics"
+)
+
+var testStart = time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+
+// cpuRun appends one sample a second with each CPU usage to samples
+func cpuRun(samples []metrics.SystemMetrics, usage float64, n int) []metrics.SystemMetrics {
+	for i := 0; i < n; i++ {
+		t := testStart.Add(time.Duration(len(samples)) * time.Second)
+		samples = append(samples, metrics.SystemMetrics{Timestamp: t, CPUUsage: usage, CPUCores: 4})
+	}
+	return samples
+}
+
+// evaluateEach feeds the history to e one sample at a time, as the monitor
+// does, and returns the cpu.usage severity after the last
+func evaluateEach(t *testing.T, e *Evaluator, samples []metrics.SystemMetrics) (Severity, *Recommendation) {
+	t.Helper()
+	var recs []Recommendation
+	for i := range samples {
+		recs = e.Evaluate(samples[:i+1])
+	}
+	for _, rec := range recs {
+		if rec.Rule == "cpu.usage" {
+			return rec.Severity, &rec
+		}
+	}
+	return "", nil
+}
+
+func newCPUEvaluator(t *testing.T) *Evaluator {
+	t.Helper()
+	e, err := NewEvaluator(EvaluatorOptions{
+		Window: time.Minute,
+		Warmup: 10 * time.Second,
+		Rules:  Options{Rules: []string{"cpu.usage"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return e
+}
+
+func TestEvaluatorSustained(t *testing.T) {
+	type run struct {
+		usage float64
+		n     int
+	}
+	tests := []struct {
+		name string
+		runs []run
+		want Severity
+	}{
+		{"idle", []run{{10, 60}}, ""},
+		{"single spike", []run{{10, 59}, {95, 1}}, ""},
+		{"short burst", []run{{10, 40}, {95, 20}}, ""},
+		{"sustained high", []run{{80, 60}}, SeverityHigh},
+		{"sustained critical", []run{{95, 60}}, SeverityCritical},
+		{"not during warm-up", []run{{95, 5}}, ""},
+		{"raised right after warm-up", []run{{95, 11}}, SeverityCritical},
+		{"holds above the clear ratio", []run{{80, 60}, {10, 20}}, SeverityHigh},
+		{"clears below the clear ratio", []run{{80, 60}, {10, 31}}, ""},
+		{"steps down to what still holds", []run{{95, 60}, {80, 31}}, SeverityHigh},
+		{"holds critical while mostly critical", []run{{95, 60}, {80, 20}}, SeverityCritical},
+		{"escalates", []run{{80, 60}, {95, 50}}, SeverityCritical},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var samples []metrics.SystemMetrics
+			for _, r := range tt.runs {
+				samples = cpuRun(samples, r.usage, r.n)
+			}
+			if got, _ := evaluateEach(t, newCPUEvaluator(t), samples); got != tt.want {
+				t.Errorf("severity = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEvaluatorHysteresisNeedsState(t *testing.T) {
+	// After 20 quiet seconds the condition held for two thirds of the
+	// window: enough to stay raised, not enough to be raised afresh
+	samples := cpuRun(cpuRun(nil, 80, 60), 10, 20)
+	e := newCPUEvaluator(t)
+	if got, _ := evaluateEach(t, e, samples); got != SeverityHigh {
+		t.Fatalf("with state: severity = %q, want HIGH", got)
+	}
+	recs := newCPUEvaluator(t).Evaluate(samples)
+	if len(recs) != 0 {
+		t.Errorf("fresh evaluator raised %v", recs)
+	}
+}
+
+func TestEvaluatorSince(t *testing.T) {
+	samples := cpuRun(cpuRun(nil, 10, 30), 80, 60)
+	_, rec := evaluateEach(t, newCPUEvaluator(t), samples)
+	if rec == nil {
+		t.Fatal("not raised")
+	}
+	// Raised once 80% of the window was high, and dated from the first
+	// high sample in the window then
+	first := testStart.Add(30 * time.Second)
+	if !rec.Since.Equal(first) {
+		t.Errorf("Since = %s, want %s", rec.Since, first)
+	}
+	last := samples[len(samples)-1].Timestamp
+	if rec.Duration != last.Sub(first) {
+		t.Errorf("Duration = %s, want %s", rec.Duration, last.Sub(first))
+	}
+}
+
+func TestEvaluatorUsesThresholds(t *testing.T) {
+	thresholds := DefaultThresholds()
+	thresholds.CPUHigh = 50
+	e, err := NewEvaluator(EvaluatorOptions{
+		Window: time.Minute,
+		Rules:  Options{Rules: []string{"cpu.usage"}, Thresholds: &thresholds},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := evaluateEach(t, e, cpuRun(nil, 60, 60)); got != SeverityHigh {
+		t.Errorf("severity = %q, want HIGH above a lowered threshold", got)
+	}
+}
+
+func TestNewEvaluatorUnknownRule(t *testing.T) {
+	if _, err := NewEvaluator(EvaluatorOptions{Rules: Options{Rules: []string{"cpu.nope"}}}); err == nil {
+		t.Error("no error for an unknown rule")
+	}
+}
@@ -0,0 +1,57 @@
+package analysis
+
+import "github.com/xmarkclx/bottleneck-check/metrics"
+
+// Thresholds holds the limits the usage rules compare against. A rule
+// fires when a value is strictly above its limit.
+type Thresholds struct {
+	CPUHigh     float64 `json:"cpu_high"`     // percent
+	CPUCritical float64 `json:"cpu_critical"` // percent
+	// LoadPerCore is the 1-minute load per core above which the system
+	// counts as overloaded
+	LoadPerCore float64 `json:"load_per_core"`
+
+	MemoryMedium   float64 `json:"memory_medium"`   // percent
+	MemoryHigh     float64 `json:"memory_high"`     // percent
+	MemoryCritical float64 `json:"memory_critical"` // percent
+
+	SwapMediumGB float64 `json:"swap_medium_gb"`
+	SwapHighGB   float64 `json:"swap_high_gb"`
+}
+
+// DefaultThresholds returns the built-in limits
+func DefaultThresholds() Thresholds {
+	return Thresholds{
+		CPUHigh:        70,
+		CPUCritical:    90,
+		LoadPerCore:    1.5,
+		MemoryMedium:   70,
+		MemoryHigh:     85,
+		MemoryCritical: 95,
+		SwapMediumGB:   0.5,
+		SwapHighGB:     2,
+	}
+}
+
+// Level is the limit above which a metric reaches a severity
+type Level struct {
+	Severity Severity
+	Above    float64
+}
+
+// Levels returns the severity levels for a history metric, least severe
+// first, or nil if no usage rule watches it
+func (t Thresholds) Levels(metric string) []Level {
+	switch metric {
+	case metrics.MetricCPUUsage:
+		return []Level{{SeverityHigh, t.CPUHigh}, {SeverityCritical, t.CPUCritical}}
+	case metrics.MetricMemoryUsedPercent:
+		return []Level{{SeverityMedium, t.MemoryMedium}, {SeverityHigh, t.MemoryHigh}, {SeverityCritical, t.MemoryCritical}}
+	case metrics.MetricSwapUsedBytes:
+		return []Level{{SeverityMedium, t.SwapMediumGB * gib}, {SeverityHigh, t.SwapHighGB * gib}}
+	case metrics.MetricMemoryPressure:
+		// Pressure levels are 0, 1 and 2 for normal, warning and critical
+		return []Level{{SeverityHigh, 0.5}, {SeverityCritical, 1.5}}
+	}
+	return nil
+}
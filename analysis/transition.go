@@ -0,0 +1,57 @@
+package analysis
+
+import "time"
+
+// TransitionKind says how a recommendation changed between two evaluations
+type TransitionKind string
+
+const (
+	// TransitionRaised is a recommendation that wasn't active before
+	TransitionRaised TransitionKind = "raised"
+	// TransitionEscalated is an active recommendation whose severity rose
+	TransitionEscalated TransitionKind = "escalated"
+	// TransitionDeescalated is an active recommendation whose severity fell
+	TransitionDeescalated TransitionKind = "deescalated"
+	// TransitionResolved is a recommendation that is no longer active
+	TransitionResolved TransitionKind = "resolved"
+)
+
+// Transition is a change in one recommendation, matched by ID
+type Transition struct {
+	Kind TransitionKind
+	Time time.Time
+	// Recommendation is the current one, or the last one seen when resolved
+	Recommendation Recommendation
+	// Previous is the severity before an escalation or de-escalation
+	Previous Severity
+}
+
+// Transitions compares two evaluations and returns what was raised,
+// escalated and de-escalated in the order of cur, followed by what was
+// resolved in the order of prev
+func Transitions(prev, cur []Recommendation, t time.Time) []Transition {
+	before := make(map[string]Recommendation, len(prev))
+	for _, rec := range prev {
+		before[rec.ID()] = rec
+	}
+	var transitions []Transition
+	active := make(map[string]bool, len(cur))
+	for _, rec := range cur {
+		active[rec.ID()] = true
+		old, ok := before[rec.ID()]
+		switch {
+		case !ok:
+			transitions = append(transitions, Transition{Kind: TransitionRaised, Time: t, Recommendation: rec})
+		case rec.Severity.Rank() > old.Severity.Rank():
+			transitions = append(transitions, Transition{Kind: TransitionEscalated, Time: t, Recommendation: rec, Previous: old.Severity})
+		case rec.Severity.Rank() < old.Severity.Rank():
+			transitions = append(transitions, Transition{Kind: TransitionDeescalated, Time: t, Recommendation: rec, Previous: old.Severity})
+		}
+	}
+	for _, rec := range prev {
+		if !active[rec.ID()] {
+			transitions = append(transitions, Transition{Kind: TransitionResolved, Time: t, Recommendation: rec})
+		}
+	}
+	return transitions
+}
@@ -0,0 +1,62 @@
+package analysis
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestTransitions(t *testing.T) {
+	rec := func(rule, subject string, severity Severity) Recommendation {
+		return Recommendation{Rule: rule, Subject: subject, Severity: severity}
+	}
+	now := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name      string
+		prev, cur []Recommendation
+		want      []Transition
+	}{
+		{"nothing", nil, nil, nil},
+		{"unchanged", []Recommendation{rec("cpu.usage", "", SeverityHigh)}, []Recommendation{rec("cpu.usage", "", SeverityHigh)}, nil},
+		{
+			"raised",
+			nil,
+			[]Recommendation{rec("cpu.usage", "", SeverityHigh)},
+			[]Transition{{Kind: TransitionRaised, Time: now, Recommendation: rec("cpu.usage", "", SeverityHigh)}},
+		},
+		{
+			"escalated",
+			[]Recommendation{rec("cpu.usage", "", SeverityHigh)},
+			[]Recommendation{rec("cpu.usage", "", SeverityCritical)},
+			[]Transition{{Kind: TransitionEscalated, Time: now, Recommendation: rec("cpu.usage", "", SeverityCritical), Previous: SeverityHigh}},
+		},
+		{
+			"de-escalated",
+			[]Recommendation{rec("cpu.usage", "", SeverityCritical)},
+			[]Recommendation{rec("cpu.usage", "", SeverityHigh)},
+			[]Transition{{Kind: TransitionDeescalated, Time: now, Recommendation: rec("cpu.usage", "", SeverityHigh), Previous: SeverityCritical}},
+		},
+		{
+			"resolved",
+			[]Recommendation{rec("cpu.usage", "", SeverityHigh)},
+			nil,
+			[]Transition{{Kind: TransitionResolved, Time: now, Recommendation: rec("cpu.usage", "", SeverityHigh)}},
+		},
+		{
+			"subjects are separate",
+			[]Recommendation{rec("process.leak", "42", SeverityLow)},
+			[]Recommendation{rec("process.leak", "43", SeverityLow)},
+			[]Transition{
+				{Kind: TransitionRaised, Time: now, Recommendation: rec("process.leak", "43", SeverityLow)},
+				{Kind: TransitionResolved, Time: now, Recommendation: rec("process.leak", "42", SeverityLow)},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Transitions(tt.prev, tt.cur, now); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Transitions() =\n%+v\nwant\n%+v", got, tt.want)
+			}
+		})
+	}
+}
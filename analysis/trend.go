@@ -0,0 +1,361 @@
+package analysis
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/xmarkclx/bottleneck-check/internal/stats"
+	"github.com/xmarkclx/bottleneck-check/internal/units"
+	"github.com/xmarkclx/bottleneck-check/metrics"
+)
+
+// Rule IDs produced by DetectTrends
+const (
+	RuleMemoryLeak  = "memory.leak"
+	RuleProcessLeak = "process.leak"
+)
+
+// TrendOptions configures growth-trend detection
+type TrendOptions struct {
+	// MinSpan is the least history a trend is fitted over
+	MinSpan time.Duration
+	// MinR2 is how closely usage must follow a straight line, 0-1
+	MinR2 float64
+	// MinMonotonic is the share of consecutive stretches of the history in
+	// which usage must have grown, 0-1
+	MinMonotonic float64
+	// MinGrowth is the slowest growth reported, as a share of installed RAM
+	// per hour, e.g. 0.01 for 1% an hour
+	MinGrowth float64
+}
+
+// Defaults used when TrendOptions leaves a field zero
+const (
+	DefaultTrendSpan      = 10 * time.Minute
+	DefaultTrendR2        = 0.8
+	DefaultTrendMonotonic = 0.8
+	DefaultTrendGrowth    = 0.01
+)
+
+// trendSegments is how many stretches the history is split into to check
+// that growth is steady rather than one jump
+const trendSegments = 10
+
+func (o TrendOptions) withDefaults() TrendOptions {
+	if o.MinSpan <= 0 {
+		o.MinSpan = DefaultTrendSpan
+	}
+	if o.MinR2 <= 0 {
+		o.MinR2 = DefaultTrendR2
+	}
+	if o.MinMonotonic <= 0 {
+		o.MinMonotonic = DefaultTrendMonotonic
+	}
+	if o.MinGrowth <= 0 {
+		o.MinGrowth = DefaultTrendGrowth
+	}
+	return o
+}
+
+// trend is steady growth found in one series
+type trend struct {
+	slope float64 // bytes per second
+	start time.Time
+	span  time.Duration
+}
+
+// DetectTrends fits a line through system memory use and through the RSS of
+// each recorded process, and reports steady, near-monotonic growth along
+// with how long until RAM runs out at that rate. Samples must be oldest
+// first; per-process trends need the "process" collector.
+//
+// System growth is attributed to the process that accounts for most of it
+// when there is one; other processes growing on their own are reported as
+// separate "process.leak" recommendations.
+func DetectTrends(samples []metrics.SystemMetrics, opts TrendOptions) []Recommendation {
+	return DetectTrendsWithHistory(samples, samples, opts)
+}
+
+// DetectTrendsWithHistory is DetectTrends with system memory fitted over
+// history instead of samples, e.g. per-minute averages from a history store
+// reaching back hours, so growth too slow to show in a few minutes is still
+// found. Processes are fitted over samples only, as history records no
+// per-process memory: a process leak is found only within the samples'
+// span, e.g. the 30 minutes a sampler keeps, and only for the processes
+// the "process" collector records, the largest and busiest.
+func DetectTrendsWithHistory(history, samples []metrics.SystemMetrics, opts TrendOptions) []Recommendation {
+	if len(samples) == 0 || len(history) < 2 {
+		return nil
+	}
+	opts = opts.withDefaults()
+	latest := &samples[len(samples)-1]
+	if latest.MemoryTotal == 0 {
+		return nil
+	}
+	total := float64(latest.MemoryTotal)
+	headroom := total - float64(latest.MemoryUsed)
+	minSlope := opts.MinGrowth * total / time.Hour.Seconds()
+
+	// System memory
+	var times []time.Time
+	var used []float64
+	for _, m := range history {
+		if m.MemoryTotal > 0 {
+			times = append(times, m.Timestamp)
+			used = append(used, float64(m.MemoryUsed))
+		}
+	}
+	system, systemGrowing := fitTrend(times, used, minSlope, opts)
+
+	// Processes, keyed by PID; a PID must be present for nearly the whole
+	// history so restarts and short-lived processes don't count
+	type series struct {
+		info  metrics.ProcessInfo
+		times []time.Time
+		rss   []float64
+	}
+	byPID := make(map[int32]*series)
+	for _, m := range samples {
+		for _, p := range m.Processes {
+			s := byPID[p.PID]
+			if s == nil {
+				s = &series{}
+				byPID[p.PID] = s
+			}
+			s.info = p
+			s.times = append(s.times, m.Timestamp)
+			s.rss = append(s.rss, float64(p.RSS))
+		}
+	}
+	type leak struct {
+		info  metrics.ProcessInfo
+		trend trend
+	}
+	var leaks []leak
+	for _, s := range byPID {
+		if float64(len(s.rss)) < 0.9*float64(len(samples)) {
+			continue
+		}
+		if t, ok := fitTrend(s.times, s.rss, minSlope, opts); ok {
+			leaks = append(leaks, leak{s.info, t})
+		}
+	}
+	sort.Slice(leaks, func(i, j int) bool { return leaks[i].trend.slope > leaks[j].trend.slope })
+
+	var recommendations []Recommendation
+	attributed := -1
+	if systemGrowing {
+		reason := fmt.Sprintf("Memory use is growing steadily (+%s/h over the last %s)",
+			units.Bytes(system.slope*time.Hour.Seconds()), formatETA(system.span))
+		suggestion := "Look for an application or service that keeps allocating memory and restart it."
+		if len(leaks) > 0 && leaks[0].trend.slope >= 0.5*system.slope {
+			attributed = 0
+			p := leaks[0].info
+			reason += fmt.Sprintf(", mostly from %s (PID %d)", p.Name, p.PID)
+			suggestion = fmt.Sprintf("Restart %s or check it for a memory leak.", p.Name)
+		}
+		eta := etaAt(headroom, system.slope)
+		recommendations = append(recommendations, Recommendation{
+			Rule:       RuleMemoryLeak,
+			Component:  "Memory",
+			Severity:   severityForETA(eta),
+			Reason:     reason,
+			Suggestion: fmt.Sprintf("At this rate RAM runs out in about %s. %s", formatETA(eta), suggestion),
+			Since:      system.start,
+			Duration:   system.span,
+			Evidence: map[string]float64{
+				"growth_bytes_per_hour":       system.slope * time.Hour.Seconds(),
+				"eta_seconds":                 eta.Seconds(),
+				metrics.MetricMemoryUsedBytes: float64(latest.MemoryUsed),
+			},
+		})
+	}
+
+	for i, l := range leaks {
+		if i == attributed {
+			continue
+		}
+		eta := etaAt(headroom, l.trend.slope)
+		recommendations = append(recommendations, Recommendation{
+			Rule:      RuleProcessLeak,
+			Subject:   strconv.Itoa(int(l.info.PID)),
+			Component: "Memory",
+			Severity:  severityForETA(eta),
+			Reason: fmt.Sprintf("%s (PID %d) keeps growing (+%s/h over the last %s, now %s)",
+				l.info.Name, l.info.PID, units.Bytes(l.trend.slope*time.Hour.Seconds()), formatETA(l.trend.span), units.Bytes(float64(l.info.RSS))),
+			Suggestion: fmt.Sprintf("If it keeps growing, RAM runs out in about %s. Restart %s or check it for a memory leak.",
+				formatETA(eta), l.info.Name),
+			Since:    l.trend.start,
+			Duration: l.trend.span,
+			Evidence: map[string]float64{
+				"growth_bytes_per_hour": l.trend.slope * time.Hour.Seconds(),
+				"eta_seconds":           eta.Seconds(),
+				"rss_bytes":             float64(l.info.RSS),
+				"pid":                   float64(l.info.PID),
+			},
+		})
+	}
+	return recommendations
+}
+
+// fitTrend reports whether values grow steadily: fast enough, close to a
+// straight line, and in most stretches of the history rather than in one jump
+func fitTrend(times []time.Time, values []float64, minSlope float64, opts TrendOptions) (trend, bool) {
+	if len(values) < trendSegments {
+		return trend{}, false
+	}
+	span := times[len(times)-1].Sub(times[0])
+	if span < opts.MinSpan {
+		return trend{}, false
+	}
+
+	xs := make([]float64, len(times))
+	for i, t := range times {
+		xs[i] = t.Sub(times[0]).Seconds()
+	}
+	fit, ok := stats.LinearFit(xs, values)
+	if !ok || fit.Slope < minSlope || fit.R2 < opts.MinR2 {
+		return trend{}, false
+	}
+
+	// Compare the averages of consecutive stretches of equal length
+	var means []float64
+	for k := 0; k < trendSegments; k++ {
+		lo, hi := k*len(values)/trendSegments, (k+1)*len(values)/trendSegments
+		var sum float64
+		for _, v := range values[lo:hi] {
+			sum += v
+		}
+		means = append(means, sum/float64(hi-lo))
+	}
+	grew := 0
+	for k := 1; k < len(means); k++ {
+		if means[k] > means[k-1] {
+			grew++
+		}
+	}
+	if float64(grew)/float64(len(means)-1) < opts.MinMonotonic {
+		return trend{}, false
+	}
+	return trend{slope: fit.Slope, start: times[0], span: span}, true
+}
+
+// DefaultTrendHold is how long a trend must be found, or missing, before a
+// TrendTracker raises or resolves it
+const DefaultTrendHold = 5 * time.Minute
+
+// TrendTracker keeps trend recommendations steady between fits, as
+// Evaluator does for sustained rules: a trend is raised once it has been
+// found for the hold time and resolved once it has been missing for as
+// long. Severity rises at once but falls only when the lower severity has
+// held for the hold time, so an estimate near a boundary doesn't flap.
+// A TrendTracker is not safe for concurrent use.
+type TrendTracker struct {
+	hold   time.Duration
+	trends map[string]*trackedTrend
+}
+
+type trackedTrend struct {
+	rec    Recommendation // newest found, at the severity reported
+	raised bool
+	found  time.Time // first found since it was last missing too long
+	seen   time.Time // last found
+	lower  time.Time // since a lower severity has been found, or zero
+}
+
+// NewTrendTracker returns a TrendTracker; a hold of zero or less means
+// DefaultTrendHold
+func NewTrendTracker(hold time.Duration) *TrendTracker {
+	if hold <= 0 {
+		hold = DefaultTrendHold
+	}
+	return &TrendTracker{hold: hold, trends: make(map[string]*trackedTrend)}
+}
+
+// Track updates the tracked trends with those found at now, e.g. by
+// DetectTrends, and returns the active ones: those found in the order
+// given, followed by those still held while missing
+func (t *TrendTracker) Track(found []Recommendation, now time.Time) []Recommendation {
+	var active []Recommendation
+	seen := make(map[string]bool, len(found))
+	for _, rec := range found {
+		id := rec.ID()
+		seen[id] = true
+		tr := t.trends[id]
+		if tr == nil {
+			tr = &trackedTrend{found: now}
+			t.trends[id] = tr
+		}
+
+		severity := rec.Severity
+		switch {
+		case !tr.raised || rec.Severity.Rank() >= tr.rec.Severity.Rank():
+			tr.lower = time.Time{}
+		case tr.lower.IsZero():
+			tr.lower = now
+			fallthrough
+		case now.Sub(tr.lower) < t.hold:
+			severity = tr.rec.Severity
+		default:
+			tr.lower = time.Time{}
+		}
+		tr.rec, tr.seen = rec, now
+		tr.rec.Severity = severity
+
+		if now.Sub(tr.found) >= t.hold {
+			tr.raised = true
+		}
+		if tr.raised {
+			active = append(active, tr.rec)
+		}
+	}
+
+	var held []Recommendation
+	for id, tr := range t.trends {
+		if seen[id] {
+			continue
+		}
+		if !tr.raised || now.Sub(tr.seen) >= t.hold {
+			delete(t.trends, id)
+			continue
+		}
+		held = append(held, tr.rec)
+	}
+	sort.Slice(held, func(i, j int) bool { return held[i].ID() < held[j].ID() })
+	return append(active, held...)
+}
+
+func etaAt(headroom, slope float64) time.Duration {
+	if headroom <= 0 {
+		return 0
+	}
+	return time.Duration(headroom / slope * float64(time.Second))
+}
+
+func severityForETA(eta time.Duration) Severity {
+	switch {
+	case eta < time.Hour:
+		return SeverityCritical
+	case eta < 6*time.Hour:
+		return SeverityHigh
+	case eta < 24*time.Hour:
+		return SeverityMedium
+	}
+	return SeverityLow
+}
+
+// formatETA writes a duration to the minute, e.g. "3h10m" or "45m", or in
+// days once it is two days or more
+func formatETA(d time.Duration) string {
+	switch {
+	case d < time.Minute:
+		return "under a minute"
+	case d >= 48*time.Hour:
+		return fmt.Sprintf("%.0f days", d.Hours()/24)
+	}
+	s := d.Round(time.Minute).String()
+	return strings.TrimSuffix(s, "0s")
+}
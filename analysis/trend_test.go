@@ -0,0 +1,152 @@
+package analysis
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/xmarkclx/bottleneck-check/metrics"
+)
+
+// growth describes a series growing at a steady rate from a start value
+type growth struct {
+	start   float64 // bytes
+	perHour float64 // bytes
+}
+
+func (g growth) at(elapsed time.Duration) uint64 {
+	return uint64(g.start + g.perHour*elapsed.Hours())
+}
+
+// memoryRun returns n samples step apart of 16 GiB of RAM, with used memory
+// and each process's RSS following their growth
+func memoryRun(n int, step time.Duration, used growth, procs map[int32]growth) []metrics.SystemMetrics {
+	samples := make([]metrics.SystemMetrics, n)
+	for i := range samples {
+		elapsed := time.Duration(i) * step
+		m := metrics.SystemMetrics{
+			Timestamp:   testStart.Add(elapsed),
+			MemoryTotal: 16 * gib,
+			MemoryUsed:  used.at(elapsed),
+		}
+		for pid, g := range procs {
+			m.Processes = append(m.Processes, metrics.ProcessInfo{PID: pid, Name: fmt.Sprintf("proc%d", pid), RSS: g.at(elapsed)})
+		}
+		samples[i] = m
+	}
+	return samples
+}
+
+// ids lists each recommendation's ID and severity
+func ids(recs []Recommendation) []string {
+	var out []string
+	for _, rec := range recs {
+		out = append(out, rec.ID()+"="+string(rec.Severity))
+	}
+	return out
+}
+
+func TestDetectTrends(t *testing.T) {
+	steady := growth{8 * gib, gib}
+	tests := []struct {
+		name    string
+		samples []metrics.SystemMetrics
+		want    []string
+	}{
+		{"flat", memoryRun(121, 10*time.Second, growth{8 * gib, 0}, nil), nil},
+		{"too slow", memoryRun(121, 10*time.Second, growth{8 * gib, 0.05 * gib}, nil), nil},
+		{"too short", memoryRun(31, 10*time.Second, steady, nil), nil},
+		{"growing", memoryRun(121, 10*time.Second, steady, nil), []string{"memory.leak=MEDIUM"}},
+		{"runs out soon", memoryRun(121, 10*time.Second, growth{15 * gib, gib}, nil), []string{"memory.leak=CRITICAL"}},
+		{
+			"attributed to the main process",
+			memoryRun(121, 10*time.Second, steady, map[int32]growth{
+				1:  {gib, 0},
+				42: {2 * gib, 0.9 * gib},
+				7:  {gib, 0.2 * gib},
+			}),
+			[]string{"memory.leak=MEDIUM", "process.leak:7=LOW"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ids(DetectTrends(tt.samples, TrendOptions{}))
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("DetectTrends() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDetectTrendsIgnoresJumps(t *testing.T) {
+	samples := memoryRun(120, 10*time.Second, growth{8 * gib, 0}, nil)
+	for i := len(samples) / 2; i < len(samples); i++ {
+		samples[i].MemoryUsed += 2 * gib
+	}
+	if got := DetectTrends(samples, TrendOptions{}); got != nil {
+		t.Errorf("DetectTrends() = %v, want nothing for a single jump", ids(got))
+	}
+}
+
+func TestDetectTrendsIgnoresShortLivedProcesses(t *testing.T) {
+	samples := memoryRun(120, 10*time.Second, growth{8 * gib, 0}, map[int32]growth{42: {gib, gib}})
+	for i := range samples[:60] {
+		samples[i].Processes = nil
+	}
+	if got := DetectTrends(samples, TrendOptions{}); got != nil {
+		t.Errorf("DetectTrends() = %v, want nothing for a process seen half the time", ids(got))
+	}
+}
+
+func TestDetectTrendsWithHistory(t *testing.T) {
+	// 0.25 GiB/h shows over 6 hours of per-minute history but not over the
+	// last two minutes of samples
+	slow := growth{8 * gib, 0.25 * gib}
+	history := memoryRun(361, time.Minute, slow, nil)
+	samples := memoryRun(13, 10*time.Second, growth{float64(history[len(history)-1].MemoryUsed), 0}, nil)
+	if got := DetectTrends(samples, TrendOptions{}); got != nil {
+		t.Fatalf("DetectTrends() over samples = %v, want nothing", ids(got))
+	}
+	got := DetectTrendsWithHistory(history, samples, TrendOptions{})
+	if want := []string{"memory.leak=LOW"}; !reflect.DeepEqual(ids(got), want) {
+		t.Fatalf("DetectTrendsWithHistory() = %v, want %v", ids(got), want)
+	}
+	if got[0].Duration != 6*time.Hour {
+		t.Errorf("Duration = %s, want 6h", got[0].Duration)
+	}
+}
+
+func TestTrendTracker(t *testing.T) {
+	leak := func(severity Severity) Recommendation {
+		return Recommendation{Rule: RuleMemoryLeak, Severity: severity}
+	}
+	process := Recommendation{Rule: RuleProcessLeak, Subject: "42", Severity: SeverityLow}
+	steps := []struct {
+		minutes int
+		found   []Recommendation
+		want    []string
+	}{
+		{0, []Recommendation{leak(SeverityHigh), process}, nil},
+		{1, []Recommendation{leak(SeverityHigh)}, nil},
+		// The process trend went missing before it was raised, so it
+		// starts over
+		{2, []Recommendation{leak(SeverityHigh), process}, nil},
+		{5, []Recommendation{leak(SeverityHigh), process}, []string{"memory.leak=HIGH"}},
+		{6, []Recommendation{leak(SeverityMedium), process}, []string{"memory.leak=HIGH"}},
+		{7, []Recommendation{leak(SeverityMedium), process}, []string{"memory.leak=HIGH", "process.leak:42=LOW"}},
+		{10, []Recommendation{leak(SeverityMedium), process}, []string{"memory.leak=HIGH", "process.leak:42=LOW"}},
+		{11, []Recommendation{leak(SeverityMedium), process}, []string{"memory.leak=MEDIUM", "process.leak:42=LOW"}},
+		{12, []Recommendation{leak(SeverityCritical)}, []string{"memory.leak=CRITICAL", "process.leak:42=LOW"}},
+		{15, nil, []string{"memory.leak=CRITICAL", "process.leak:42=LOW"}},
+		{16, nil, []string{"memory.leak=CRITICAL"}},
+		{17, nil, nil},
+	}
+	tracker := NewTrendTracker(0)
+	for _, s := range steps {
+		now := testStart.Add(time.Duration(s.minutes) * time.Minute)
+		if got := ids(tracker.Track(s.found, now)); !reflect.DeepEqual(got, s.want) {
+			t.Errorf("at %dm: Track() = %v, want %v", s.minutes, got, s.want)
+		}
+	}
+}
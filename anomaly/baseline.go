@@ -0,0 +1,169 @@
+// Package anomaly learns what is normal for a host from its recorded history
+// and flags live readings that deviate from it.
+//
+// A Baseline keeps the mean and spread of each metric per hour of the week,
+// so a build server that is busy every night or a desktop that idles on
+// weekends is judged against its own habits rather than fixed thresholds.
+// A Detector smooths live readings with an exponentially weighted moving
+// average (EWMA) and reports an Anomaly when the smoothed value is several
+// standard deviations from the baseline for that time slot.
+package anomaly
+
+import (
+	"math"
+	"time"
+
+	"github.com/xmarkclx/bottleneck-check/history"
+	"github.com/xmarkclx/bottleneck-check/metrics"
+)
+
+// DefaultMetrics are the metrics learned and watched when none are given
+var DefaultMetrics = []string{
+	metrics.MetricCPUUsage,
+	metrics.MetricLoad1,
+	metrics.MetricMemoryUsedPercent,
+	metrics.MetricSwapUsedBytes,
+}
+
+// DefaultLearnPeriod is how much history LearnFromStore reads by default
+const DefaultLearnPeriod = 28 * 24 * time.Hour
+
+// Thresholds for trusting a learned slot
+const (
+	// minSlotCount is the fewest observations a slot needs to be used
+	minSlotCount = 30
+	// minProfileCount is the least history, in 1m observations, before a
+	// metric's baseline is used at all
+	minProfileCount = 24 * 60
+)
+
+// Basis says which slot an expectation came from
+type Basis string
+
+const (
+	// BasisWeekHour compares against the same hour on the same weekday
+	BasisWeekHour Basis = "weekday-hour"
+	// BasisHour compares against the same hour on any day
+	BasisHour Basis = "hour"
+	// BasisOverall compares against all history
+	BasisOverall Basis = "overall"
+)
+
+// moments accumulates a running mean and variance (Welford's method)
+type moments struct {
+	N    int
+	Mean float64
+	M2   float64
+}
+
+func (m *moments) add(v float64) {
+	m.N++
+	delta := v - m.Mean
+	m.Mean += delta / float64(m.N)
+	m.M2 += delta * (v - m.Mean)
+}
+
+func (m *moments) stdDev() float64 {
+	if m.N < 2 {
+		return 0
+	}
+	return math.Sqrt(m.M2 / float64(m.N-1))
+}
+
+// profile is one metric's learned behavior
+type profile struct {
+	week    [7 * 24]moments // by weekday and hour, local time
+	hour    [24]moments
+	overall moments
+}
+
+// Expectation is the normal value of a metric at some time
+type Expectation struct {
+	Mean   float64
+	StdDev float64
+	Basis  Basis
+	// Count is the number of observations behind the expectation
+	Count int
+}
+
+// Baseline holds per-metric profiles with weekly seasonality. Build one
+// with Add or LearnFromStore. A Baseline is not safe for concurrent
+// modification but may be read concurrently once built.
+type Baseline struct {
+	profiles map[string]*profile
+	from, to time.Time
+}
+
+// NewBaseline returns an empty Baseline
+func NewBaseline() *Baseline {
+	return &Baseline{profiles: make(map[string]*profile)}
+}
+
+// Add records one observation of a metric
+func (b *Baseline) Add(metric string, t time.Time, v float64) {
+	p := b.profiles[metric]
+	if p == nil {
+		p = &profile{}
+		b.profiles[metric] = p
+	}
+	local := t.Local()
+	p.week[int(local.Weekday())*24+local.Hour()].add(v)
+	p.hour[local.Hour()].add(v)
+	p.overall.add(v)
+
+	if b.from.IsZero() || t.Before(b.from) {
+		b.from = t
+	}
+	if t.After(b.to) {
+		b.to = t
+	}
+}
+
+// Span returns the time range of the observations added
+func (b *Baseline) Span() (from, to time.Time) {
+	return b.from, b.to
+}
+
+// Ready reports whether a metric has enough history to be judged
+func (b *Baseline) Ready(metric string) bool {
+	p := b.profiles[metric]
+	return p != nil && p.overall.N >= minProfileCount
+}
+
+// Expected returns what is normal for a metric at t, using the most
+// specific slot with enough observations. It returns false until the
+// metric is Ready.
+func (b *Baseline) Expected(metric string, t time.Time) (Expectation, bool) {
+	if !b.Ready(metric) {
+		return Expectation{}, false
+	}
+	p := b.profiles[metric]
+	local := t.Local()
+	if m := p.week[int(local.Weekday())*24+local.Hour()]; m.N >= minSlotCount {
+		return Expectation{Mean: m.Mean, StdDev: m.stdDev(), Basis: BasisWeekHour, Count: m.N}, true
+	}
+	if m := p.hour[local.Hour()]; m.N >= minSlotCount {
+		return Expectation{Mean: m.Mean, StdDev: m.stdDev(), Basis: BasisHour, Count: m.N}, true
+	}
+	m := p.overall
+	return Expectation{Mean: m.Mean, StdDev: m.stdDev(), Basis: BasisOverall, Count: m.N}, true
+}
+
+// LearnFromStore builds a Baseline from the per-minute averages recorded in
+// a history store between from and to. Empty metrics means DefaultMetrics.
+func LearnFromStore(store *history.Store, from, to time.Time, metricNames []string) (*Baseline, error) {
+	if len(metricNames) == 0 {
+		metricNames = DefaultMetrics
+	}
+	b := NewBaseline()
+	for _, metric := range metricNames {
+		points, _, err := store.Query(metric, from, to, history.ResolutionMinute)
+		if err != nil {
+			return nil, err
+		}
+		for _, p := range points {
+			b.Add(metric, p.Time, p.Avg)
+		}
+	}
+	return b, nil
+}
@@ -0,0 +1,176 @@
+package anomaly
+
+import (
+	"math"
+	"sync"
+	"time"
+
+	"github.com/xmarkclx/bottleneck-check/metrics"
+)
+
+// Options configures a Detector
+type Options struct {
+	// Metrics lists the metrics to watch. Empty means DefaultMetrics.
+	Metrics []string
+	// Smoothing is the EWMA time constant: readings older than this carry
+	// about a third of the weight. It keeps single spikes from counting.
+	Smoothing time.Duration
+	// RaiseZ is how many standard deviations from normal raise an anomaly
+	RaiseZ float64
+	// ClearZ is the deviation below which an anomaly clears. Keeping it
+	// under RaiseZ stops anomalies from flapping.
+	ClearZ float64
+}
+
+// Defaults used when Options leaves a field zero
+const (
+	DefaultSmoothing = 2 * time.Minute
+	DefaultRaiseZ    = 3.0
+	DefaultClearZ    = 2.0
+)
+
+// Anomaly is a metric that is far from its learned normal
+type Anomaly struct {
+	Metric string
+	// Value is the smoothed reading that was judged
+	Value    float64
+	Expected Expectation
+	// Z is the deviation in standard deviations; negative means below normal
+	Z     float64
+	Since time.Time
+}
+
+// Above reports whether the reading is higher than normal
+func (a Anomaly) Above() bool {
+	return a.Z > 0
+}
+
+// Detector flags live readings that deviate from a Baseline. It is safe for
+// concurrent use.
+type Detector struct {
+	opts Options
+
+	mu       sync.Mutex
+	baseline *Baseline
+	last     time.Time
+	ewma     map[string]float64
+	active   map[string]time.Time
+}
+
+// NewDetector returns a Detector judging against b, which may be nil until
+// a baseline has been learned
+func NewDetector(b *Baseline, opts Options) *Detector {
+	if len(opts.Metrics) == 0 {
+		opts.Metrics = DefaultMetrics
+	}
+	if opts.Smoothing <= 0 {
+		opts.Smoothing = DefaultSmoothing
+	}
+	if opts.RaiseZ <= 0 {
+		opts.RaiseZ = DefaultRaiseZ
+	}
+	if opts.ClearZ <= 0 {
+		opts.ClearZ = min(DefaultClearZ, opts.RaiseZ)
+	}
+	return &Detector{
+		opts:     opts,
+		baseline: b,
+		ewma:     make(map[string]float64),
+		active:   make(map[string]time.Time),
+	}
+}
+
+// SetBaseline replaces the baseline, e.g. after relearning
+func (d *Detector) SetBaseline(b *Baseline) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.baseline = b
+}
+
+// Baseline returns the current baseline, or nil
+func (d *Detector) Baseline() *Baseline {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.baseline
+}
+
+// Update feeds samples (oldest first) into the moving averages, skipping
+// any already seen, and returns the active anomalies
+func (d *Detector) Update(samples []metrics.SystemMetrics) []Anomaly {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	for i := range samples {
+		m := &samples[i]
+		if !m.Timestamp.After(d.last) {
+			continue
+		}
+		values := m.Values()
+		for _, metric := range d.opts.Metrics {
+			v, ok := values[metric]
+			if !ok {
+				continue
+			}
+			prev, seen := d.ewma[metric]
+			if !seen {
+				d.ewma[metric] = v
+				continue
+			}
+			// Weight by elapsed time so irregular sampling stays consistent
+			alpha := 1 - math.Exp(-float64(m.Timestamp.Sub(d.last))/float64(d.opts.Smoothing))
+			d.ewma[metric] = prev + alpha*(v-prev)
+		}
+		d.last = m.Timestamp
+	}
+	if d.baseline == nil || d.last.IsZero() {
+		return nil
+	}
+	latest := d.last
+
+	var anomalies []Anomaly
+	for _, metric := range d.opts.Metrics {
+		value, ok := d.ewma[metric]
+		if !ok {
+			continue
+		}
+		expected, ok := d.baseline.Expected(metric, latest)
+		if !ok {
+			delete(d.active, metric)
+			continue
+		}
+		z := (value - expected.Mean) / spreadFloor(metric, expected)
+
+		limit := d.opts.RaiseZ
+		since, active := d.active[metric]
+		if active {
+			limit = d.opts.ClearZ
+		}
+		if math.Abs(z) < limit {
+			delete(d.active, metric)
+			continue
+		}
+		if !active {
+			since = latest
+			d.active[metric] = since
+		}
+		anomalies = append(anomalies, Anomaly{Metric: metric, Value: value, Expected: expected, Z: z, Since: since})
+	}
+	return anomalies
+}
+
+// spreadFloor keeps the z-score meaningful on very steady metrics, where a
+// tiny standard deviation would turn noise into an anomaly
+func spreadFloor(metric string, e Expectation) float64 {
+	floor := 0.05 * math.Abs(e.Mean)
+	if series, ok := metrics.LookupSeries(metric); ok {
+		switch series.Unit {
+		case "percent":
+			floor = max(floor, 2)
+		case "bytes":
+			floor = max(floor, 64*1024*1024)
+		default:
+			floor = max(floor, 0.1)
+		}
+	}
+	return max(e.StdDev, floor)
+}
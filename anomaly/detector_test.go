@@ -0,0 +1,116 @@
+package anomaly
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/xmarkclx/bottleneck-check/metrics"
+)
+
+var testStart = time.Date(2026, 10, 12, 0, 0, 0, 0, time.Local)
+
+// learn returns a baseline of n per-minute cpu.usage observations from
+// testStart, alternating between the values given
+func learn(n int, values ...float64) *Baseline {
+	b := NewBaseline()
+	for i := 0; i < n; i++ {
+		b.Add(metrics.MetricCPUUsage, testStart.Add(time.Duration(i)*time.Minute), values[i%len(values)])
+	}
+	return b
+}
+
+func TestBaselineWarmup(t *testing.T) {
+	// A day of minutes is needed before a metric is judged at all
+	b := learn(minProfileCount-1, 45, 55)
+	if _, ok := b.Expected(metrics.MetricCPUUsage, testStart); ok || b.Ready(metrics.MetricCPUUsage) {
+		t.Fatal("baseline is ready one observation short of a day")
+	}
+	b.Add(metrics.MetricCPUUsage, testStart.Add(minProfileCount*time.Minute), 50)
+	e, ok := b.Expected(metrics.MetricCPUUsage, testStart.Add(3*time.Hour))
+	if !ok {
+		t.Fatal("baseline isn't ready after a day")
+	}
+	if e.Basis != BasisWeekHour || e.Count != 60 || math.Abs(e.Mean-50) > 1e-9 {
+		t.Errorf("Expected() = %+v, want the weekday-hour slot of 60 around 50", e)
+	}
+	// Another weekday falls back to the same hour on any day
+	if e, _ := b.Expected(metrics.MetricCPUUsage, testStart.Add(27*time.Hour)); e.Basis != BasisHour {
+		t.Errorf("Expected() on another weekday has basis %s, want %s", e.Basis, BasisHour)
+	}
+}
+
+func TestDetectorHysteresis(t *testing.T) {
+	// Normal is 50 ± about 5; with a tiny smoothing constant the moving
+	// average follows each reading
+	d := NewDetector(learn(minProfileCount, 45, 55), Options{Metrics: []string{metrics.MetricCPUUsage}, Smoothing: time.Nanosecond})
+	at := testStart.Add(time.Hour)
+
+	steps := []struct {
+		usage  float64
+		active bool
+	}{
+		{60, false}, // z 2: under the raise limit
+		{70, true},  // z 4: raised
+		{62, true},  // z 2.4: under the raise limit but above the clear limit
+		{58, false}, // z 1.6: cleared
+		{62, false}, // z 2.4: not raised again until it reaches 3
+		{30, true},  // z -4: below normal counts too
+	}
+	var since time.Time
+	for i, step := range steps {
+		at = at.Add(time.Minute)
+		anomalies := d.Update([]metrics.SystemMetrics{{Timestamp: at, CPUUsage: step.usage, CPUCores: 4}})
+		if got := len(anomalies) == 1; got != step.active {
+			t.Fatalf("step %d (%v%%): active = %v, want %v (%+v)", i, step.usage, got, step.active, anomalies)
+		}
+		if !step.active {
+			since = time.Time{}
+			continue
+		}
+		a := anomalies[0]
+		if since.IsZero() {
+			since = at
+		}
+		if !a.Since.Equal(since) {
+			t.Errorf("step %d: Since = %v, want %v", i, a.Since, since)
+		}
+		if a.Above() != (step.usage > 50) {
+			t.Errorf("step %d: Above() = %v with z %.2f", i, a.Above(), a.Z)
+		}
+	}
+}
+
+func TestDetectorWarmup(t *testing.T) {
+	d := NewDetector(learn(minProfileCount-1, 45, 55), Options{Metrics: []string{metrics.MetricCPUUsage}, Smoothing: time.Nanosecond})
+	if anomalies := d.Update([]metrics.SystemMetrics{{Timestamp: testStart.Add(2 * 24 * time.Hour), CPUUsage: 100, CPUCores: 4}}); anomalies != nil {
+		t.Errorf("Update() before a day of history = %+v, want nothing", anomalies)
+	}
+}
+
+func TestSpreadFloor(t *testing.T) {
+	tests := []struct {
+		metric string
+		e      Expectation
+		want   float64
+	}{
+		// A perfectly steady metric would otherwise divide by zero
+		{metrics.MetricCPUUsage, Expectation{Mean: 10}, 2},
+		{metrics.MetricCPUUsage, Expectation{Mean: 80}, 4},
+		{metrics.MetricCPUUsage, Expectation{Mean: 80, StdDev: 7}, 7},
+		{metrics.MetricSwapUsedBytes, Expectation{}, 64 * 1024 * 1024},
+		{metrics.MetricSwapUsedBytes, Expectation{Mean: 4 << 30}, 0.05 * (4 << 30)},
+		{metrics.MetricLoad1, Expectation{Mean: 0.5}, 0.1},
+	}
+	for _, tt := range tests {
+		if got := spreadFloor(tt.metric, tt.e); math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("spreadFloor(%s, %+v) = %v, want %v", tt.metric, tt.e, got, tt.want)
+		}
+	}
+
+	// A steady baseline judges a 5-point rise as 2 deviations, not infinite
+	d := NewDetector(learn(minProfileCount, 50), Options{Metrics: []string{metrics.MetricCPUUsage}, Smoothing: time.Nanosecond})
+	if anomalies := d.Update([]metrics.SystemMetrics{{Timestamp: testStart.Add(2 * 24 * time.Hour), CPUUsage: 55, CPUCores: 4}}); anomalies != nil {
+		t.Errorf("Update() on a steady baseline = %+v, want nothing", anomalies)
+	}
+}
@@ -0,0 +1,236 @@
+// Package baseline saves a named summary of how a machine behaved over a
+// window, so a later window can be compared against it, for example to show
+// that an upgrade or configuration change helped.
+//
+// A Profile holds the distribution of every metric and the recommendations
+// that were raised while the window was replayed through the sustained
+// evaluator. Profiles are stored as JSON files in Dir().
+package baseline
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/xmarkclx/bottleneck-check/analysis"
+	"github.com/xmarkclx/bottleneck-check/history"
+	"github.com/xmarkclx/bottleneck-check/internal/stats"
+	"github.com/xmarkclx/bottleneck-check/metrics"
+	"github.com/xmarkclx/bottleneck-check/report"
+)
+
+// ErrNotFound is returned by Load for a name that was never saved
+var ErrNotFound = errors.New("baseline not found")
+
+// Profile summarizes metrics and recommendations over a window
+type Profile struct {
+	Name    string    `json:"name"`
+	Created time.Time `json:"created"`
+	Host    string    `json:"host,omitempty"`
+	From    time.Time `json:"from"`
+	To      time.Time `json:"to"`
+	// Source is "history" for recorded data or "live" for a fresh sample
+	Source  string `json:"source"`
+	Samples int    `json:"samples"`
+	// Metrics maps metric names to their distribution over the window
+	Metrics map[string]report.Distribution `json:"metrics"`
+	// Findings lists every recommendation raised during the window
+	Findings []Finding `json:"findings"`
+}
+
+// Finding is a recommendation raised at some point during a window
+type Finding struct {
+	Rule      string `json:"rule"`
+	Component string `json:"component"`
+	// Severity is the highest severity reached
+	Severity analysis.Severity `json:"severity"`
+	// Reason is the most recent reason given
+	Reason string `json:"reason"`
+	// Share is the fraction of samples during which it was active, 0-1
+	Share float64 `json:"share"`
+}
+
+// Capture summarizes snapshots (oldest first) into an unnamed Profile.
+// Findings are raised as an evaluator with opts would have raised them.
+func Capture(samples []metrics.SystemMetrics, source string, opts analysis.EvaluatorOptions) (*Profile, error) {
+	if len(samples) == 0 {
+		return nil, errors.New("no samples in the window")
+	}
+	p := &Profile{
+		Created: time.Now(),
+		From:    samples[0].Timestamp,
+		To:      samples[len(samples)-1].Timestamp,
+		Source:  source,
+		Samples: len(samples),
+		Metrics: make(map[string]report.Distribution),
+	}
+	if host, err := os.Hostname(); err == nil {
+		p.Host = host
+	}
+
+	values := make(map[string][]float64)
+	for i := range samples {
+		for name, v := range samples[i].Values() {
+			values[name] = append(values[name], v)
+		}
+	}
+	for name, vs := range values {
+		s := stats.Summarize(vs)
+		p.Metrics[name] = report.Distribution{Avg: s.Avg, P50: s.P50, P95: s.P95, P99: s.P99, Max: s.Max}
+	}
+
+	findings, err := replay(samples, opts)
+	if err != nil {
+		return nil, err
+	}
+	p.Findings = findings
+	return p, nil
+}
+
+// replay runs the samples through a sustained evaluator as the monitor
+// would have, and collects every recommendation it raised
+func replay(samples []metrics.SystemMetrics, opts analysis.EvaluatorOptions) ([]Finding, error) {
+	evaluator, err := analysis.NewEvaluator(opts)
+	if err != nil {
+		return nil, err
+	}
+	byRule := make(map[string]*Finding)
+	active := make(map[string]int)
+	lo := 0
+	for i := range samples {
+		for samples[i].Timestamp.Sub(samples[lo].Timestamp) > evaluator.Window() {
+			lo++
+		}
+		for _, rec := range evaluator.Evaluate(samples[lo : i+1]) {
+			f := byRule[rec.Rule]
+			if f == nil {
+				f = &Finding{Rule: rec.Rule, Component: rec.Component}
+				byRule[rec.Rule] = f
+			}
+			if rec.Severity.Rank() > f.Severity.Rank() {
+				f.Severity = rec.Severity
+			}
+			f.Reason = rec.Reason
+			active[rec.Rule]++
+		}
+	}
+
+	findings := make([]Finding, 0, len(byRule))
+	for rule, f := range byRule {
+		f.Share = float64(active[rule]) / float64(len(samples))
+		findings = append(findings, *f)
+	}
+	sortFindings(findings)
+	return findings, nil
+}
+
+// sortFindings orders findings by severity, most urgent first, then by rule
+func sortFindings(findings []Finding) {
+	sort.Slice(findings, func(i, j int) bool {
+		if a, b := findings[i].Severity.Rank(), findings[j].Severity.Rank(); a != b {
+			return a > b
+		}
+		return findings[i].Rule < findings[j].Rule
+	})
+}
+
+// FromHistory captures a Profile from recorded history between from and to
+func FromHistory(store *history.Store, from, to time.Time, opts analysis.EvaluatorOptions) (*Profile, error) {
+	snapshots, _, err := store.Snapshots(from, to, history.ResolutionAuto)
+	if err != nil {
+		return nil, err
+	}
+	if len(snapshots) == 0 {
+		return nil, fmt.Errorf("no history recorded between %s and %s",
+			from.Format("2006-01-02 15:04"), to.Format("2006-01-02 15:04"))
+	}
+	return Capture(snapshots, "history", opts)
+}
+
+// Dir returns the directory baselines are saved in
+func Dir() string {
+	return filepath.Join(history.DataDir(), "baselines")
+}
+
+var validName = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+
+// ValidateName checks that a name can be used as a file name
+func ValidateName(name string) error {
+	if !validName.MatchString(name) {
+		return fmt.Errorf("invalid baseline name %q (use letters, digits, '.', '_' and '-')", name)
+	}
+	return nil
+}
+
+func path(dir, name string) string {
+	return filepath.Join(dir, name+".json")
+}
+
+// Save writes a profile under its name in dir, replacing any existing one
+func Save(dir string, p *Profile) error {
+	if err := ValidateName(p.Name); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(p, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := path(dir, p.Name) + ".tmp"
+	if err := os.WriteFile(tmp, append(data, '\n'), 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path(dir, p.Name))
+}
+
+// Exists reports whether a baseline with the name is saved in dir
+func Exists(dir, name string) bool {
+	_, err := os.Stat(path(dir, name))
+	return err == nil
+}
+
+// Load reads a saved profile
+func Load(dir, name string) (*Profile, error) {
+	if err := ValidateName(name); err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path(dir, name))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, name)
+	}
+	if err != nil {
+		return nil, err
+	}
+	var p Profile
+	if err := json.Unmarshal(data, &p); err != nil {
+		return nil, fmt.Errorf("reading baseline %s: %w", name, err)
+	}
+	return &p, nil
+}
+
+// List returns the names of saved baselines, sorted
+func List(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, e := range entries {
+		if name, ok := strings.CutSuffix(e.Name(), ".json"); ok && !e.IsDir() {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
@@ -0,0 +1,121 @@
+package baseline
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/xmarkclx/bottleneck-check/analysis"
+	"github.com/xmarkclx/bottleneck-check/metrics"
+	"github.com/xmarkclx/bottleneck-check/report"
+)
+
+// cpuWindow returns one sample a second: a minute at 50% CPU, then a minute
+// at 80%
+func cpuWindow() []metrics.SystemMetrics {
+	start := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+	var samples []metrics.SystemMetrics
+	for i := 0; i < 120; i++ {
+		usage := 50.0
+		if i >= 60 {
+			usage = 80
+		}
+		samples = append(samples, metrics.SystemMetrics{
+			Timestamp: start.Add(time.Duration(i) * time.Second),
+			CPUUsage:  usage,
+			CPUCores:  4,
+		})
+	}
+	return samples
+}
+
+func TestCapture(t *testing.T) {
+	thresholds := analysis.DefaultThresholds()
+	tests := []struct {
+		name       string
+		thresholds *analysis.Thresholds
+		want       analysis.Severity // of cpu.usage, "" if not raised
+	}{
+		{"default thresholds", nil, analysis.SeverityHigh},
+		{"raised high limit", &analysis.Thresholds{CPUHigh: 85, CPUCritical: 95}, ""},
+		{"lowered critical limit", &analysis.Thresholds{CPUHigh: thresholds.CPUHigh, CPUCritical: 75}, analysis.SeverityCritical},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := Capture(cpuWindow(), "history", analysis.EvaluatorOptions{
+				Window: 30 * time.Second,
+				Warmup: 10 * time.Second,
+				Rules:  analysis.Options{Rules: []string{"cpu.usage"}, Thresholds: tt.thresholds},
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if p.Samples != 120 || p.Source != "history" {
+				t.Errorf("Samples = %d, Source = %q, want 120 and history", p.Samples, p.Source)
+			}
+			cpu := p.Metrics[metrics.MetricCPUUsage]
+			if cpu.Avg != 65 || cpu.Max != 80 || cpu.P50 < 50 || cpu.P50 > 80 {
+				t.Errorf("cpu.usage = %+v, want avg 65 and max 80", cpu)
+			}
+
+			if tt.want == "" {
+				if len(p.Findings) != 0 {
+					t.Errorf("Findings = %+v, want none", p.Findings)
+				}
+				return
+			}
+			if len(p.Findings) != 1 {
+				t.Fatalf("Findings = %+v, want cpu.usage only", p.Findings)
+			}
+			f := p.Findings[0]
+			// Raised only once the high minute filled most of the window
+			if f.Rule != "cpu.usage" || f.Severity != tt.want || f.Share <= 0 || f.Share >= 0.5 {
+				t.Errorf("finding = %+v, want cpu.usage %s for under half the samples", f, tt.want)
+			}
+		})
+	}
+
+	if _, err := Capture(nil, "live", analysis.EvaluatorOptions{}); err == nil {
+		t.Error("Capture() of no samples succeeded")
+	}
+}
+
+func TestDiff(t *testing.T) {
+	finding := func(rule string, severity analysis.Severity) Finding {
+		return Finding{Rule: rule, Component: "CPU", Severity: severity}
+	}
+	base := &Profile{
+		Metrics: map[string]report.Distribution{
+			metrics.MetricCPUUsage:          {Avg: 60, P95: 90},
+			metrics.MetricMemoryUsedPercent: {Avg: 70, P95: 80},
+		},
+		Findings: []Finding{finding("cpu.usage", analysis.SeverityHigh), finding("memory.swap", analysis.SeverityMedium)},
+	}
+	current := &Profile{
+		Metrics: map[string]report.Distribution{
+			metrics.MetricCPUUsage: {Avg: 40, P95: 95},
+			metrics.MetricLoad1:    {Avg: 2, P95: 3},
+		},
+		Findings: []Finding{finding("cpu.usage", analysis.SeverityCritical), finding("cpu.load", analysis.SeverityHigh)},
+	}
+	c := Diff(base, current)
+
+	// Only metrics recorded in both windows are compared
+	if len(c.Metrics) != 1 || c.Metrics[0].Metric != metrics.MetricCPUUsage || c.Metrics[0].Unit != "percent" {
+		t.Fatalf("Metrics = %+v, want cpu.usage only", c.Metrics)
+	}
+	if avg, p95 := c.Metrics[0].Change(); avg != -20 || p95 != 5 {
+		t.Errorf("Change() = %v, %v, want -20, 5", avg, p95)
+	}
+
+	if want := []Finding{finding("cpu.load", analysis.SeverityHigh)}; !reflect.DeepEqual(c.Appeared, want) {
+		t.Errorf("Appeared = %+v, want %+v", c.Appeared, want)
+	}
+	if want := []Finding{finding("memory.swap", analysis.SeverityMedium)}; !reflect.DeepEqual(c.Resolved, want) {
+		t.Errorf("Resolved = %+v, want %+v", c.Resolved, want)
+	}
+	want := []Persisting{{Base: finding("cpu.usage", analysis.SeverityHigh), Current: finding("cpu.usage", analysis.SeverityCritical)}}
+	if !reflect.DeepEqual(c.Persisting, want) {
+		t.Errorf("Persisting = %+v, want %+v", c.Persisting, want)
+	}
+}
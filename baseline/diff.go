@@ -0,0 +1,70 @@
+package baseline
+
+import (
+	"github.com/xmarkclx/bottleneck-check/metrics"
+	"github.com/xmarkclx/bottleneck-check/report"
+)
+
+// MetricDelta compares one metric between two profiles
+type MetricDelta struct {
+	Metric  string
+	Unit    string
+	Base    report.Distribution
+	Current report.Distribution
+}
+
+// Change returns Current minus Base for the average and p95
+func (d MetricDelta) Change() (avg, p95 float64) {
+	return d.Current.Avg - d.Base.Avg, d.Current.P95 - d.Base.P95
+}
+
+// Persisting is a finding present in both profiles
+type Persisting struct {
+	Base    Finding
+	Current Finding
+}
+
+// Comparison is the difference between a saved baseline and another window
+type Comparison struct {
+	Base    *Profile
+	Current *Profile
+	// Metrics recorded in both profiles, in AllSeries order
+	Metrics []MetricDelta
+	// Appeared were raised only in the current window
+	Appeared []Finding
+	// Resolved were raised only in the baseline
+	Resolved []Finding
+	// Persisting were raised in both
+	Persisting []Persisting
+}
+
+// Diff compares current against base
+func Diff(base, current *Profile) *Comparison {
+	c := &Comparison{Base: base, Current: current}
+	for _, series := range metrics.AllSeries {
+		b, inBase := base.Metrics[series.Name]
+		cur, inCurrent := current.Metrics[series.Name]
+		if inBase && inCurrent {
+			c.Metrics = append(c.Metrics, MetricDelta{Metric: series.Name, Unit: series.Unit, Base: b, Current: cur})
+		}
+	}
+
+	baseFindings := make(map[string]Finding)
+	for _, f := range base.Findings {
+		baseFindings[f.Rule] = f
+	}
+	for _, f := range current.Findings {
+		if b, ok := baseFindings[f.Rule]; ok {
+			c.Persisting = append(c.Persisting, Persisting{Base: b, Current: f})
+			delete(baseFindings, f.Rule)
+		} else {
+			c.Appeared = append(c.Appeared, f)
+		}
+	}
+	for _, f := range base.Findings {
+		if _, ok := baseFindings[f.Rule]; ok {
+			c.Resolved = append(c.Resolved, f)
+		}
+	}
+	return c
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/xmarkclx/bottleneck-check/history"
+	"github.com/xmarkclx/bottleneck-check/render"
+)
+
+// runAnnotate records an annotation in the history, or lists them
+func runAnnotate(args []string) int {
+	fs := flag.NewFlagSet("annotate", flag.ContinueOnError)
+	at := fs.String("at", "now", "when it happened: time ago (90m, 2h, 7d), date or RFC 3339 time")
+	list := fs.Bool("list", false, "list annotations instead of adding one")
+	from := fs.String("from", "7d", "with -list, start of range, same formats as -at")
+	to := fs.String("to", "now", "with -list, end of range, same formats as -at")
+	dir := fs.String("dir", cfg.HistoryDir, "history directory")
+	display := addOutputFlags(fs)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: bottleneck-check annotate [-at TIME] <text...>\n")
+		fmt.Fprintf(fs.Output(), "       bottleneck-check annotate -list [-from TIME] [-to TIME]\n\n")
+		fs.PrintDefaults()
+	}
+	words, err := parseInterspersed(fs, args)
+	if err != nil {
+		return 2
+	}
+	if err := display.apply(); err != nil {
+		printError(err)
+		return 2
+	}
+
+	now := time.Now()
+	if *list {
+		return listAnnotations(*dir, *from, *to, now)
+	}
+	if len(words) == 0 {
+		fs.Usage()
+		return 2
+	}
+	when, err := history.ParseTime(*at, now)
+	if err != nil {
+		printError(err)
+		return 2
+	}
+
+	// The store is opened read-only so annotating works while a monitor is
+	// recording
+	if err := os.MkdirAll(*dir, 0o755); err != nil {
+		printError(err)
+		return 1
+	}
+	store, err := history.OpenReadOnly(historyOptions(*dir))
+	if err != nil {
+		printError(err)
+		return 1
+	}
+	defer store.Close()
+	a, err := store.Annotate(history.Annotation{Time: when, Text: strings.Join(words, " "), Source: "cli"})
+	if errors.Is(err, history.ErrInvalidAnnotation) {
+		printError(err)
+		return 2
+	}
+	if err != nil {
+		printError(err)
+		return 1
+	}
+	fmt.Fprintf(stdout, "%s✓ Annotated %s:%s %s\n", render.ColorGreen, a.Time.Local().Format("2006-01-02 15:04:05"), render.ColorReset, a.Text)
+	return 0
+}
+
+func listAnnotations(dir, from, to string, now time.Time) int {
+	fromTime, err := history.ParseTime(from, now)
+	if err != nil {
+		printError(err)
+		return 2
+	}
+	toTime, err := history.ParseTime(to, now)
+	if err != nil {
+		printError(err)
+		return 2
+	}
+	store, err := history.OpenReadOnly(historyOptions(dir))
+	if err != nil {
+		printError(fmt.Errorf("no history at %s: %w", dir, err))
+		return 1
+	}
+	defer store.Close()
+
+	annotations, err := store.Annotations(fromTime, toTime)
+	if err != nil {
+		printError(err)
+		return 1
+	}
+	render.AnnotationList(stdout, annotations)
+	return 0
+}
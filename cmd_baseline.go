@@ -0,0 +1,197 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"time"
+
+	"github.com/xmarkclx/bottleneck-check/baseline"
+	"github.com/xmarkclx/bottleneck-check/history"
+	"github.com/xmarkclx/bottleneck-check/metrics"
+	"github.com/xmarkclx/bottleneck-check/render"
+)
+
+func printBaselineUsage() {
+	fmt.Fprintf(stdout, "Usage: bottleneck-check baseline <save|diff|list> [name] [flags]\n\n")
+	fmt.Fprintf(stdout, "  save <name>   Summarize a window of metrics and recommendations under a name\n")
+	fmt.Fprintf(stdout, "  diff <name>   Compare the current or another window against a saved baseline\n")
+	fmt.Fprintf(stdout, "  list          Show saved baselines\n\n")
+	fmt.Fprintf(stdout, "The window is read from recorded history (-from/-to), or sampled live with -sample.\n")
+}
+
+// runBaseline saves, compares and lists named baselines
+func runBaseline(args []string) int {
+	if len(args) == 0 {
+		printBaselineUsage()
+		return 2
+	}
+	action, args := args[0], args[1:]
+
+	fs := flag.NewFlagSet("baseline "+action, flag.ContinueOnError)
+	from := fs.String("from", "1h", "start of window: time ago (90m, 2h, 7d), date or RFC 3339 time")
+	to := fs.String("to", "now", "end of window, same formats as -from")
+	sample := fs.Duration("sample", 0, "sample live for this long (e.g. 1m) instead of reading history")
+	historyDir := fs.String("history-dir", cfg.HistoryDir, "history directory")
+	dir := fs.String("dir", baseline.Dir(), "directory for saved baselines")
+	force := fs.Bool("force", false, "replace an existing baseline with the same name")
+	display := addOutputFlags(fs)
+	positional, err := parseInterspersed(fs, args)
+	if err != nil {
+		return 2
+	}
+	if err := display.apply(); err != nil {
+		printError(err)
+		return 2
+	}
+
+	switch action {
+	case "list":
+		return listBaselines(*dir)
+	case "save", "diff":
+	case "help", "-h", "--help":
+		printBaselineUsage()
+		return 0
+	default:
+		fmt.Fprintf(stderr, "Unknown baseline action: '%s'\n\n", action)
+		printBaselineUsage()
+		return 2
+	}
+	if len(positional) != 1 {
+		fmt.Fprintf(stderr, "baseline %s needs exactly one name\n", action)
+		return 2
+	}
+	name := positional[0]
+	if err := baseline.ValidateName(name); err != nil {
+		printError(err)
+		return 2
+	}
+
+	var base *baseline.Profile
+	if action == "save" {
+		if baseline.Exists(*dir, name) && !*force {
+			printError(fmt.Errorf("baseline %q already exists; use -force to replace it", name))
+			return 1
+		}
+	} else {
+		// Load first so a typo fails before a live sample is taken
+		if base, err = baseline.Load(*dir, name); err != nil {
+			printError(err)
+			return 1
+		}
+	}
+
+	profile, err := captureWindow(*from, *to, *sample, *historyDir)
+	if err != nil {
+		printError(err)
+		if errors.Is(err, errBadWindow) {
+			return 2
+		}
+		return 1
+	}
+
+	if action == "save" {
+		profile.Name = name
+		if err := baseline.Save(*dir, profile); err != nil {
+			printError(err)
+			return 1
+		}
+		render.BaselineSaved(stdout, profile)
+		return 0
+	}
+	profile.Name = "current"
+	render.BaselineDiff(stdout, baseline.Diff(base, profile))
+	return 0
+}
+
+var errBadWindow = errors.New("invalid window")
+
+// captureWindow profiles either a live sample or a window of history
+func captureWindow(from, to string, sample time.Duration, historyDir string) (*baseline.Profile, error) {
+	if sample > 0 {
+		fmt.Fprintf(stdout, "Sampling for %s...\n", sample)
+		samples, err := sampleLive(context.Background(), sample, time.Second)
+		if err != nil {
+			return nil, err
+		}
+		return baseline.Capture(samples, "live", evaluatorOptions())
+	}
+
+	now := time.Now()
+	fromTime, err := history.ParseTime(from, now)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", errBadWindow, err)
+	}
+	toTime, err := history.ParseTime(to, now)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", errBadWindow, err)
+	}
+	store, err := history.OpenReadOnly(historyOptions(historyDir))
+	if err != nil {
+		return nil, err
+	}
+	defer store.Close()
+	return baseline.FromHistory(store, fromTime, toTime, evaluatorOptions())
+}
+
+// sampleLive collects a snapshot every interval for d
+func sampleLive(ctx context.Context, d, interval time.Duration) ([]metrics.SystemMetrics, error) {
+	// The first CPU reading must leave time for the next sample
+	collector, err := metrics.NewCollector(metrics.Options{SampleWindow: min(metrics.DefaultSampleWindow, interval/2)})
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(ctx, d)
+	defer cancel()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	var samples []metrics.SystemMetrics
+	for {
+		// Collector errors leave individual fields zero; keep the snapshot
+		if m, _ := collector.Collect(ctx); ctx.Err() == nil {
+			samples = append(samples, *m)
+		}
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return samples, nil
+		}
+	}
+}
+
+func listBaselines(dir string) int {
+	names, err := baseline.List(dir)
+	if err != nil {
+		printError(err)
+		return 1
+	}
+	var profiles []*baseline.Profile
+	for _, name := range names {
+		p, err := baseline.Load(dir, name)
+		if err != nil {
+			printError(err)
+			continue
+		}
+		profiles = append(profiles, p)
+	}
+	render.BaselineList(stdout, profiles)
+	return 0
+}
+
+// parseInterspersed parses flags that may appear before or after positional
+// arguments, e.g. "save before-upgrade -from 2h", and returns the positionals
+func parseInterspersed(fs *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+		if fs.NArg() == 0 {
+			return positional, nil
+		}
+		positional = append(positional, fs.Arg(0))
+		args = fs.Args()[1:]
+	}
+}
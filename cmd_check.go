@@ -0,0 +1,142 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/xmarkclx/bottleneck-check/analysis"
+	"github.com/xmarkclx/bottleneck-check/render"
+	"github.com/xmarkclx/bottleneck-check/schema"
+)
+
+// Exit codes of the check command
+const (
+	checkOK       = 0
+	checkMedium   = 1
+	checkHigh     = 2
+	checkCritical = 3
+	// checkUsage is returned for bad flags, so it can't be mistaken for a
+	// severity (EX_USAGE from sysexits.h)
+	checkUsage = 64
+	// checkFailed is returned when the system couldn't be sampled
+	checkFailed = 70
+)
+
+// runCheck samples for a while, analyzes once and exits with a code that
+// reflects the worst severity found
+func runCheck(args []string) int {
+	fs := flag.NewFlagSet("check", flag.ContinueOnError)
+	duration := fs.Duration("duration", cfg.Check.Duration.Duration, "how long to sample before analyzing")
+	interval := fs.Duration("interval", cfg.Check.Interval.Duration, "time between samples")
+	failOn := fs.String("fail-on", cfg.Check.FailOn, "lowest severity that makes the check fail: medium, high or critical")
+	quiet := fs.Bool("quiet", false, "print only the result line")
+	format := fs.String("format", "text", "output format: text, or json (see the JSON Output section of the README)")
+	display := addOutputFlags(fs)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: bottleneck-check check [flags]\n\n")
+		fmt.Fprintf(fs.Output(), "Exit codes: 0 ok, 1 medium, 2 high, 3 critical, %d usage error, %d sampling failed.\n", checkUsage, checkFailed)
+		fmt.Fprintf(fs.Output(), "Severities below -fail-on exit 0.\n\n")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return checkOK
+		}
+		return checkUsage
+	}
+	if err := display.apply(); err != nil {
+		printError(err)
+		return checkUsage
+	}
+	threshold, err := analysis.ParseSeverity(*failOn)
+	if err == nil && threshold.Rank() < analysis.SeverityMedium.Rank() {
+		err = fmt.Errorf("-fail-on must be medium, high or critical, not %s", threshold)
+	}
+	if err != nil {
+		printError(err)
+		return checkUsage
+	}
+	if *duration <= 0 || *interval <= 0 {
+		printError(fmt.Errorf("-duration and -interval must be positive"))
+		return checkUsage
+	}
+	if *duration < 2**interval {
+		// A single sample can't show that a condition is sustained
+		printError(fmt.Errorf("-duration (%s) must be at least twice -interval (%s)", *duration, *interval))
+		return checkUsage
+	}
+	if *format != "text" && *format != "json" {
+		printError(fmt.Errorf("-format must be text or json, not %q", *format))
+		return checkUsage
+	}
+
+	if !*quiet && *format == "text" {
+		fmt.Fprintf(stdout, "Sampling for %s...\n", *duration)
+	}
+	samples, err := sampleLive(context.Background(), *duration, *interval)
+	if err == nil && len(samples) < 2 {
+		err = fmt.Errorf("only %d samples collected in %s", len(samples), *duration)
+	}
+	if err != nil {
+		printError(err)
+		return checkFailed
+	}
+
+	// Judge sustained conditions over the whole check: a condition must hold
+	// for most of it, and the first half is warm-up. The samples may span a
+	// little less than the duration, as the first one takes a while.
+	opts := evaluatorOptions()
+	span := samples[len(samples)-1].Timestamp.Sub(samples[0].Timestamp)
+	opts.Window, opts.Warmup = *duration, min(*duration/2, span)
+	evaluator, err := analysis.NewEvaluator(opts)
+	if err != nil {
+		printError(err)
+		return checkFailed
+	}
+	recommendations := evaluator.Evaluate(samples)
+	recommendations = append(recommendations, analysis.DetectTrends(samples, analysis.TrendOptions{})...)
+
+	worst := analysis.Worst(recommendations)
+	code := checkExitCode(worst, threshold)
+	if *format == "json" {
+		latest := &samples[len(samples)-1]
+		doc := schema.New(schema.KindCheck, latest, recommendations, nil)
+		doc.Window = &schema.Window{From: samples[0].Timestamp, To: latest.Timestamp, Samples: len(samples)}
+		if err := schema.Write(os.Stdout, doc, true); err != nil {
+			printError(err)
+			return checkFailed
+		}
+		return code
+	}
+	if !*quiet {
+		latest := &samples[len(samples)-1]
+		fmt.Fprintln(stdout)
+		render.QuickStatus(stdout, latest, samples)
+		render.HistorySummary(stdout, samples)
+		fmt.Fprintln(stdout)
+		render.Recommendations(stdout, recommendations)
+		fmt.Fprintln(stdout)
+	}
+	render.CheckResult(stdout, worst, len(recommendations), code)
+	return code
+}
+
+// checkExitCode maps the worst severity to an exit code, treating anything
+// below threshold as ok
+func checkExitCode(worst, threshold analysis.Severity) int {
+	if worst.Rank() < threshold.Rank() {
+		return checkOK
+	}
+	switch worst {
+	case analysis.SeverityCritical:
+		return checkCritical
+	case analysis.SeverityHigh:
+		return checkHigh
+	case analysis.SeverityMedium:
+		return checkMedium
+	}
+	return checkOK
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/xmarkclx/bottleneck-check/analysis"
+)
+
+func TestCheckExitCode(t *testing.T) {
+	tests := []struct {
+		worst, threshold analysis.Severity
+		want             int
+	}{
+		{"", analysis.SeverityMedium, checkOK},
+		{analysis.SeverityLow, analysis.SeverityMedium, checkOK},
+		{analysis.SeverityMedium, analysis.SeverityMedium, checkMedium},
+		{analysis.SeverityHigh, analysis.SeverityMedium, checkHigh},
+		{analysis.SeverityCritical, analysis.SeverityMedium, checkCritical},
+		{analysis.SeverityMedium, analysis.SeverityHigh, checkOK},
+		{analysis.SeverityHigh, analysis.SeverityHigh, checkHigh},
+		{analysis.SeverityHigh, analysis.SeverityCritical, checkOK},
+		{analysis.SeverityCritical, analysis.SeverityCritical, checkCritical},
+	}
+	for _, tt := range tests {
+		if got := checkExitCode(tt.worst, tt.threshold); got != tt.want {
+			t.Errorf("checkExitCode(%s, %s) = %d, want %d", tt.worst, tt.threshold, got, tt.want)
+		}
+	}
+}
+
+func TestRunCheckUsage(t *testing.T) {
+	tests := [][]string{
+		{"-fail-on", "low"},
+		{"-fail-on", "bogus"},
+		{"-duration", "0s"},
+		{"-duration", "1s", "-interval", "1s"},
+		{"-format", "yaml"},
+		{"-no-such-flag"},
+	}
+	for _, args := range tests {
+		if got := runCheck(args); got != checkUsage {
+			t.Errorf("runCheck(%q) = %d, want %d", args, got, checkUsage)
+		}
+	}
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+
+	"github.com/xmarkclx/bottleneck-check/profile"
+	"github.com/xmarkclx/bottleneck-check/render"
+)
+
+// runCompare compares two saved run profiles
+func runCompare(args []string) int {
+	fs := flag.NewFlagSet("compare", flag.ContinueOnError)
+	display := addOutputFlags(fs)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: bottleneck-check compare <run A> <run B>\n\n")
+		fmt.Fprintf(fs.Output(), "Runs are profile files saved by 'bottleneck-check run', given as a path\n")
+		fmt.Fprintf(fs.Output(), "or as a file name in %s.\n", profile.Dir())
+	}
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if err := display.apply(); err != nil {
+		printError(err)
+		return 2
+	}
+	if fs.NArg() != 2 {
+		fs.Usage()
+		return 2
+	}
+
+	var runs [2]*profile.Profile
+	for i, name := range fs.Args() {
+		path, err := profile.Resolve(name)
+		if err != nil {
+			printError(err)
+			return 1
+		}
+		if runs[i], err = profile.Load(path); err != nil {
+			printError(err)
+			return 1
+		}
+	}
+	render.RunComparison(stdout, profile.Compare(runs[0], runs[1]))
+	return 0
+}
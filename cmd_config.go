@@ -0,0 +1,168 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/xmarkclx/bottleneck-check/config"
+	"github.com/xmarkclx/bottleneck-check/render"
+)
+
+// cfg is the effective configuration. Commands take their flag defaults
+// from it, so flags override the environment and the config file.
+var cfg = config.Default()
+
+// newGlobalFlags returns the flags accepted before the command
+func newGlobalFlags() (*flag.FlagSet, *string) {
+	fs := flag.NewFlagSet("bottleneck-check", flag.ContinueOnError)
+	path := fs.String("config", "", "config file (default $"+config.PathEnv+" or "+config.DefaultPath()+")")
+	fs.String("color", "", "color output: auto, always or never")
+	fs.Bool("plain", false, "plain ASCII output without colors, emoji or symbols")
+	return fs, path
+}
+
+// loadConfig parses the global flags at the start of args, loads the
+// configuration and returns the command and its arguments, or an exit code
+// on error. The global flags end at the first argument that isn't one, so
+// the monitor's flags still work without naming the command.
+func loadConfig(args []string) ([]string, int) {
+	fs, path := newGlobalFlags()
+	fs.SetOutput(stderr)
+	fs.Usage = func() {
+		fmt.Fprintf(stderr, "Run 'bottleneck-check help' for usage.\n")
+	}
+	n := globalArgs(fs, args)
+	// check reserves 2 for a HIGH finding
+	usage := 2
+	if n < len(args) && args[n] == "check" {
+		usage = checkUsage
+	}
+	if err := fs.Parse(args[:n]); err != nil {
+		return nil, usage
+	}
+
+	c, err := config.Load(*path)
+	fs.Visit(func(f *flag.Flag) {
+		if f.Name != "config" && err == nil {
+			err = c.Set(f.Name, f.Value.String(), config.SourceFlag)
+		}
+	})
+	var mode render.ColorMode
+	if err == nil {
+		mode, err = render.ParseColorMode(c.Color)
+	}
+	if err != nil {
+		printError(err)
+		return nil, usage
+	}
+	cfg = c
+	setOutput(mode, cfg.Plain)
+	return args[n:], 0
+}
+
+// globalArgs counts the arguments at the start of args that are global
+// flags and their values
+func globalArgs(fs *flag.FlagSet, args []string) int {
+	for i := 0; i < len(args); i++ {
+		if !strings.HasPrefix(args[i], "-") {
+			return i
+		}
+		name, _, hasValue := strings.Cut(strings.TrimLeft(args[i], "-"), "=")
+		f := fs.Lookup(name)
+		if f == nil {
+			return i
+		}
+		if b, ok := f.Value.(interface{ IsBoolFlag() bool }); !hasValue && !(ok && b.IsBoolFlag()) {
+			i++
+		}
+	}
+	return len(args)
+}
+
+// maxShownValue is the widest value config show prints
+const maxShownValue = 60
+
+// runConfig shows the effective configuration or where it is read from
+func runConfig(args []string) int {
+	usage := func() {
+		fmt.Fprintf(stderr, "Usage: bottleneck-check config <show|path> [flags]\n\n")
+		fmt.Fprintf(stderr, "  show   Print the effective configuration and where each value comes from\n")
+		fmt.Fprintf(stderr, "  path   Print the config file's path\n")
+	}
+	if len(args) == 0 {
+		usage()
+		return 2
+	}
+	action, args := args[0], args[1:]
+
+	fs := flag.NewFlagSet("config "+action, flag.ContinueOnError)
+	format := fs.String("format", "text", "output format: text, or json in the config file's format, without secrets")
+	display := addOutputFlags(fs)
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return 0
+		}
+		return 2
+	}
+	if err := display.apply(); err != nil {
+		printError(err)
+		return 2
+	}
+
+	switch action {
+	case "show":
+	case "path":
+		fmt.Fprintln(stdout, cfg.Path())
+		return 0
+	case "help", "-h", "--help":
+		usage()
+		return 0
+	default:
+		fmt.Fprintf(stderr, "Unknown config action: '%s'\n\n", action)
+		usage()
+		return 2
+	}
+
+	switch *format {
+	case "json":
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(cfg.Redacted()); err != nil {
+			printError(err)
+			return 1
+		}
+		return 0
+	case "text":
+	default:
+		printError(fmt.Errorf("-format must be text or json, not %q", *format))
+		return 2
+	}
+
+	file := cfg.Path()
+	if !cfg.Found() {
+		file += " (not found, using defaults)"
+	}
+	fmt.Fprintf(stdout, "%s🔧 Configuration%s\n", render.ColorBold, render.ColorReset)
+	fmt.Fprintf(stdout, "Config file: %s\n", file)
+	fmt.Fprintf(stdout, "Precedence: flags > environment > config file > defaults\n\n")
+	tw := tabwriter.NewWriter(stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(tw, "KEY\tVALUE\tSOURCE\tENVIRONMENT\n")
+	for _, s := range cfg.Settings() {
+		value := strings.ReplaceAll(s.Value, "\n", `\n`)
+		if value == "" {
+			value = `""`
+		}
+		// Long values such as templates are shown in full by -format json
+		if r := []rune(value); len(r) > maxShownValue {
+			value = string(r[:maxShownValue-1]) + "…"
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", s.Key, value, s.Source, s.Env)
+	}
+	tw.Flush()
+	return 0
+}
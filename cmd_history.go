@@ -0,0 +1,118 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"time"
+
+	"github.com/xmarkclx/bottleneck-check/history"
+	"github.com/xmarkclx/bottleneck-check/metrics"
+	"github.com/xmarkclx/bottleneck-check/render"
+)
+
+// runHistory prints a recorded metric over a time range
+func runHistory(args []string) int {
+	fs := flag.NewFlagSet("history", flag.ContinueOnError)
+	metric := fs.String("metric", metrics.MetricCPUUsage, "metric to query (see -list)")
+	from := fs.String("from", "1h", "start of range: time ago (90m, 2h, 7d), date or RFC 3339 time")
+	to := fs.String("to", "now", "end of range, same formats as -from")
+	res := fs.String("res", "auto", "resolution: auto, raw, 1m or 1h")
+	dir := fs.String("dir", cfg.HistoryDir, "history directory")
+	list := fs.Bool("list", false, "list the available metrics and exit")
+	display := addOutputFlags(fs)
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if err := display.apply(); err != nil {
+		printError(err)
+		return 2
+	}
+
+	if *list {
+		for _, series := range metrics.AllSeries {
+			fmt.Fprintf(stdout, "%-22s %s\n", series.Name, series.Help)
+		}
+		return 0
+	}
+
+	series, ok := metrics.LookupSeries(*metric)
+	if !ok {
+		printError(fmt.Errorf("unknown metric %q (see -list)", *metric))
+		return 2
+	}
+	now := time.Now()
+	fromTime, err := history.ParseTime(*from, now)
+	if err != nil {
+		printError(err)
+		return 2
+	}
+	toTime, err := history.ParseTime(*to, now)
+	if err != nil {
+		printError(err)
+		return 2
+	}
+	resolution, err := history.ParseResolution(*res)
+	if err != nil {
+		printError(err)
+		return 2
+	}
+
+	store, err := history.OpenReadOnly(historyOptions(*dir))
+	if err != nil {
+		printError(fmt.Errorf("no history at %s: %w", *dir, err))
+		return 1
+	}
+	defer store.Close()
+
+	points, used, err := store.Query(series.Name, fromTime, toTime, resolution)
+	if err != nil {
+		printError(err)
+		return 1
+	}
+
+	fmt.Fprintf(stdout, "%s📈 %s%s (%s)\n", render.ColorBold, series.Name, render.ColorReset, series.Help)
+	fmt.Fprintf(stdout, "%s → %s | %s resolution | %d points\n",
+		fromTime.Format("2006-01-02 15:04:05"), toTime.Format("2006-01-02 15:04:05"), used, len(points))
+	fmt.Fprintf(stdout, "─────────────────────────────────────────────────────────────\n")
+	if len(points) == 0 {
+		fmt.Fprintf(stdout, "%sNo data recorded in this range.%s\n", render.ColorYellow, render.ColorReset)
+		return 0
+	}
+
+	annotations, err := store.Annotations(fromTime, toTime)
+	if err != nil {
+		printError(err)
+		return 1
+	}
+
+	format := render.FormatValue(series.Unit)
+	if used == history.ResolutionRaw {
+		fmt.Fprintf(stdout, "%-19s  %12s\n", "Time", "Value")
+		for _, p := range points {
+			fmt.Fprintf(stdout, "%-19s  %12s\n", p.Time.Local().Format("2006-01-02 15:04:05"), format(p.Avg))
+		}
+	} else {
+		fmt.Fprintf(stdout, "%-16s  %12s %12s %12s %12s %7s\n", "Time", "Min", "Avg", "Max", "P95", "Samples")
+		for _, p := range points {
+			fmt.Fprintf(stdout, "%-16s  %12s %12s %12s %12s %7d\n", p.Time.Local().Format("2006-01-02 15:04"),
+				format(p.Min), format(p.Avg), format(p.Max), format(p.P95), p.Count)
+		}
+	}
+
+	if len(annotations) > 0 {
+		fmt.Fprintf(stdout, "\n%s📝 Annotations%s\n", render.ColorBold, render.ColorReset)
+		render.AnnotationList(stdout, annotations)
+	}
+	return 0
+}
+
+// historyOptions opens the store in dir with the configured retention, so
+// readers pick the resolutions the recorder keeps
+func historyOptions(dir string) history.Options {
+	return history.Options{
+		Dir:             dir,
+		RawRetention:    cfg.History.RawRetention.Duration,
+		MinuteRetention: cfg.History.MinuteRetention.Duration,
+		HourRetention:   cfg.History.HourRetention.Duration,
+	}
+}
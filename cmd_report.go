@@ -0,0 +1,59 @@
+package main
+
+import (
+	"flag"
+	"time"
+
+	"github.com/xmarkclx/bottleneck-check/history"
+	"github.com/xmarkclx/bottleneck-check/render"
+	"github.com/xmarkclx/bottleneck-check/report"
+)
+
+// runReport summarizes recorded history over a date range
+func runReport(args []string) int {
+	fs := flag.NewFlagSet("report", flag.ContinueOnError)
+	from := fs.String("from", "7d", "start of range: time ago (90m, 2h, 7d), date or RFC 3339 time")
+	to := fs.String("to", "now", "end of range, same formats as -from")
+	res := fs.String("res", "auto", "resolution: auto (finest retained), raw, 1m or 1h")
+	dir := fs.String("dir", cfg.HistoryDir, "history directory")
+	display := addOutputFlags(fs)
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if err := display.apply(); err != nil {
+		printError(err)
+		return 2
+	}
+
+	now := time.Now()
+	fromTime, err := history.ParseTime(*from, now)
+	if err != nil {
+		printError(err)
+		return 2
+	}
+	toTime, err := history.ParseTime(*to, now)
+	if err != nil {
+		printError(err)
+		return 2
+	}
+	resolution, err := history.ParseResolution(*res)
+	if err != nil {
+		printError(err)
+		return 2
+	}
+
+	store, err := history.OpenReadOnly(historyOptions(*dir))
+	if err != nil {
+		printError(err)
+		return 1
+	}
+	defer store.Close()
+
+	r, err := report.Build(store, report.Options{From: fromTime, To: toTime, Resolution: resolution, Thresholds: &cfg.Thresholds})
+	if err != nil {
+		printError(err)
+		return 1
+	}
+	render.Report(stdout, r)
+	return 0
+}
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/signal"
+	"syscall"
+
+	"github.com/xmarkclx/bottleneck-check/profile"
+	"github.com/xmarkclx/bottleneck-check/render"
+)
+
+// Exit codes of the run command when it can't report the command's own,
+// as env and timeout use them
+const (
+	// runFailed is returned when profiling fails after the command started
+	runFailed = 125
+	// runNotStarted is returned when the command couldn't be started
+	runNotStarted = 127
+)
+
+// runCommand profiles a command until it exits and returns its exit code
+func runCommand(args []string) int {
+	fs := flag.NewFlagSet("run", flag.ContinueOnError)
+	interval := fs.Duration("interval", profile.DefaultInterval, "time between samples")
+	output := fs.String("o", "", "file to save the run profile to (default: a new file in "+profile.Dir()+")")
+	noSave := fs.Bool("no-save", false, "don't save the run profile")
+	display := addOutputFlags(fs)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: bottleneck-check run [flags] -- <command> [args...]\n\n")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if err := display.apply(); err != nil {
+		printError(err)
+		return 2
+	}
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return 2
+	}
+
+	cmd := exec.Command(fs.Arg(0), fs.Args()[1:]...)
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+
+	// Stay alive to report on the command, and pass interrupt and
+	// termination requests on to it. Ctrl-C at a terminal reaches it
+	// directly as well, as both are in the foreground process group.
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	started := make(chan *os.Process, 1)
+	done := make(chan struct{})
+	go forwardSignals(signals, started, done)
+
+	launched := false
+	p, err := profile.Run(context.Background(), cmd, profile.Options{
+		Interval: *interval,
+		Started: func(process *os.Process) {
+			launched = true
+			started <- process
+		},
+	})
+	// Signals during the report end the wrapper as usual
+	signal.Stop(signals)
+	close(done)
+	if err != nil {
+		printError(err)
+		if !launched {
+			return runNotStarted
+		}
+		return runFailed
+	}
+
+	// The report goes to stderr so the command's own output stays clean
+	render.RunProfile(stderr, p)
+	if !*noSave {
+		path := *output
+		if path == "" {
+			path = profile.DefaultPath(p)
+		}
+		if err := profile.Save(path, p); err != nil {
+			printError(err)
+		} else {
+			fmt.Fprintf(stderr, "\nProfile saved to %s\n", path)
+		}
+	}
+	return p.ExitCode
+}
+
+// forwardSignals passes signals on to the process once it has started,
+// holding those that arrive before, until done is closed
+func forwardSignals(signals <-chan os.Signal, started <-chan *os.Process, done <-chan struct{}) {
+	var process *os.Process
+	var pending []os.Signal
+	for {
+		select {
+		case process = <-started:
+			for _, sig := range pending {
+				process.Signal(sig)
+			}
+			pending = nil
+		case sig := <-signals:
+			if process == nil {
+				pending = append(pending, sig)
+				continue
+			}
+			process.Signal(sig)
+		case <-done:
+			return
+		}
+	}
+}
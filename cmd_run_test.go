@@ -0,0 +1,54 @@
+package main
+
+import (
+	"os"
+	"os/exec"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestForwardSignals(t *testing.T) {
+	cmd := exec.Command("sleep", "60")
+	signals := make(chan os.Signal, 1)
+	started := make(chan *os.Process, 1)
+	done := make(chan struct{})
+	returned := make(chan struct{})
+	go func() {
+		forwardSignals(signals, started, done)
+		close(returned)
+	}()
+
+	// A signal before the command starts is held until it has
+	signals <- syscall.SIGTERM
+	if err := cmd.Start(); err != nil {
+		t.Skipf("can't start sleep: %v", err)
+	}
+	defer cmd.Process.Kill()
+	started <- cmd.Process
+
+	exited := make(chan error, 1)
+	go func() { exited <- cmd.Wait() }()
+	select {
+	case <-exited:
+	case <-time.After(5 * time.Second):
+		t.Fatal("the queued SIGTERM didn't reach the command")
+	}
+	status, ok := cmd.ProcessState.Sys().(syscall.WaitStatus)
+	if !ok || !status.Signaled() || status.Signal() != syscall.SIGTERM {
+		t.Errorf("command ended with %v, want SIGTERM", cmd.ProcessState)
+	}
+
+	close(done)
+	select {
+	case <-returned:
+	case <-time.After(5 * time.Second):
+		t.Error("forwardSignals didn't return once done was closed")
+	}
+}
+
+func TestRunCommandNotStarted(t *testing.T) {
+	if got := runCommand([]string{"-no-save", "--", "/nonexistent/command"}); got != runNotStarted {
+		t.Errorf("runCommand() = %d, want %d", got, runNotStarted)
+	}
+}
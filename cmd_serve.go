@@ -0,0 +1,114 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/xmarkclx/bottleneck-check/sampler"
+	"github.com/xmarkclx/bottleneck-check/server"
+)
+
+// monitorServer, when set, is kept current by updateSystemData
+var monitorServer *server.Server
+
+// runServe monitors in the background and serves the results over HTTP
+// until interrupted
+func runServe(args []string) int {
+	fs := flag.NewFlagSet("serve", flag.ContinueOnError)
+	listen := fs.String("listen", cfg.Serve.Listen, "address to listen on, e.g. :9310 for all interfaces or unix:/run/bottleneck-check.sock")
+	token := fs.String("token", "", "bearer token clients must send (default: the token setting, e.g. $BOTTLENECK_CHECK_TOKEN, so it needn't appear in the process list)")
+	historyDir := fs.String("history-dir", cfg.HistoryDir, "directory for recorded metrics history")
+	noHistory := fs.Bool("no-history", cfg.NoHistory, "don't record metrics history to disk")
+	maxClients := fs.Int("max-clients", cfg.Serve.MaxClients, "most /v1/stream clients connected at once")
+	display := addOutputFlags(fs)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: bottleneck-check serve [flags]\n\n")
+		fmt.Fprintf(fs.Output(), "Endpoints:\n")
+		fmt.Fprintf(fs.Output(), "  /                    Web dashboard\n")
+		fmt.Fprintf(fs.Output(), "  /metrics             Prometheus metrics\n")
+		fmt.Fprintf(fs.Output(), "  /v1/snapshot         Latest snapshot\n")
+		fmt.Fprintf(fs.Output(), "  /v1/recommendations  Active recommendations and anomalies\n")
+		fmt.Fprintf(fs.Output(), "  /v1/health           Health summary (503 while critical)\n")
+		fmt.Fprintf(fs.Output(), "  /v1/history          ?metric=cpu.usage&from=1h&to=now&res=auto\n")
+		fmt.Fprintf(fs.Output(), "  /v1/stream           Server-Sent Events of every update and recommendation change\n\n")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return 0
+		}
+		return 2
+	}
+	if err := display.apply(); err != nil {
+		printError(err)
+		return 2
+	}
+	if *token == "" {
+		*token = cfg.Token
+	}
+
+	s, e, stop, err := startMonitor(*historyDir, *noHistory, stderr)
+	if err != nil {
+		printError(err)
+		return 1
+	}
+	defer stop()
+	monitorSampler = s
+	monitorEvaluator = e
+
+	httpServer, served, err := startServer(*listen, server.Options{Token: *token, MaxClients: *maxClients}, s, stderr)
+	if err != nil {
+		printError(err)
+		return 1
+	}
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+
+	// Re-evaluate on every sample so clients always see the latest state
+	samples, unsubscribe := s.Subscribe(1)
+	defer unsubscribe()
+	for {
+		select {
+		case <-samples:
+			updateSystemData()
+		case err := <-served:
+			printError(err)
+			return 1
+		case <-ctx.Done():
+			shutdown, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			if err := httpServer.Shutdown(shutdown); err != nil {
+				printError(err)
+				return 1
+			}
+			return 0
+		}
+	}
+}
+
+// startServer listens on addr and serves the monitor state in the
+// background. The returned channel receives the error that stopped serving.
+// The collector and history store are filled into opts.
+func startServer(addr string, opts server.Options, s *sampler.Sampler, notices io.Writer) (*http.Server, <-chan error, error) {
+	listener, err := server.Listen(addr)
+	if err != nil {
+		return nil, nil, err
+	}
+	opts.Collector, opts.Store = s.Collector(), monitorStore
+	monitorServer = server.New(opts)
+	httpServer := &http.Server{Handler: monitorServer.Handler(), ReadHeaderTimeout: 10 * time.Second}
+	httpServer.RegisterOnShutdown(monitorServer.Close)
+	served := make(chan error, 1)
+	go func() { served <- httpServer.Serve(listener) }()
+	fmt.Fprintf(notices, "Serving on %s\n", server.URL(listener))
+	return httpServer, served, nil
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"slices"
+	"syscall"
+	"time"
+
+	"github.com/xmarkclx/bottleneck-check/analysis"
+	"github.com/xmarkclx/bottleneck-check/metrics"
+	"github.com/xmarkclx/bottleneck-check/sampler"
+	"github.com/xmarkclx/bottleneck-check/statusline"
+)
+
+// runStatus prints a one-line summary every interval for status bars until
+// interrupted or the bar stops reading
+func runStatus(args []string) int {
+	fs := flag.NewFlagSet("status", flag.ContinueOnError)
+	format := fs.String("format", cfg.Status.Format, "output format: text, i3bar (also swaybar) or waybar")
+	text := fs.String("template", cfg.Status.Template, "Go template for the line (see the README for fields)")
+	interval := fs.Duration("interval", cfg.Status.Interval.Duration, "time between lines")
+	display := addOutputFlags(fs)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: bottleneck-check status [flags]\n\n")
+		fmt.Fprintf(fs.Output(), "Examples:\n")
+		fmt.Fprintf(fs.Output(), "  tmux:   set -g status-right '#(bottleneck-check status)'\n")
+		fmt.Fprintf(fs.Output(), "  i3bar:  status_command bottleneck-check status -format i3bar\n")
+		fmt.Fprintf(fs.Output(), "  waybar: \"exec\": \"bottleneck-check status -format waybar\", \"return-type\": \"json\"\n\n")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return 0
+		}
+		return 2
+	}
+	if err := display.apply(); err != nil {
+		printError(err)
+		return 2
+	}
+	f, err := statusline.ParseFormat(*format)
+	if err != nil {
+		printError(err)
+		return 2
+	}
+	t, err := statusline.Parse(*text)
+	if err != nil {
+		printError(fmt.Errorf("invalid -template: %w", err))
+		return 2
+	}
+	if *interval <= 0 {
+		printError(fmt.Errorf("-interval must be positive"))
+		return 2
+	}
+
+	// Status lines don't show processes, so skip reading them all
+	collectors := slices.DeleteFunc(metrics.CollectorNames(), func(name string) bool {
+		return name == metrics.CollectorProcess
+	})
+	s, err := sampler.New(sampler.Options{
+		Interval: cfg.SampleInterval.Duration,
+		Metrics:  metrics.Options{Collectors: collectors},
+	})
+	if err != nil {
+		printError(err)
+		return 1
+	}
+	e, err := analysis.NewEvaluator(evaluatorOptions())
+	if err != nil {
+		printError(err)
+		return 1
+	}
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+	s.Start(ctx)
+	monitorSampler = s
+	monitorEvaluator = e
+
+	// JSON goes out unaltered; text follows -color and -plain like any
+	// other output
+	var out io.Writer = os.Stdout
+	if f == statusline.FormatText {
+		out = stdout
+	}
+	emitter := statusline.NewEmitter(out, f, t)
+
+	// Wait for the first sample so the first line has readings
+	firstSample, unsubscribe := s.Subscribe(1)
+	select {
+	case <-firstSample:
+		unsubscribe()
+	case <-ctx.Done():
+		unsubscribe()
+		return 0
+	}
+	ticker := time.NewTicker(*interval)
+	defer ticker.Stop()
+	for {
+		updateSystemData()
+		if err := emitter.Emit(statusline.New(lastMetrics, lastRecommendations)); err != nil {
+			printError(err)
+			return 1
+		}
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return 0
+		}
+	}
+}
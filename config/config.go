@@ -0,0 +1,297 @@
+// Package config holds the settings shared by all commands: the defaults,
+// overridden by a JSON file in the user's config directory, overridden in
+// turn by BOTTLENECK_CHECK_* environment variables. Command-line flags take
+// their defaults from the result, so they override everything.
+//
+// Every setting has a dotted key, e.g. "monitor.interval", named after its
+// place in the file. The environment variable for it is the key in capitals
+// with dots as underscores behind BOTTLENECK_CHECK_, e.g.
+// BOTTLENECK_CHECK_MONITOR_INTERVAL.
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/xmarkclx/bottleneck-check/analysis"
+	"github.com/xmarkclx/bottleneck-check/history"
+	"github.com/xmarkclx/bottleneck-check/sampler"
+	"github.com/xmarkclx/bottleneck-check/server"
+	"github.com/xmarkclx/bottleneck-check/statusline"
+)
+
+// EnvPrefix starts the name of every environment variable read
+const EnvPrefix = "BOTTLENECK_CHECK_"
+
+// PathEnv names the environment variable that points at the config file
+const PathEnv = EnvPrefix + "CONFIG"
+
+// FileName is the config file's name inside the config directory
+const FileName = "config.json"
+
+// Source is where a setting's value came from
+type Source string
+
+const (
+	SourceDefault Source = "default"
+	SourceFile    Source = "file"
+	SourceEnv     Source = "env"
+	SourceFlag    Source = "flag"
+)
+
+// Config is the effective configuration
+type Config struct {
+	// Color is auto, always or never, and Plain selects ASCII output
+	Color string `json:"color"`
+	Plain bool   `json:"plain"`
+	// HistoryDir is where metrics history is recorded and read
+	HistoryDir string `json:"history_dir"`
+	NoHistory  bool   `json:"no_history"`
+	// SampleInterval is how often the background sampler reads the system
+	SampleInterval Duration `json:"sample_interval"`
+	// Token is the bearer token API clients must send
+	Token string `json:"token" config:"secret"`
+
+	History    History             `json:"history"`
+	Alerts     Alerts              `json:"alerts"`
+	Thresholds analysis.Thresholds `json:"thresholds"`
+	Monitor    Monitor             `json:"monitor"`
+	Check      Check               `json:"check"`
+	Serve      Serve               `json:"serve"`
+	Status     Status              `json:"status"`
+
+	path    string
+	found   bool
+	sources map[string]Source
+}
+
+// History sets how long each resolution of the metrics history is kept.
+// Readers use it too, to pick a resolution the recorder still has.
+type History struct {
+	RawRetention    Duration `json:"raw_retention"`
+	MinuteRetention Duration `json:"minute_retention"`
+	HourRetention   Duration `json:"hour_retention"`
+}
+
+// Alerts sets how long usage conditions must hold before they are raised
+type Alerts struct {
+	Window Duration `json:"window"`
+	Warmup Duration `json:"warmup"`
+}
+
+// Monitor configures the interactive monitor
+type Monitor struct {
+	// Interval is the time between redraws
+	Interval Duration `json:"interval"`
+	// Listen, when set, also serves the API as serve does
+	Listen string `json:"listen"`
+	// Views lists the views shown, in tab order, by title
+	Views []string `json:"views"`
+}
+
+// Check configures the one-shot check
+type Check struct {
+	Duration Duration `json:"duration"`
+	Interval Duration `json:"interval"`
+	FailOn   string   `json:"fail_on"`
+}
+
+// Serve configures the HTTP server
+type Serve struct {
+	Listen     string `json:"listen"`
+	MaxClients int    `json:"max_clients"`
+}
+
+// Status configures status line output
+type Status struct {
+	Format   string   `json:"format"`
+	Template string   `json:"template"`
+	Interval Duration `json:"interval"`
+}
+
+// DefaultViews are the monitor's views, in tab order
+var DefaultViews = []string{"overview", "cpu", "memory", "gpu", "processes", "charts", "events", "details"}
+
+// Default returns the built-in configuration
+func Default() *Config {
+	return &Config{
+		Color:          "auto",
+		HistoryDir:     history.DefaultDir(),
+		SampleInterval: Duration{sampler.DefaultInterval},
+		History: History{
+			RawRetention:    Duration{history.DefaultRawRetention},
+			MinuteRetention: Duration{history.DefaultMinuteRetention},
+			HourRetention:   Duration{history.DefaultHourRetention},
+		},
+		Alerts: Alerts{
+			Window: Duration{analysis.DefaultWindow},
+			Warmup: Duration{analysis.DefaultWarmup},
+		},
+		Thresholds: analysis.DefaultThresholds(),
+		Monitor: Monitor{
+			Interval: Duration{10 * time.Second},
+			Views:    append([]string(nil), DefaultViews...),
+		},
+		Check: Check{
+			Duration: Duration{30 * time.Second},
+			Interval: Duration{time.Second},
+			FailOn:   "medium",
+		},
+		Serve: Serve{
+			Listen:     "127.0.0.1:9310",
+			MaxClients: server.DefaultMaxClients,
+		},
+		Status: Status{
+			Format:   string(statusline.FormatText),
+			Template: statusline.DefaultTemplate,
+			Interval: Duration{5 * time.Second},
+		},
+		sources: make(map[string]Source),
+	}
+}
+
+// DefaultPath returns the config file's usual location:
+// $XDG_CONFIG_HOME/bottleneck-check/config.json on Linux and the platform's
+// usual settings location elsewhere
+func DefaultPath() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "bottleneck-check", FileName)
+}
+
+// Load returns the defaults overridden by the file at path and then by the
+// environment. With an empty path it reads $BOTTLENECK_CHECK_CONFIG, or
+// the file at DefaultPath if there is one; a file named explicitly must
+// exist.
+func Load(path string) (*Config, error) {
+	c := Default()
+	required := true
+	if path == "" {
+		path = os.Getenv(PathEnv)
+	}
+	if path == "" {
+		path, required = DefaultPath(), false
+	}
+	c.path = path
+
+	data, err := os.ReadFile(path)
+	switch {
+	case errors.Is(err, fs.ErrNotExist) && !required:
+	case err != nil:
+		return nil, err
+	default:
+		c.found = true
+		if err := c.readFile(data); err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+	}
+	if err := c.readEnv(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// readFile applies the settings in a config file, rejecting unknown keys
+// so a misspelling doesn't go unnoticed
+func (c *Config) readFile(data []byte) error {
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	fields := c.fields()
+	var apply func(prefix string, raw map[string]json.RawMessage) error
+	apply = func(prefix string, raw map[string]json.RawMessage) error {
+		for name, value := range raw {
+			key := prefix + name
+			if f, ok := fields[key]; ok {
+				if err := json.Unmarshal(value, f.value.Addr().Interface()); err != nil {
+					return fmt.Errorf("%s: %w", key, err)
+				}
+				c.sources[key] = SourceFile
+				continue
+			}
+			if !isSection(fields, key) {
+				return fmt.Errorf("unknown setting %q", key)
+			}
+			var section map[string]json.RawMessage
+			if err := json.Unmarshal(value, &section); err != nil {
+				return fmt.Errorf("%s: want an object of settings", key)
+			}
+			if err := apply(key+".", section); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return apply("", raw)
+}
+
+// readEnv applies the BOTTLENECK_CHECK_* variables that are set and not
+// empty
+func (c *Config) readEnv() error {
+	for _, f := range c.list() {
+		value := os.Getenv(EnvName(f.key))
+		if value == "" {
+			continue
+		}
+		if err := c.Set(f.key, value, SourceEnv); err != nil {
+			return fmt.Errorf("$%s: %w", EnvName(f.key), err)
+		}
+	}
+	return nil
+}
+
+// Set changes a setting from its text form, as environment variables and
+// flags give it. Lists are comma-separated.
+func (c *Config) Set(key, value string, source Source) error {
+	f, ok := c.fields()[key]
+	if !ok {
+		return fmt.Errorf("unknown setting %q", key)
+	}
+	if err := parseValue(f.value, value); err != nil {
+		return fmt.Errorf("%s: %w", key, err)
+	}
+	c.sources[key] = source
+	return nil
+}
+
+// Path returns the config file read, or that would have been read
+func (c *Config) Path() string {
+	return c.path
+}
+
+// Found reports whether the config file existed
+func (c *Config) Found() bool {
+	return c.found
+}
+
+// EnvName returns the environment variable for a setting
+func EnvName(key string) string {
+	return EnvPrefix + envKey(key)
+}
+
+// Duration is a time.Duration written as text, e.g. "10s", "5m" or "7d",
+// in the config file
+type Duration struct {
+	time.Duration
+}
+
+func (d Duration) MarshalText() ([]byte, error) {
+	return []byte(d.String()), nil
+}
+
+func (d *Duration) UnmarshalText(text []byte) error {
+	v, err := history.ParseDuration(string(text))
+	if err != nil {
+		return err
+	}
+	d.Duration = v
+	return nil
+}
@@ -0,0 +1,151 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// writeConfig writes a config file into a temporary directory
+func writeConfig(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), FileName)
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// source returns where a setting's value came from
+func source(c *Config, key string) Source {
+	for _, s := range c.Settings() {
+		if s.Key == key {
+			return s.Source
+		}
+	}
+	return ""
+}
+
+func TestLoadPrecedence(t *testing.T) {
+	path := writeConfig(t, `{
+		"check": {"duration": "1m", "interval": "2s", "fail_on": "high"},
+		"thresholds": {"cpu_high": 60}
+	}`)
+	t.Setenv(EnvName("check.interval"), "5s")
+	t.Setenv(EnvName("check.fail_on"), "critical")
+
+	c, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Set("check.fail_on", "medium", SourceFlag); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		key    string
+		got    any
+		want   any
+		source Source
+	}{
+		{"check.duration", c.Check.Duration.Duration, time.Minute, SourceFile},
+		{"check.interval", c.Check.Interval.Duration, 5 * time.Second, SourceEnv},
+		{"check.fail_on", c.Check.FailOn, "medium", SourceFlag},
+		{"thresholds.cpu_high", c.Thresholds.CPUHigh, 60.0, SourceFile},
+		{"thresholds.cpu_critical", c.Thresholds.CPUCritical, Default().Thresholds.CPUCritical, SourceDefault},
+	}
+	for _, tt := range tests {
+		if tt.got != tt.want {
+			t.Errorf("%s = %v, want %v", tt.key, tt.got, tt.want)
+		}
+		if got := source(c, tt.key); got != tt.source {
+			t.Errorf("%s source = %s, want %s", tt.key, got, tt.source)
+		}
+	}
+	if !c.Found() || c.Path() != path {
+		t.Errorf("Found() = %v, Path() = %q; want true, %q", c.Found(), c.Path(), path)
+	}
+}
+
+func TestLoadPathFromEnv(t *testing.T) {
+	path := writeConfig(t, `{"history": {"raw_retention": "7d"}}`)
+	t.Setenv(PathEnv, path)
+
+	c, err := Load("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.Path() != path {
+		t.Errorf("Path() = %q, want %q", c.Path(), path)
+	}
+	if got, want := c.History.RawRetention.Duration, 7*24*time.Hour; got != want {
+		t.Errorf("history.raw_retention = %s, want %s", got, want)
+	}
+}
+
+func TestLoadErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		env     map[string]string
+		want    string
+	}{
+		{"unknown key", `{"check": {"duraton": "1m"}}`, nil, `unknown setting "check.duraton"`},
+		{"unknown section", `{"bogus": {}}`, nil, `unknown setting "bogus"`},
+		{"section not an object", `{"check": 5}`, nil, "check: want an object"},
+		{"bad duration", `{"check": {"duration": "soon"}}`, nil, "check.duration"},
+		{"bad env", `{}`, map[string]string{"check.interval": "often"}, "$" + EnvName("check.interval")},
+		{"bad json", `{`, nil, "unexpected end"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for key, value := range tt.env {
+				t.Setenv(EnvName(key), value)
+			}
+			_, err := Load(writeConfig(t, tt.content))
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Load() error = %v, want one containing %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadMissingFile(t *testing.T) {
+	// A file named explicitly must exist
+	if _, err := Load(filepath.Join(t.TempDir(), "missing.json")); err == nil {
+		t.Error("Load() of a missing file succeeded")
+	}
+}
+
+func TestSet(t *testing.T) {
+	c := Default()
+	if err := c.Set("monitor.views", " cpu, ,memory ", SourceFlag); err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Join(c.Monitor.Views, ","); got != "cpu,memory" {
+		t.Errorf("monitor.views = %q, want %q", got, "cpu,memory")
+	}
+	for _, tt := range []struct{ key, value string }{
+		{"monitor.nothing", "1"},
+		{"plain", "maybe"},
+		{"serve.max_clients", "many"},
+		{"thresholds.cpu_high", "hot"},
+	} {
+		if err := c.Set(tt.key, tt.value, SourceFlag); err == nil {
+			t.Errorf("Set(%q, %q) succeeded", tt.key, tt.value)
+		}
+	}
+}
+
+func TestRedacted(t *testing.T) {
+	c := Default()
+	c.Token = "secret"
+	if r := c.Redacted(); r.Token != "" {
+		t.Errorf("Redacted().Token = %q, want it cleared", r.Token)
+	}
+	if c.Token != "secret" {
+		t.Error("Redacted() changed the original")
+	}
+}
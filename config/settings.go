@@ -0,0 +1,167 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Setting is one setting's effective value, for display
+type Setting struct {
+	Key string
+	// Value is the text form, as an environment variable would give it;
+	// secrets show only whether they are set
+	Value  string
+	Source Source
+	// Env is the environment variable that overrides it
+	Env    string
+	Secret bool
+}
+
+// Settings lists every setting in file order
+func (c *Config) Settings() []Setting {
+	var out []Setting
+	for _, f := range c.list() {
+		s := Setting{
+			Key:    f.key,
+			Value:  formatValue(f.value),
+			Source: SourceDefault,
+			Env:    EnvName(f.key),
+			Secret: f.secret,
+		}
+		if src, ok := c.sources[f.key]; ok {
+			s.Source = src
+		}
+		if f.secret {
+			s.Value = "(not set)"
+			if !f.value.IsZero() {
+				s.Value = "(set)"
+			}
+		}
+		out = append(out, s)
+	}
+	return out
+}
+
+// Redacted returns a copy with secrets cleared, safe to print
+func (c *Config) Redacted() *Config {
+	r := *c
+	for _, f := range r.list() {
+		if f.secret {
+			f.value.SetZero()
+		}
+	}
+	return &r
+}
+
+// field is a setting's place in a Config
+type field struct {
+	key    string
+	value  reflect.Value
+	secret bool
+}
+
+// list walks the settings in declaration order, naming nested structs'
+// fields with dotted keys after their JSON names
+func (c *Config) list() []field {
+	var out []field
+	var walk func(prefix string, v reflect.Value)
+	walk = func(prefix string, v reflect.Value) {
+		t := v.Type()
+		for i := 0; i < t.NumField(); i++ {
+			sf := t.Field(i)
+			name, _, _ := strings.Cut(sf.Tag.Get("json"), ",")
+			if !sf.IsExported() || name == "" || name == "-" {
+				continue
+			}
+			fv := v.Field(i)
+			if fv.Kind() == reflect.Struct && fv.Type() != durationType {
+				walk(prefix+name+".", fv)
+				continue
+			}
+			out = append(out, field{key: prefix + name, value: fv, secret: sf.Tag.Get("config") == "secret"})
+		}
+	}
+	walk("", reflect.ValueOf(c).Elem())
+	return out
+}
+
+func (c *Config) fields() map[string]field {
+	fields := make(map[string]field)
+	for _, f := range c.list() {
+		fields[f.key] = f
+	}
+	return fields
+}
+
+// isSection reports whether key names a group of settings, e.g. "monitor"
+func isSection(fields map[string]field, key string) bool {
+	for k := range fields {
+		if strings.HasPrefix(k, key+".") {
+			return true
+		}
+	}
+	return false
+}
+
+var durationType = reflect.TypeOf(Duration{})
+
+// parseValue sets v from its text form
+func parseValue(v reflect.Value, s string) error {
+	s = strings.TrimSpace(s)
+	if v.Type() == durationType {
+		return v.Addr().Interface().(*Duration).UnmarshalText([]byte(s))
+	}
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(s)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return fmt.Errorf("invalid boolean %q", s)
+		}
+		v.SetBool(b)
+	case reflect.Int:
+		n, err := strconv.Atoi(s)
+		if err != nil {
+			return fmt.Errorf("invalid integer %q", s)
+		}
+		v.SetInt(int64(n))
+	case reflect.Float64:
+		f, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return fmt.Errorf("invalid number %q", s)
+		}
+		v.SetFloat(f)
+	case reflect.Slice:
+		var items []string
+		for _, item := range strings.Split(s, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		v.Set(reflect.ValueOf(items))
+	default:
+		return fmt.Errorf("unsupported setting type %s", v.Type())
+	}
+	return nil
+}
+
+// formatValue is the text form of a setting, as parseValue reads it
+func formatValue(v reflect.Value) string {
+	if v.Type() == durationType {
+		return v.Interface().(Duration).String()
+	}
+	switch v.Kind() {
+	case reflect.Float64:
+		return strconv.FormatFloat(v.Float(), 'g', -1, 64)
+	case reflect.Slice:
+		return strings.Join(v.Interface().([]string), ",")
+	}
+	return fmt.Sprint(v.Interface())
+}
+
+func envKey(key string) string {
+	return strings.ToUpper(strings.ReplaceAll(key, ".", "_"))
+}
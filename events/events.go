@@ -0,0 +1,375 @@
+// Package events keeps a log of recommendation transitions: when each
+// recommendation was raised, escalated, de-escalated and resolved, and the
+// peak of its evidence along the way. A condition that came and went while
+// nobody was watching still leaves a trace.
+//
+// The log is kept in memory and, when given a path, appended to a
+// line-delimited JSON file so it survives restarts.
+package events
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"math"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/xmarkclx/bottleneck-check/analysis"
+)
+
+// Defaults used when Options leaves a field zero
+const (
+	DefaultRetention = 30 * 24 * time.Hour
+	DefaultMax       = 1000
+)
+
+// compactInterval is how often a long-running log drops the events past
+// retention from its file
+const compactInterval = 24 * time.Hour
+
+// FileName is the log's name inside the history directory
+const FileName = "events.jsonl"
+
+// Event is one transition of one recommendation
+type Event struct {
+	Time      time.Time               `json:"time"`
+	Kind      analysis.TransitionKind `json:"kind"`
+	ID        string                  `json:"id"`
+	Rule      string                  `json:"rule"`
+	Subject   string                  `json:"subject,omitempty"`
+	Component string                  `json:"component"`
+	// Severity is the new severity, or the last one when resolved
+	Severity analysis.Severity `json:"severity"`
+	// Previous is the severity before an escalation or de-escalation
+	Previous analysis.Severity `json:"previous_severity,omitempty"`
+	Reason   string            `json:"reason"`
+	// Since is when the recommendation was raised
+	Since time.Time `json:"since"`
+	// PeakSeverity and Peak are the highest severity and evidence values
+	// seen since the recommendation was raised
+	PeakSeverity analysis.Severity  `json:"peak_severity"`
+	Peak         map[string]float64 `json:"peak,omitempty"`
+}
+
+// Options configures a Log
+type Options struct {
+	// Path is the file the log is appended to; empty keeps it in memory
+	Path string
+	// Retention is how long events are kept in the file
+	Retention time.Duration
+	// Max is how many of the latest events are kept in memory
+	Max int
+}
+
+// episode is what the log remembers about an active recommendation
+type episode struct {
+	since        time.Time
+	peakSeverity analysis.Severity
+	peak         map[string]float64
+}
+
+// Log records transitions between successive evaluations. It is safe for
+// concurrent use.
+type Log struct {
+	opts Options
+
+	mu       sync.Mutex
+	events   []Event // oldest first
+	prev     []analysis.Recommendation
+	episodes map[string]*episode
+	// compacted is when events past retention were last dropped from the file
+	compacted time.Time
+	err       error
+}
+
+// Open loads the events kept in opts.Path, dropping those past retention,
+// and returns a log that appends to it. A missing file is created on the
+// first event. The file is compacted again once a day while the log is
+// updated.
+func Open(opts Options) (*Log, error) {
+	if opts.Retention <= 0 {
+		opts.Retention = DefaultRetention
+	}
+	if opts.Max <= 0 {
+		opts.Max = DefaultMax
+	}
+	l := &Log{opts: opts, episodes: make(map[string]*episode)}
+	if opts.Path == "" {
+		return l, nil
+	}
+
+	l.compacted = time.Now()
+	kept, err := compact(opts.Path, l.compacted.Add(-opts.Retention))
+	if err != nil {
+		return nil, err
+	}
+	l.events = kept[max(len(kept)-opts.Max, 0):]
+	l.resume(kept)
+	return l, nil
+}
+
+// resume picks up the recommendations still active when the log was last
+// written, so the first Update continues their episodes, or resolves them
+// if they cleared while nothing was running, instead of raising them again
+func (l *Log) resume(logged []Event) {
+	open := make(map[string]Event)
+	var order []string
+	for _, e := range logged {
+		if e.Kind == analysis.TransitionResolved {
+			delete(open, e.ID)
+			continue
+		}
+		if _, ok := open[e.ID]; !ok {
+			order = append(order, e.ID)
+		}
+		open[e.ID] = e
+	}
+	for _, id := range order {
+		e, ok := open[id]
+		if !ok {
+			continue
+		}
+		delete(open, id) // an ID may be listed twice if it was raised again
+		l.prev = append(l.prev, analysis.Recommendation{
+			Rule:      e.Rule,
+			Subject:   e.Subject,
+			Component: e.Component,
+			Severity:  e.Severity,
+			Reason:    e.Reason,
+		})
+		l.episodes[id] = &episode{since: e.Since, peakSeverity: e.PeakSeverity, peak: copyPeak(e.Peak)}
+		if l.episodes[id].peak == nil {
+			l.episodes[id].peak = make(map[string]float64)
+		}
+	}
+}
+
+// Update compares recommendations with those of the previous Update,
+// records the transitions and returns them
+func (l *Log) Update(recs []analysis.Recommendation, t time.Time) []Event {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	var added []Event
+	for _, tr := range analysis.Transitions(l.prev, recs, t) {
+		rec := tr.Recommendation
+		ep := l.episodes[rec.ID()]
+		if ep == nil {
+			ep = &episode{since: t, peak: make(map[string]float64)}
+			l.episodes[rec.ID()] = ep
+		}
+		ep.observe(rec)
+		e := Event{
+			Time:         t,
+			Kind:         tr.Kind,
+			ID:           rec.ID(),
+			Rule:         rec.Rule,
+			Subject:      rec.Subject,
+			Component:    rec.Component,
+			Severity:     rec.Severity,
+			Previous:     tr.Previous,
+			Reason:       rec.Reason,
+			Since:        ep.since,
+			PeakSeverity: ep.peakSeverity,
+			Peak:         copyPeak(ep.peak),
+		}
+		if tr.Kind == analysis.TransitionResolved {
+			delete(l.episodes, rec.ID())
+		}
+		added = append(added, e)
+	}
+
+	// Keep peaks current between transitions
+	for _, rec := range recs {
+		if ep := l.episodes[rec.ID()]; ep != nil {
+			ep.observe(rec)
+		}
+	}
+	l.prev = recs
+
+	if len(added) > 0 {
+		l.events = append(l.events, added...)
+		if over := len(l.events) - l.opts.Max; over > 0 {
+			l.events = append([]Event(nil), l.events[over:]...)
+		}
+		if l.opts.Path != "" {
+			l.err = appendEvents(l.opts.Path, added)
+			if l.err == nil && t.Sub(l.compacted) >= compactInterval {
+				_, l.err = compact(l.opts.Path, t.Add(-l.opts.Retention))
+				l.compacted = t
+			}
+		}
+	}
+	return added
+}
+
+// Events returns the logged events, oldest first
+func (l *Log) Events() []Event {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return append([]Event(nil), l.events...)
+}
+
+// Path returns the file the log is kept in, empty if it is only in memory
+func (l *Log) Path() string {
+	return l.opts.Path
+}
+
+// Err returns the last error writing the log, if any
+func (l *Log) Err() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.err
+}
+
+func (ep *episode) observe(rec analysis.Recommendation) {
+	if rec.Severity.Rank() > ep.peakSeverity.Rank() {
+		ep.peakSeverity = rec.Severity
+	}
+	for name, v := range rec.Evidence {
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			continue
+		}
+		if old, ok := ep.peak[name]; !ok || v > old {
+			ep.peak[name] = v
+		}
+	}
+}
+
+func copyPeak(peak map[string]float64) map[string]float64 {
+	if len(peak) == 0 {
+		return nil
+	}
+	out := make(map[string]float64, len(peak))
+	for k, v := range peak {
+		out[k] = v
+	}
+	return out
+}
+
+// Worst splits from..to into n columns and returns the most severe
+// recommendation active during each, "" where none was
+func Worst(events []Event, from, to time.Time, n int) []analysis.Severity {
+	columns := make([]analysis.Severity, n)
+	if n <= 0 || !to.After(from) {
+		return columns
+	}
+	step := to.Sub(from) / time.Duration(n)
+	active := make(map[string]analysis.Severity)
+	worstActive := func() analysis.Severity {
+		var worst analysis.Severity
+		for _, s := range active {
+			if s.Rank() > worst.Rank() {
+				worst = s
+			}
+		}
+		return worst
+	}
+
+	// Start with what was active at from
+	i := 0
+	for ; i < len(events) && events[i].Time.Before(from); i++ {
+		if events[i].Kind == analysis.TransitionResolved {
+			delete(active, events[i].ID)
+		} else {
+			active[events[i].ID] = events[i].Severity
+		}
+	}
+	for col := range columns {
+		end := from.Add(step * time.Duration(col+1))
+		worst := worstActive()
+		for ; i < len(events) && events[i].Time.Before(end); i++ {
+			e := events[i]
+			if e.Kind == analysis.TransitionResolved {
+				delete(active, e.ID)
+			} else {
+				active[e.ID] = e.Severity
+			}
+			// A resolved recommendation was still active until it resolved
+			if e.Severity.Rank() > worst.Rank() {
+				worst = e.Severity
+			}
+		}
+		columns[col] = worst
+	}
+	return columns
+}
+
+// Path returns where the log is kept for a history directory
+func Path(historyDir string) string {
+	return filepath.Join(historyDir, FileName)
+}
+
+func readEvents(path string) ([]Event, error) {
+	f, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var all []Event
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var e Event
+		// Skip lines torn by a crash mid-write
+		if json.Unmarshal(scanner.Bytes(), &e) == nil {
+			all = append(all, e)
+		}
+	}
+	return all, scanner.Err()
+}
+
+// compact drops the events before cutoff from the file and returns the rest
+func compact(path string, cutoff time.Time) ([]Event, error) {
+	all, err := readEvents(path)
+	if err != nil {
+		return nil, err
+	}
+	kept := all[:0]
+	for _, e := range all {
+		if !e.Time.Before(cutoff) {
+			kept = append(kept, e)
+		}
+	}
+	if len(kept) < len(all) {
+		if err := writeEvents(path, kept); err != nil {
+			return nil, err
+		}
+	}
+	return kept, nil
+}
+
+func appendEvents(path string, events []Event) error {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return err
+	}
+	enc := json.NewEncoder(f)
+	for _, e := range events {
+		if err := enc.Encode(e); err != nil {
+			f.Close()
+			return err
+		}
+	}
+	return f.Close()
+}
+
+// writeEvents replaces the file atomically
+func writeEvents(path string, events []Event) error {
+	tmp := path + ".tmp"
+	if err := os.Remove(tmp); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	if err := appendEvents(tmp, events); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
@@ -0,0 +1,118 @@
+package events
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/xmarkclx/bottleneck-check/analysis"
+)
+
+func rec(rule string, severity analysis.Severity, usage float64) analysis.Recommendation {
+	return analysis.Recommendation{
+		Rule:      rule,
+		Component: "CPU",
+		Severity:  severity,
+		Evidence:  map[string]float64{"usage": usage},
+	}
+}
+
+func TestResume(t *testing.T) {
+	path := Path(t.TempDir())
+	base := time.Now().UTC().Add(-time.Hour).Truncate(time.Second)
+	at := func(m int) time.Time { return base.Add(time.Duration(m) * time.Minute) }
+
+	l, err := Open(Options{Path: path})
+	if err != nil {
+		t.Fatal(err)
+	}
+	l.Update([]analysis.Recommendation{rec("cpu.usage", analysis.SeverityHigh, 95)}, at(0))
+	l.Update([]analysis.Recommendation{rec("cpu.usage", analysis.SeverityHigh, 97), rec("cpu.load", analysis.SeverityMedium, 4)}, at(1))
+	l.Update([]analysis.Recommendation{rec("cpu.load", analysis.SeverityMedium, 6)}, at(2))
+	// cpu.usage is raised again, so its episode starts over
+	l.Update([]analysis.Recommendation{rec("cpu.usage", analysis.SeverityMedium, 85), rec("cpu.load", analysis.SeverityMedium, 5)}, at(3))
+	if err := l.Err(); err != nil {
+		t.Fatal(err)
+	}
+
+	// After a restart, the open episodes continue instead of being raised
+	// again: cpu.usage escalates and cpu.load, which cleared while nothing
+	// was running, resolves
+	l, err = Open(Options{Path: path})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := len(l.Events()); got != 4 {
+		t.Fatalf("loaded %d events, want 4", got)
+	}
+	got := l.Update([]analysis.Recommendation{rec("cpu.usage", analysis.SeverityCritical, 99)}, at(4))
+	want := []Event{
+		{
+			Time: at(4), Kind: analysis.TransitionEscalated, ID: "cpu.usage", Rule: "cpu.usage", Component: "CPU",
+			Severity: analysis.SeverityCritical, Previous: analysis.SeverityMedium,
+			Since: at(3), PeakSeverity: analysis.SeverityCritical, Peak: map[string]float64{"usage": 99},
+		},
+		{
+			Time: at(4), Kind: analysis.TransitionResolved, ID: "cpu.load", Rule: "cpu.load", Component: "CPU",
+			Severity: analysis.SeverityMedium,
+			Since:    at(1), PeakSeverity: analysis.SeverityMedium, Peak: map[string]float64{"usage": 4},
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Update() after restart =\n%+v\nwant\n%+v", got, want)
+	}
+}
+
+func TestCompaction(t *testing.T) {
+	path := Path(t.TempDir())
+	now := time.Now().Truncate(time.Second)
+
+	l, err := Open(Options{Path: path, Retention: time.Hour})
+	if err != nil {
+		t.Fatal(err)
+	}
+	l.Update([]analysis.Recommendation{rec("cpu.usage", analysis.SeverityHigh, 95)}, now)
+	l.Update(nil, now.Add(time.Minute))
+	// A day later, the next event compacts the file
+	l.Update([]analysis.Recommendation{rec("cpu.load", analysis.SeverityMedium, 4)}, now.Add(25*time.Hour))
+	if err := l.Err(); err != nil {
+		t.Fatal(err)
+	}
+	kept, err := readEvents(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(kept) != 1 || kept[0].ID != "cpu.load" {
+		t.Errorf("file holds %+v, want only the cpu.load event", kept)
+	}
+}
+
+func TestWorst(t *testing.T) {
+	from := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+	at := func(m int) time.Time { return from.Add(time.Duration(m) * time.Minute) }
+	event := func(m int, kind analysis.TransitionKind, id string, severity analysis.Severity) Event {
+		return Event{Time: at(m), Kind: kind, ID: id, Severity: severity}
+	}
+	events := []Event{
+		// Active since before the range
+		event(-5, analysis.TransitionRaised, "memory.swap", analysis.SeverityLow),
+		// Raised and resolved inside the second column
+		event(17, analysis.TransitionRaised, "cpu.usage", analysis.SeverityCritical),
+		event(20, analysis.TransitionResolved, "cpu.usage", analysis.SeverityCritical),
+		// Escalated and resolved inside the fourth column
+		event(50, analysis.TransitionEscalated, "memory.swap", analysis.SeverityHigh),
+		event(55, analysis.TransitionResolved, "memory.swap", analysis.SeverityHigh),
+	}
+	// Five 15-minute columns
+	got := Worst(events, from, at(75), 5)
+	want := []analysis.Severity{
+		analysis.SeverityLow,
+		analysis.SeverityCritical,
+		analysis.SeverityLow,
+		analysis.SeverityHigh,
+		"",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Worst() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,76 @@
+package history
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// MaxAnnotationLength is the longest annotation text accepted, in runes
+const MaxAnnotationLength = 200
+
+// ErrInvalidAnnotation is returned by Annotate for empty or overlong text
+var ErrInvalidAnnotation = errors.New("invalid annotation")
+
+// annotationsFile holds every annotation, one JSON object per line
+const annotationsFile = "annotations.jsonl"
+
+// Annotation is a note attached to a moment, such as "deployed v2.3", to
+// correlate with the metrics around it
+type Annotation struct {
+	Time time.Time `json:"t"`
+	Text string    `json:"text"`
+	// Source is where the annotation was made: "monitor", "cli" or "api"
+	Source string `json:"source,omitempty"`
+}
+
+// Annotate records an annotation; a zero Time means now. It appends a
+// single line, so it also works on a read-only store while another process
+// is recording.
+func (s *Store) Annotate(a Annotation) (Annotation, error) {
+	a.Text = strings.Join(strings.Fields(a.Text), " ")
+	if a.Text == "" {
+		return a, fmt.Errorf("%w: the text is empty", ErrInvalidAnnotation)
+	}
+	if len([]rune(a.Text)) > MaxAnnotationLength {
+		return a, fmt.Errorf("%w: the text is longer than %d characters", ErrInvalidAnnotation, MaxAnnotationLength)
+	}
+	if a.Time.IsZero() {
+		a.Time = time.Now()
+	}
+	a.Time = a.Time.UTC()
+
+	line, err := json.Marshal(a)
+	if err != nil {
+		return a, err
+	}
+	f, err := os.OpenFile(filepath.Join(s.opts.Dir, annotationsFile), os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return a, err
+	}
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		f.Close()
+		return a, err
+	}
+	return a, f.Close()
+}
+
+// Annotations returns the annotations made between from and to, oldest
+// first
+func (s *Store) Annotations(from, to time.Time) ([]Annotation, error) {
+	var annotations []Annotation
+	err := readLines(filepath.Join(s.opts.Dir, annotationsFile), func(line []byte) {
+		var a Annotation
+		if json.Unmarshal(line, &a) == nil && !a.Time.Before(from) && !a.Time.After(to) {
+			annotations = append(annotations, a)
+		}
+	})
+	// Annotations made with an explicit time may be out of order
+	sort.SliceStable(annotations, func(i, j int) bool { return annotations[i].Time.Before(annotations[j].Time) })
+	return annotations, err
+}
@@ -0,0 +1,23 @@
+//go:build !windows
+
+package history
+
+import (
+	"errors"
+	"os"
+	"syscall"
+)
+
+// errWouldBlock is returned by lockFile when another process holds the lock
+var errWouldBlock = syscall.EWOULDBLOCK
+
+// lockFile takes an exclusive lock on f without waiting. The lock is
+// released when f is closed, also when the process dies.
+func lockFile(f *os.File) error {
+	for {
+		err := syscall.Flock(int(f.Fd()), syscall.LOCK_EX|syscall.LOCK_NB)
+		if !errors.Is(err, syscall.EINTR) {
+			return err
+		}
+	}
+}
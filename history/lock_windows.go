@@ -0,0 +1,19 @@
+//go:build windows
+
+package history
+
+import (
+	"os"
+
+	"golang.org/x/sys/windows"
+)
+
+// errWouldBlock is returned by lockFile when another process holds the lock
+var errWouldBlock = windows.ERROR_LOCK_VIOLATION
+
+// lockFile takes an exclusive lock on f without waiting. The lock is
+// released when f is closed, also when the process dies.
+func lockFile(f *os.File) error {
+	var ol windows.Overlapped
+	return windows.LockFileEx(windows.Handle(f.Fd()), windows.LOCKFILE_EXCLUSIVE_LOCK|windows.LOCKFILE_FAIL_IMMEDIATELY, 0, 1, 0, &ol)
+}
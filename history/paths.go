@@ -0,0 +1,76 @@
+package history
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DataDir returns the per-user directory for bottleneck-check data:
+// $XDG_DATA_HOME/bottleneck-check when set, otherwise the platform's usual
+// application data location.
+func DataDir() string {
+	if dir := os.Getenv("XDG_DATA_HOME"); dir != "" {
+		return filepath.Join(dir, "bottleneck-check")
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return filepath.Join(os.TempDir(), "bottleneck-check")
+	}
+	switch runtime.GOOS {
+	case "windows":
+		if dir := os.Getenv("LocalAppData"); dir != "" {
+			return filepath.Join(dir, "bottleneck-check")
+		}
+		return filepath.Join(home, "AppData", "Local", "bottleneck-check")
+	case "darwin":
+		return filepath.Join(home, "Library", "Application Support", "bottleneck-check")
+	}
+	return filepath.Join(home, ".local", "share", "bottleneck-check")
+}
+
+// DefaultDir returns the default store directory
+func DefaultDir() string {
+	return filepath.Join(DataDir(), "history")
+}
+
+// ParseTime accepts "now", a duration ago such as "90m", "2h" or "7d" (a
+// leading "-" is optional), an RFC 3339 timestamp, or a local date/time as
+// "2006-01-02", "2006-01-02 15:04" or "2006-01-02T15:04".
+func ParseTime(s string, now time.Time) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	if s == "" || s == "now" {
+		return now, nil
+	}
+	if d, err := ParseDuration(strings.TrimPrefix(s, "-")); err == nil {
+		return now.Add(-d), nil
+	}
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	for _, layout := range []string{"2006-01-02 15:04", "2006-01-02T15:04", "2006-01-02"} {
+		if t, err := time.ParseInLocation(layout, s, time.Local); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid time %q", s)
+}
+
+// ParseDuration is time.ParseDuration plus "d" (days) and "w" (weeks) units
+// as a whole-number suffix, e.g. "7d"
+func ParseDuration(s string) (time.Duration, error) {
+	for suffix, unit := range map[string]time.Duration{"d": 24 * time.Hour, "w": 7 * 24 * time.Hour} {
+		if n, ok := strings.CutSuffix(s, suffix); ok {
+			count, err := strconv.Atoi(n)
+			if err != nil {
+				return 0, fmt.Errorf("invalid duration %q", s)
+			}
+			return time.Duration(count) * unit, nil
+		}
+	}
+	return time.ParseDuration(s)
+}
@@ -0,0 +1,57 @@
+package history
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseDuration(t *testing.T) {
+	tests := []struct {
+		in   string
+		want time.Duration
+		ok   bool
+	}{
+		{"90m", 90 * time.Minute, true},
+		{"1h30m", 90 * time.Minute, true},
+		{"7d", 7 * 24 * time.Hour, true},
+		{"2w", 14 * 24 * time.Hour, true},
+		{"0d", 0, true},
+		{"1.5d", 0, false},
+		{"d", 0, false},
+		{"soon", 0, false},
+		{"", 0, false},
+	}
+	for _, tt := range tests {
+		got, err := ParseDuration(tt.in)
+		if (err == nil) != tt.ok || got != tt.want {
+			t.Errorf("ParseDuration(%q) = %s, %v; want %s, ok %v", tt.in, got, err, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestParseTime(t *testing.T) {
+	now := time.Date(2026, 10, 18, 12, 30, 0, 0, time.Local)
+	tests := []struct {
+		in   string
+		want time.Time
+		ok   bool
+	}{
+		{"", now, true},
+		{"now", now, true},
+		{"90m", now.Add(-90 * time.Minute), true},
+		{"-2h", now.Add(-2 * time.Hour), true},
+		{" 7d ", now.AddDate(0, 0, -7), true},
+		{"2026-10-17T08:00:00Z", time.Date(2026, 10, 17, 8, 0, 0, 0, time.UTC), true},
+		{"2026-10-17 08:15", time.Date(2026, 10, 17, 8, 15, 0, 0, time.Local), true},
+		{"2026-10-17T08:15", time.Date(2026, 10, 17, 8, 15, 0, 0, time.Local), true},
+		{"2026-10-17", time.Date(2026, 10, 17, 0, 0, 0, 0, time.Local), true},
+		{"yesterday", time.Time{}, false},
+		{"2026-13-01", time.Time{}, false},
+	}
+	for _, tt := range tests {
+		got, err := ParseTime(tt.in, now)
+		if (err == nil) != tt.ok || !got.Equal(tt.want) {
+			t.Errorf("ParseTime(%q) = %s, %v; want %s, ok %v", tt.in, got, err, tt.want, tt.ok)
+		}
+	}
+}
@@ -0,0 +1,238 @@
+package history
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/xmarkclx/bottleneck-check/metrics"
+)
+
+// Resolution selects which records a query reads
+type Resolution string
+
+const (
+	ResolutionAuto   Resolution = "auto"
+	ResolutionRaw    Resolution = "raw"
+	ResolutionMinute Resolution = "1m"
+	ResolutionHour   Resolution = "1h"
+)
+
+// ParseResolution validates a resolution name
+func ParseResolution(s string) (Resolution, error) {
+	switch r := Resolution(s); r {
+	case ResolutionAuto, ResolutionRaw, ResolutionMinute, ResolutionHour:
+		return r, nil
+	}
+	return "", fmt.Errorf("unknown resolution %q (want auto, raw, 1m or 1h)", s)
+}
+
+// Point is one value of a metric over time. Raw points have Min, Avg, Max
+// and P95 all equal to the sample value and a Count of 1.
+type Point struct {
+	Time  time.Time
+	Min   float64
+	Avg   float64
+	Max   float64
+	P95   float64
+	Count int
+}
+
+// Query returns the points of a metric with from <= Time < to, oldest first
+func (s *Store) Query(metric string, from, to time.Time, res Resolution) ([]Point, Resolution, error) {
+	if res == ResolutionAuto || res == "" {
+		res = s.autoResolution(from, to, time.Now())
+	}
+	from, to = from.UTC(), to.UTC()
+
+	s.mu.Lock()
+	sealedThrough := s.state.SealedThrough
+	s.mu.Unlock()
+
+	var points []Point
+	var err error
+	switch res {
+	case ResolutionRaw:
+		points, err = s.queryRaw(metric, from, to)
+	case ResolutionMinute:
+		points, err = s.queryRollups(metric, from, to, minuteDir, minuteLayout, time.Minute, sealedThrough)
+	case ResolutionHour:
+		points, err = s.queryRollups(metric, from, to, hourDir, hourLayout, time.Hour, sealedThrough)
+	default:
+		return nil, res, fmt.Errorf("unknown resolution %q", res)
+	}
+	return points, res, err
+}
+
+// autoResolution picks the finest resolution that is still retained for
+// the start of the range and keeps the point count readable
+func (s *Store) autoResolution(from, to, now time.Time) Resolution {
+	span := to.Sub(from)
+	switch {
+	case span <= time.Hour && !from.Before(now.Add(-s.opts.RawRetention)):
+		return ResolutionRaw
+	case span <= 48*time.Hour && !from.Before(now.Add(-s.opts.MinuteRetention)):
+		return ResolutionMinute
+	}
+	return ResolutionHour
+}
+
+// FinestResolution returns the most detailed resolution still retained for
+// data starting at from
+func (s *Store) FinestResolution(from time.Time) Resolution {
+	now := time.Now()
+	switch {
+	case !from.Before(now.Add(-s.opts.RawRetention)):
+		return ResolutionRaw
+	case !from.Before(now.Add(-s.opts.MinuteRetention)):
+		return ResolutionMinute
+	}
+	return ResolutionHour
+}
+
+// Samples returns the raw samples with from <= Time < to, oldest first
+func (s *Store) Samples(from, to time.Time) ([]Sample, error) {
+	from, to = from.UTC(), to.UTC()
+	hours, err := s.segments(rawDir, rawLayout)
+	if err != nil {
+		return nil, err
+	}
+
+	var samples []Sample
+	for _, hour := range hours {
+		if !overlaps(hour, hour.Add(time.Hour), from, to) {
+			continue
+		}
+		segment, err := readSamples(s.segmentPath(rawDir, rawLayout, hour))
+		if err != nil {
+			return nil, err
+		}
+		for _, sample := range segment {
+			if !sample.Time.Before(from) && sample.Time.Before(to) {
+				samples = append(samples, sample)
+			}
+		}
+	}
+	return samples, nil
+}
+
+// Snapshots rebuilds snapshots with from <= Timestamp < to, oldest first, so
+// recorded history can be replayed through analysis. At 1m or 1h resolution
+// each snapshot holds the bucket averages of the named metrics, or of all
+// of them when none are named.
+func (s *Store) Snapshots(from, to time.Time, res Resolution, names ...string) ([]metrics.SystemMetrics, Resolution, error) {
+	if res == ResolutionAuto || res == "" {
+		res = s.FinestResolution(from)
+	}
+	if res == ResolutionRaw {
+		samples, err := s.Samples(from, to)
+		if err != nil {
+			return nil, res, err
+		}
+		snapshots := make([]metrics.SystemMetrics, len(samples))
+		for i, sample := range samples {
+			snapshots[i] = metrics.FromValues(sample.Time.Local(), sample.Values)
+		}
+		return snapshots, res, nil
+	}
+
+	if len(names) == 0 {
+		for _, series := range metrics.AllSeries {
+			names = append(names, series.Name)
+		}
+	}
+	byTime := make(map[time.Time]map[string]float64)
+	for _, name := range names {
+		points, _, err := s.Query(name, from, to, res)
+		if err != nil {
+			return nil, res, err
+		}
+		for _, p := range points {
+			values := byTime[p.Time]
+			if values == nil {
+				values = make(map[string]float64)
+				byTime[p.Time] = values
+			}
+			values[name] = p.Avg
+		}
+	}
+
+	snapshots := make([]metrics.SystemMetrics, 0, len(byTime))
+	for t, values := range byTime {
+		snapshots = append(snapshots, metrics.FromValues(t.Local(), values))
+	}
+	sort.Slice(snapshots, func(i, j int) bool { return snapshots[i].Timestamp.Before(snapshots[j].Timestamp) })
+	return snapshots, res, nil
+}
+
+func (s *Store) queryRaw(metric string, from, to time.Time) ([]Point, error) {
+	samples, err := s.Samples(from, to)
+	if err != nil {
+		return nil, err
+	}
+	var points []Point
+	for _, sample := range samples {
+		if v, ok := sample.Values[metric]; ok {
+			points = append(points, Point{Time: sample.Time, Min: v, Avg: v, Max: v, P95: v, Count: 1})
+		}
+	}
+	return points, nil
+}
+
+// queryRollups reads stored rollups for sealed hours and rolls up the raw
+// samples of hours that aren't sealed yet
+func (s *Store) queryRollups(metric string, from, to time.Time, dir, layout string, bucket time.Duration, sealedThrough time.Time) ([]Point, error) {
+	byTime := make(map[time.Time]Point)
+	add := func(rollups []Rollup) {
+		for _, r := range rollups {
+			agg, ok := r.Series[metric]
+			if !ok || r.Time.Before(from.Truncate(bucket)) || !r.Time.Before(to) {
+				continue
+			}
+			// A later record for the same bucket replaces an earlier one
+			byTime[r.Time] = Point{Time: r.Time, Min: agg.Min, Avg: agg.Avg, Max: agg.Max, P95: agg.P95, Count: agg.Count}
+		}
+	}
+
+	starts, err := s.segments(dir, layout)
+	if err != nil {
+		return nil, err
+	}
+	for _, start := range starts {
+		end := start.AddDate(0, 0, 1)
+		if dir == hourDir {
+			end = start.AddDate(0, 1, 0)
+		}
+		if !overlaps(start, end, from, to) {
+			continue
+		}
+		rollups, err := readRollups(s.segmentPath(dir, layout, start))
+		if err != nil {
+			return nil, err
+		}
+		add(rollups)
+	}
+
+	if to.After(sealedThrough) {
+		pendingFrom := from
+		if pendingFrom.Before(sealedThrough) {
+			pendingFrom = sealedThrough
+		}
+		samples, err := s.Samples(pendingFrom.Truncate(bucket), to)
+		if err != nil {
+			return nil, err
+		}
+		add(rollup(samples, bucket))
+	}
+
+	points := make([]Point, 0, len(byTime))
+	for _, p := range byTime {
+		points = append(points, p)
+	}
+	sort.Slice(points, func(i, j int) bool { return points[i].Time.Before(points[j].Time) })
+	return points, nil
+}
+
+func overlaps(start, end, from, to time.Time) bool {
+	return start.Before(to) && end.After(from)
+}
@@ -0,0 +1,484 @@
+// Package history persists snapshots to a local append-only store and
+// answers range queries over them.
+//
+// The store is a directory of line-delimited JSON segments:
+//
+//	raw/2026101814.jsonl  every sample, one file per hour (UTC)
+//	1m/20261018.jsonl     per-minute min/avg/max/p95, one file per day
+//	1h/202610.jsonl       per-hour min/avg/max/p95, one file per month
+//	state.json            which hours have been rolled up
+//	annotations.jsonl     notes attached to moments, kept until deleted
+//	LOCK                  locked by the recording process, with its pid
+//
+// When a raw hour is complete it is "sealed": its 1m and 1h rollups are
+// appended to their segments. Each resolution has its own retention, after
+// which whole segments are deleted.
+package history
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/xmarkclx/bottleneck-check/internal/stats"
+	"github.com/xmarkclx/bottleneck-check/metrics"
+)
+
+// Defaults used when Options leaves a field zero
+const (
+	DefaultRawRetention    = 48 * time.Hour
+	DefaultMinuteRetention = 30 * 24 * time.Hour
+	DefaultHourRetention   = 400 * 24 * time.Hour
+)
+
+// ErrLocked is returned by Open when another live process is recording
+var ErrLocked = errors.New("history store is in use by another process")
+
+// Options configures a Store
+type Options struct {
+	// Dir is the store directory; empty means DefaultDir()
+	Dir string
+	// Retention per resolution
+	RawRetention    time.Duration
+	MinuteRetention time.Duration
+	HourRetention   time.Duration
+}
+
+// Sample is one raw record
+type Sample struct {
+	Time   time.Time          `json:"t"`
+	Values map[string]float64 `json:"v"`
+}
+
+// Aggregate summarizes one metric over a rollup bucket
+type Aggregate struct {
+	Min   float64 `json:"min"`
+	Avg   float64 `json:"avg"`
+	Max   float64 `json:"max"`
+	P95   float64 `json:"p95"`
+	Count int     `json:"n"`
+}
+
+// Rollup is one downsampled record covering a minute or an hour
+type Rollup struct {
+	Time   time.Time            `json:"t"` // bucket start
+	Series map[string]Aggregate `json:"s"`
+}
+
+type storeState struct {
+	// SealedThrough is the end of the last rolled-up hour
+	SealedThrough time.Time `json:"sealed_through"`
+}
+
+// Store is an on-disk metrics history. It is safe for concurrent use.
+type Store struct {
+	opts     Options
+	readOnly bool
+
+	mu      sync.Mutex
+	state   storeState
+	raw     *os.File
+	rawHour time.Time
+	lastErr error
+	// lockFile holds the store lock while recording
+	lockFile *os.File
+}
+
+const (
+	rawDir    = "raw"
+	minuteDir = "1m"
+	hourDir   = "1h"
+
+	rawLayout    = "2006010215"
+	minuteLayout = "20060102"
+	hourLayout   = "200601"
+)
+
+// Open opens the store for recording. It takes the store lock, rolls up
+// any complete hours left from earlier runs and applies retention.
+func Open(opts Options) (*Store, error) {
+	s := newStore(opts)
+	for _, dir := range []string{rawDir, minuteDir, hourDir} {
+		if err := os.MkdirAll(filepath.Join(s.opts.Dir, dir), 0o755); err != nil {
+			return nil, err
+		}
+	}
+	if err := s.lock(); err != nil {
+		return nil, err
+	}
+	if err := s.loadState(); err != nil {
+		s.unlock()
+		return nil, err
+	}
+	if err := s.sealBefore(time.Now().UTC().Truncate(time.Hour)); err != nil {
+		s.unlock()
+		return nil, err
+	}
+	s.applyRetention(time.Now())
+	return s, nil
+}
+
+// OpenReadOnly opens the store for queries only. It works while another
+// process is recording.
+func OpenReadOnly(opts Options) (*Store, error) {
+	s := newStore(opts)
+	s.readOnly = true
+	if _, err := os.Stat(s.opts.Dir); err != nil {
+		return nil, err
+	}
+	if err := s.loadState(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func newStore(opts Options) *Store {
+	if opts.Dir == "" {
+		opts.Dir = DefaultDir()
+	}
+	if opts.RawRetention <= 0 {
+		opts.RawRetention = DefaultRawRetention
+	}
+	if opts.MinuteRetention <= 0 {
+		opts.MinuteRetention = DefaultMinuteRetention
+	}
+	if opts.HourRetention <= 0 {
+		opts.HourRetention = DefaultHourRetention
+	}
+	return &Store{opts: opts}
+}
+
+// Dir returns the store directory
+func (s *Store) Dir() string {
+	return s.opts.Dir
+}
+
+// Err returns the last error seen while recording, if any
+func (s *Store) Err() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.lastErr
+}
+
+// Append writes a snapshot as a raw sample. Crossing into a new hour seals
+// the previous one.
+func (s *Store) Append(m *metrics.SystemMetrics) error {
+	if s.readOnly {
+		return errors.New("history store is read-only")
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	err := s.appendLocked(Sample{Time: m.Timestamp.UTC(), Values: m.Values()})
+	s.lastErr = err
+	return err
+}
+
+func (s *Store) appendLocked(sample Sample) error {
+	hour := sample.Time.Truncate(time.Hour)
+	if s.raw == nil || !hour.Equal(s.rawHour) {
+		if s.raw != nil {
+			s.raw.Close()
+			s.raw = nil
+		}
+		if hour.After(s.rawHour) && !s.rawHour.IsZero() {
+			if err := s.sealBefore(hour); err != nil {
+				return err
+			}
+			s.applyRetention(sample.Time)
+		}
+		f, err := os.OpenFile(s.segmentPath(rawDir, rawLayout, hour), os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+		if err != nil {
+			return err
+		}
+		s.raw = f
+		s.rawHour = hour
+	}
+
+	line, err := json.Marshal(sample)
+	if err != nil {
+		return err
+	}
+	_, err = s.raw.Write(append(line, '\n'))
+	return err
+}
+
+// Record appends every snapshot received until the channel closes. Append
+// errors don't stop recording; the latest one is available from Err.
+func (s *Store) Record(snapshots <-chan metrics.SystemMetrics) {
+	for m := range snapshots {
+		s.Append(&m)
+	}
+}
+
+// Close flushes and releases the store
+func (s *Store) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var err error
+	if s.raw != nil {
+		err = s.raw.Close()
+		s.raw = nil
+	}
+	if !s.readOnly {
+		s.unlock()
+	}
+	return err
+}
+
+func (s *Store) segmentPath(dir, layout string, t time.Time) string {
+	return filepath.Join(s.opts.Dir, dir, t.UTC().Format(layout)+".jsonl")
+}
+
+// sealBefore rolls up every raw hour before end that isn't sealed yet
+func (s *Store) sealBefore(end time.Time) error {
+	hours, err := s.segments(rawDir, rawLayout)
+	if err != nil {
+		return err
+	}
+	for _, hour := range hours {
+		if hour.Before(s.state.SealedThrough) || !hour.Before(end) {
+			continue
+		}
+		if err := s.seal(hour); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// seal appends the 1m and 1h rollups of one raw hour and records it
+func (s *Store) seal(hour time.Time) error {
+	samples, err := readSamples(s.segmentPath(rawDir, rawLayout, hour))
+	if err != nil {
+		return err
+	}
+	if len(samples) > 0 {
+		if err := appendRollups(s.segmentPath(minuteDir, minuteLayout, hour), rollup(samples, time.Minute)); err != nil {
+			return err
+		}
+		if err := appendRollups(s.segmentPath(hourDir, hourLayout, hour), rollup(samples, time.Hour)); err != nil {
+			return err
+		}
+	}
+	s.state.SealedThrough = hour.Add(time.Hour)
+	return s.saveState()
+}
+
+// applyRetention deletes segments that ended before their retention window.
+// Raw hours are only deleted once sealed.
+func (s *Store) applyRetention(now time.Time) {
+	s.prune(rawDir, rawLayout, func(t time.Time) time.Time { return t.Add(time.Hour) },
+		minTime(now.Add(-s.opts.RawRetention), s.state.SealedThrough))
+	s.prune(minuteDir, minuteLayout, func(t time.Time) time.Time { return t.AddDate(0, 0, 1) },
+		now.Add(-s.opts.MinuteRetention))
+	s.prune(hourDir, hourLayout, func(t time.Time) time.Time { return t.AddDate(0, 1, 0) },
+		now.Add(-s.opts.HourRetention))
+}
+
+func (s *Store) prune(dir, layout string, end func(time.Time) time.Time, cutoff time.Time) {
+	starts, err := s.segments(dir, layout)
+	if err != nil {
+		return
+	}
+	for _, start := range starts {
+		if end(start).Before(cutoff) {
+			os.Remove(s.segmentPath(dir, layout, start))
+		}
+	}
+}
+
+// segments lists the start times of the segments in dir, oldest first
+func (s *Store) segments(dir, layout string) ([]time.Time, error) {
+	entries, err := os.ReadDir(filepath.Join(s.opts.Dir, dir))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var starts []time.Time
+	for _, entry := range entries {
+		name, ok := strings.CutSuffix(entry.Name(), ".jsonl")
+		if !ok {
+			continue
+		}
+		t, err := time.ParseInLocation(layout, name, time.UTC)
+		if err != nil {
+			continue
+		}
+		starts = append(starts, t)
+	}
+	sort.Slice(starts, func(i, j int) bool { return starts[i].Before(starts[j]) })
+	return starts, nil
+}
+
+func (s *Store) loadState() error {
+	data, err := os.ReadFile(filepath.Join(s.opts.Dir, "state.json"))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, &s.state)
+}
+
+func (s *Store) saveState() error {
+	data, err := json.Marshal(s.state)
+	if err != nil {
+		return err
+	}
+	path := filepath.Join(s.opts.Dir, "state.json")
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// lock takes the store lock, an OS lock on the LOCK file that is released
+// when the recording process exits however it exits, and writes our pid
+// into the file for the error other processes report
+func (s *Store) lock() error {
+	path := filepath.Join(s.opts.Dir, "LOCK")
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR, 0o644)
+	if err != nil {
+		return err
+	}
+	if err := lockFile(f); err != nil {
+		f.Close()
+		if !errors.Is(err, errWouldBlock) {
+			return fmt.Errorf("locking %s: %w", path, err)
+		}
+		if data, err := os.ReadFile(path); err == nil && len(bytes.TrimSpace(data)) > 0 {
+			return fmt.Errorf("%w (pid %s)", ErrLocked, bytes.TrimSpace(data))
+		}
+		return ErrLocked
+	}
+	if err := f.Truncate(0); err == nil {
+		f.WriteAt([]byte(strconv.Itoa(os.Getpid())), 0)
+	}
+	s.lockFile = f
+	return nil
+}
+
+// unlock releases the store lock. The file stays: removing it could let
+// one process lock the old file while another creates and locks a new one.
+func (s *Store) unlock() {
+	if s.lockFile == nil {
+		return
+	}
+	s.lockFile.Truncate(0)
+	s.lockFile.Close()
+	s.lockFile = nil
+}
+
+func readSamples(path string) ([]Sample, error) {
+	var samples []Sample
+	err := readLines(path, func(line []byte) {
+		var sample Sample
+		if json.Unmarshal(line, &sample) == nil {
+			samples = append(samples, sample)
+		}
+	})
+	return samples, err
+}
+
+func readRollups(path string) ([]Rollup, error) {
+	var rollups []Rollup
+	err := readLines(path, func(line []byte) {
+		var r Rollup
+		if json.Unmarshal(line, &r) == nil {
+			rollups = append(rollups, r)
+		}
+	})
+	return rollups, err
+}
+
+func readLines(path string, fn func([]byte)) error {
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		fn(scanner.Bytes())
+	}
+	return scanner.Err()
+}
+
+func appendRollups(path string, rollups []Rollup) error {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	w := bufio.NewWriter(f)
+	enc := json.NewEncoder(w)
+	for _, r := range rollups {
+		if err := enc.Encode(r); err != nil {
+			return err
+		}
+	}
+	return w.Flush()
+}
+
+// rollup groups samples into buckets of the given size
+func rollup(samples []Sample, bucket time.Duration) []Rollup {
+	values := make(map[time.Time]map[string][]float64)
+	var starts []time.Time
+	for _, sample := range samples {
+		start := sample.Time.Truncate(bucket)
+		series, ok := values[start]
+		if !ok {
+			series = make(map[string][]float64)
+			values[start] = series
+			starts = append(starts, start)
+		}
+		for name, v := range sample.Values {
+			series[name] = append(series[name], v)
+		}
+	}
+	sort.Slice(starts, func(i, j int) bool { return starts[i].Before(starts[j]) })
+
+	rollups := make([]Rollup, 0, len(starts))
+	for _, start := range starts {
+		r := Rollup{Time: start, Series: make(map[string]Aggregate)}
+		for name, vs := range values[start] {
+			summary := stats.Summarize(vs)
+			r.Series[name] = Aggregate{
+				Min:   summary.Min,
+				Avg:   summary.Avg,
+				Max:   summary.Max,
+				P95:   summary.P95,
+				Count: summary.Count,
+			}
+		}
+		rollups = append(rollups, r)
+	}
+	return rollups
+}
+
+func minTime(a, b time.Time) time.Time {
+	if a.Before(b) {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,162 @@
+package history
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/xmarkclx/bottleneck-check/metrics"
+)
+
+// fill records a sample every 10 seconds for two hours from start, with CPU
+// usage equal to the minute of the hour
+func fill(t *testing.T, s *Store, start time.Time) {
+	t.Helper()
+	for at := start; at.Before(start.Add(2 * time.Hour)); at = at.Add(10 * time.Second) {
+		m := metrics.SystemMetrics{Timestamp: at, CPUCores: 4, CPUUsage: float64(at.Minute())}
+		if err := s.Append(&m); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// checkRollups checks the 1m and 1h points of the two hours fill wrote
+func checkRollups(t *testing.T, s *Store, start time.Time) {
+	t.Helper()
+	minutes, _, err := s.Query(metrics.MetricCPUUsage, start, start.Add(2*time.Hour), ResolutionMinute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(minutes) != 120 {
+		t.Fatalf("got %d minute points, want 120", len(minutes))
+	}
+	for i, p := range minutes {
+		want := Point{Time: start.Add(time.Duration(i) * time.Minute), Min: float64(i % 60), Avg: float64(i % 60), Max: float64(i % 60), P95: float64(i % 60), Count: 6}
+		if p != want {
+			t.Fatalf("minute point %d = %+v, want %+v", i, p, want)
+		}
+	}
+
+	hours, _, err := s.Query(metrics.MetricCPUUsage, start, start.Add(2*time.Hour), ResolutionHour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(hours) != 2 {
+		t.Fatalf("got %d hour points, want 2", len(hours))
+	}
+	for i, p := range hours {
+		if p.Min != 0 || p.Avg != 29.5 || p.Max != 59 || p.Count != 360 || !p.Time.Equal(start.Add(time.Duration(i)*time.Hour)) {
+			t.Errorf("hour point %d = %+v", i, p)
+		}
+	}
+}
+
+func TestStoreRollups(t *testing.T) {
+	dir := t.TempDir()
+	start := time.Now().UTC().Truncate(time.Hour).Add(-3 * time.Hour)
+
+	s, err := Open(Options{Dir: dir})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Crossing into the second hour seals the first; the second is rolled
+	// up at query time
+	fill(t, s, start)
+	checkRollups(t, s, start)
+	if err := s.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Opening again seals the second hour, without duplicating the first
+	s, err = Open(Options{Dir: dir})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	if got, want := s.state.SealedThrough, start.Add(2*time.Hour); !got.Equal(want) {
+		t.Errorf("sealed through %s, want %s", got, want)
+	}
+	checkRollups(t, s, start)
+
+	samples, err := s.Samples(start.Add(time.Minute), start.Add(2*time.Minute))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(samples) != 6 || samples[0].Values[metrics.MetricCPUUsage] != 1 {
+		t.Errorf("Samples() = %v, want the 6 samples of the second minute", samples)
+	}
+}
+
+func TestStoreRetention(t *testing.T) {
+	dir := t.TempDir()
+	start := time.Now().UTC().Truncate(time.Hour).Add(-3 * time.Hour)
+	s, err := Open(Options{Dir: dir})
+	if err != nil {
+		t.Fatal(err)
+	}
+	fill(t, s, start)
+	s.Close()
+
+	// Only raw hours that ended over an hour ago are deleted, and only
+	// once sealed
+	s, err = Open(Options{Dir: dir, RawRetention: time.Hour + time.Minute})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	hours, err := s.segments(rawDir, rawLayout)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(hours) != 1 || !hours[0].Equal(start.Add(time.Hour)) {
+		t.Errorf("raw hours kept = %v, want only %s", hours, start.Add(time.Hour))
+	}
+	if s.FinestResolution(start) != ResolutionMinute {
+		t.Errorf("FinestResolution(%s) = %s, want 1m", start, s.FinestResolution(start))
+	}
+	checkRollups(t, s, start)
+}
+
+func TestStoreLocked(t *testing.T) {
+	dir := t.TempDir()
+	s, err := Open(Options{Dir: dir})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Open(Options{Dir: dir}); !errors.Is(err, ErrLocked) {
+		t.Errorf("second Open() error = %v, want ErrLocked", err)
+	}
+	r, err := OpenReadOnly(Options{Dir: dir})
+	if err != nil {
+		t.Fatalf("OpenReadOnly() error = %v", err)
+	}
+	defer r.Close()
+	if err := r.Append(&metrics.SystemMetrics{Timestamp: time.Now()}); err == nil {
+		t.Error("Append() to a read-only store succeeded")
+	}
+
+	if err := s.Close(); err != nil {
+		t.Fatal(err)
+	}
+	s, err = Open(Options{Dir: dir})
+	if err != nil {
+		t.Fatalf("Open() after Close() error = %v", err)
+	}
+	s.Close()
+}
+
+func TestStoreStaleLock(t *testing.T) {
+	// A pid left behind doesn't hold the lock, even if it has been reused
+	// by a live process such as init
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "LOCK"), []byte("1"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	s, err := Open(Options{Dir: dir})
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	s.Close()
+}
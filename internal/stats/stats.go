@@ -0,0 +1,114 @@
+// Package stats has small numeric helpers shared across packages.
+package stats
+
+import (
+	"math"
+	"sort"
+)
+
+// Summary describes the distribution of a set of values
+type Summary struct {
+	Count int
+	Min   float64
+	Avg   float64
+	Max   float64
+	P50   float64
+	P95   float64
+	P99   float64
+}
+
+// Summarize computes a Summary; the input slice is not modified
+func Summarize(values []float64) Summary {
+	if len(values) == 0 {
+		return Summary{}
+	}
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+
+	var sum float64
+	for _, v := range sorted {
+		sum += v
+	}
+	return Summary{
+		Count: len(sorted),
+		Min:   sorted[0],
+		Avg:   sum / float64(len(sorted)),
+		Max:   sorted[len(sorted)-1],
+		P50:   percentileSorted(sorted, 50),
+		P95:   percentileSorted(sorted, 95),
+		P99:   percentileSorted(sorted, 99),
+	}
+}
+
+// Percentile returns the p-th percentile (0-100) using linear interpolation
+// between closest ranks; the input slice is not modified
+func Percentile(values []float64, p float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+	return percentileSorted(sorted, p)
+}
+
+func percentileSorted(sorted []float64, p float64) float64 {
+	if len(sorted) == 1 {
+		return sorted[0]
+	}
+	rank := p / 100 * float64(len(sorted)-1)
+	lower := int(math.Floor(rank))
+	upper := int(math.Ceil(rank))
+	if lower < 0 {
+		return sorted[0]
+	}
+	if upper >= len(sorted) {
+		return sorted[len(sorted)-1]
+	}
+	frac := rank - float64(lower)
+	return sorted[lower] + (sorted[upper]-sorted[lower])*frac
+}
+
+// Fit is a least-squares line y = Intercept + Slope*x
+type Fit struct {
+	Slope     float64
+	Intercept float64
+	// R2 is the coefficient of determination, 0-1; 1 means every point is
+	// on the line
+	R2 float64
+}
+
+// LinearFit fits a line through the points (xs[i], ys[i]). It returns false
+// with fewer than two points or when all xs are equal.
+func LinearFit(xs, ys []float64) (Fit, bool) {
+	n := min(len(xs), len(ys))
+	if n < 2 {
+		return Fit{}, false
+	}
+	var meanX, meanY float64
+	for i := 0; i < n; i++ {
+		meanX += xs[i]
+		meanY += ys[i]
+	}
+	meanX /= float64(n)
+	meanY /= float64(n)
+
+	var sxx, sxy, syy float64
+	for i := 0; i < n; i++ {
+		dx, dy := xs[i]-meanX, ys[i]-meanY
+		sxx += dx * dx
+		sxy += dx * dy
+		syy += dy * dy
+	}
+	if sxx == 0 {
+		return Fit{}, false
+	}
+	fit := Fit{Slope: sxy / sxx}
+	fit.Intercept = meanY - fit.Slope*meanX
+	if syy == 0 {
+		// A flat line is a perfect fit
+		fit.R2 = 1
+	} else {
+		fit.R2 = sxy * sxy / (sxx * syy)
+	}
+	return fit, true
+}
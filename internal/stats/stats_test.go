@@ -0,0 +1,84 @@
+package stats
+
+import (
+	"math"
+	"testing"
+)
+
+// near reports whether a and b are equal within rounding error
+func near(a, b float64) bool {
+	return math.Abs(a-b) < 1e-9
+}
+
+func TestPercentile(t *testing.T) {
+	values := []float64{40, 10, 30, 20, 50}
+	tests := []struct {
+		p    float64
+		want float64
+	}{
+		{0, 10},
+		{25, 20},
+		{50, 30},
+		{90, 46},
+		{100, 50},
+		{-10, 10},
+		{150, 50},
+	}
+	for _, tt := range tests {
+		if got := Percentile(values, tt.p); !near(got, tt.want) {
+			t.Errorf("Percentile(%v, %v) = %v, want %v", values, tt.p, got, tt.want)
+		}
+	}
+	if values[0] != 40 {
+		t.Error("Percentile sorted its input")
+	}
+	if got := Percentile(nil, 50); got != 0 {
+		t.Errorf("Percentile(nil, 50) = %v, want 0", got)
+	}
+	if got := Percentile([]float64{7}, 99); got != 7 {
+		t.Errorf("Percentile([7], 99) = %v, want 7", got)
+	}
+}
+
+func TestSummarize(t *testing.T) {
+	values := make([]float64, 101)
+	for i := range values {
+		// 100 down to 0, so sorting matters
+		values[i] = float64(100 - i)
+	}
+	got := Summarize(values)
+	want := Summary{Count: 101, Min: 0, Avg: 50, Max: 100, P50: 50, P95: 95, P99: 99}
+	if got != want {
+		t.Errorf("Summarize() = %+v, want %+v", got, want)
+	}
+	if values[0] != 100 {
+		t.Error("Summarize sorted its input")
+	}
+	if got := Summarize(nil); got != (Summary{}) {
+		t.Errorf("Summarize(nil) = %+v, want zero", got)
+	}
+}
+
+func TestLinearFit(t *testing.T) {
+	tests := []struct {
+		name   string
+		xs, ys []float64
+		want   Fit
+		ok     bool
+	}{
+		{"line", []float64{0, 1, 2, 3}, []float64{1, 3, 5, 7}, Fit{Slope: 2, Intercept: 1, R2: 1}, true},
+		{"flat", []float64{0, 1, 2}, []float64{4, 4, 4}, Fit{Slope: 0, Intercept: 4, R2: 1}, true},
+		{"noisy", []float64{0, 1, 2, 3}, []float64{0, 2, 1, 3}, Fit{Slope: 0.8, Intercept: 0.3, R2: 0.64}, true},
+		{"uneven lengths", []float64{0, 1, 2, 9}, []float64{1, 2, 3}, Fit{Slope: 1, Intercept: 1, R2: 1}, true},
+		{"one point", []float64{1}, []float64{1}, Fit{}, false},
+		{"vertical", []float64{2, 2, 2}, []float64{1, 2, 3}, Fit{}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := LinearFit(tt.xs, tt.ys)
+			if ok != tt.ok || !near(got.Slope, tt.want.Slope) || !near(got.Intercept, tt.want.Intercept) || !near(got.R2, tt.want.R2) {
+				t.Errorf("LinearFit() = %+v, %v; want %+v, %v", got, ok, tt.want, tt.ok)
+			}
+		})
+	}
+}
@@ -0,0 +1,17 @@
+// Package units formats quantities for display.
+package units
+
+import "fmt"
+
+const (
+	mib = 1024 * 1024
+	gib = 1024 * mib
+)
+
+// Bytes writes a size as MB below a gigabyte and GB from there up
+func Bytes(b float64) string {
+	if b < gib {
+		return fmt.Sprintf("%.0fMB", b/mib)
+	}
+	return fmt.Sprintf("%.1fGB", b/gib)
+}
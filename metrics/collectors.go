@@ -0,0 +1,170 @@
+package metrics
+
+import (
+	"context"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/shirou/gopsutil/v3/cpu"
+	"github.com/shirou/gopsutil/v3/host"
+	"github.com/shirou/gopsutil/v3/load"
+	"github.com/shirou/gopsutil/v3/mem"
+)
+
+func collectCPU(ctx context.Context, c *Collector, m *SystemMetrics) error {
+	m.CPUCores = runtime.NumCPU()
+
+	// The model never changes, so only look it up once
+	if c.cpuModel == "" {
+		c.cpuModel = getCPUModel(ctx)
+	}
+	m.CPUModel = c.cpuModel
+
+	usage, err := c.cpuPercent(ctx)
+	if err != nil {
+		return err
+	}
+	m.CPUUsage = usage
+	return nil
+}
+
+func getCPUModel(ctx context.Context) string {
+	// Try to get CPU info from gopsutil
+	cpuInfo, err := cpu.InfoWithContext(ctx)
+	if err != nil || len(cpuInfo) == 0 {
+		return "Unknown CPU"
+	}
+	return cpuInfo[0].ModelName
+}
+
+func collectLoad(ctx context.Context, c *Collector, m *SystemMetrics) error {
+	loadAvg, err := getLoadAverages(ctx, c)
+	if err != nil {
+		return err
+	}
+	m.LoadAverage = loadAvg
+	return nil
+}
+
+func getLoadAverages(ctx context.Context, c *Collector) ([3]float64, error) {
+	// Get load average (Linux/macOS style)
+	loadStat, err := load.AvgWithContext(ctx)
+	if err != nil {
+		// On Windows, load average isn't available, so estimate from CPU
+		// usage (shared with the cpu collector, so this doesn't block again)
+		if runtime.GOOS == "windows" {
+			if cpuPercent, cpuErr := c.cpuPercent(ctx); cpuErr == nil {
+				return estimateLoad(cpuPercent), nil
+			}
+		}
+		return [3]float64{0, 0, 0}, nil
+	}
+	return [3]float64{loadStat.Load1, loadStat.Load5, loadStat.Load15}, nil
+}
+
+// estimateLoad converts a CPU percentage to a load-like metric for
+// platforms without load averages
+func estimateLoad(cpuPercent float64) [3]float64 {
+	estimatedLoad := cpuPercent / 100.0 * float64(runtime.NumCPU())
+	return [3]float64{estimatedLoad, estimatedLoad, estimatedLoad}
+}
+
+// MemoryInfo holds RAM and swap totals in bytes
+type MemoryInfo struct {
+	Total     uint64
+	Used      uint64
+	SwapUsed  uint64
+	SwapTotal uint64
+}
+
+func collectMemory(ctx context.Context, _ *Collector, m *SystemMetrics) error {
+	memInfo, err := GetMemoryInfo(ctx)
+	if err != nil {
+		return err
+	}
+	m.MemoryUsed = memInfo.Used
+	m.MemoryTotal = memInfo.Total
+	m.SwapUsed = memInfo.SwapUsed
+	m.SwapTotal = memInfo.SwapTotal
+	m.MemPressure = MemoryPressure(memInfo.Used, memInfo.Total, memInfo.SwapUsed)
+	return nil
+}
+
+// GetMemoryInfo reads current RAM and swap usage
+func GetMemoryInfo(ctx context.Context) (*MemoryInfo, error) {
+	info := &MemoryInfo{}
+
+	// Get virtual memory statistics
+	vmStat, err := mem.VirtualMemoryWithContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	info.Total = vmStat.Total
+	info.Used = vmStat.Used
+
+	// Get swap memory statistics; if swap info is not available, continue
+	// with zero values
+	if swapStat, err := mem.SwapMemoryWithContext(ctx); err == nil {
+		info.SwapUsed = swapStat.Used
+		info.SwapTotal = swapStat.Total
+	}
+
+	return info, nil
+}
+
+// MemoryPressure is a cross-platform memory pressure estimation
+func MemoryPressure(memUsed, memTotal, swapUsed uint64) string {
+	if memTotal == 0 {
+		return "unknown"
+	}
+
+	memUsagePercent := float64(memUsed) / float64(memTotal) * 100
+	swapUsageGB := float64(swapUsed) / (1024 * 1024 * 1024)
+
+	// Critical: Very high memory usage or significant swap usage
+	if memUsagePercent > 95 || swapUsageGB > 2 {
+		return "critical"
+	}
+	// Warning: High memory usage or any swap usage
+	if memUsagePercent > 85 || swapUsageGB > 0.1 {
+		return "warning"
+	}
+	// Normal: Low to moderate memory usage, no swap
+	return "normal"
+}
+
+func collectGPU(ctx context.Context, _ *Collector, m *SystemMetrics) error {
+	m.GPUModel = getGPUModel(ctx)
+	return nil
+}
+
+// Cross-platform GPU model detection (simplified)
+func getGPUModel(ctx context.Context) string {
+	// Note: Full GPU detection would require platform-specific code
+	// For now, we'll detect common GPU patterns from CPU model or use generic detection
+	switch runtime.GOOS {
+	case "darwin":
+		// Try to detect Apple Silicon integrated GPU
+		if hostInfo, err := host.InfoWithContext(ctx); err == nil {
+			if strings.Contains(strings.ToLower(hostInfo.Platform), "darwin") {
+				return "Integrated GPU (macOS)"
+			}
+		}
+	case "windows":
+		return "Graphics Card (Windows)"
+	case "linux":
+		return "Graphics Card (Linux)"
+	}
+	return "Unknown GPU"
+}
+
+func collectHost(ctx context.Context, _ *Collector, m *SystemMetrics) error {
+	uptime, err := host.UptimeWithContext(ctx)
+	if err != nil {
+		return err
+	}
+	m.Uptime = time.Duration(uptime) * time.Second
+	return nil
+}
@@ -0,0 +1,72 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"runtime"
+
+	"github.com/shirou/gopsutil/v3/cpu"
+)
+
+// CPUTimes is a cumulative reading of CPU time across all cores, in
+// seconds. Utilization is derived from the difference of two readings, so
+// reading the counters never blocks.
+type CPUTimes struct {
+	Total  float64
+	Busy   float64
+	Iowait float64
+}
+
+// ReadCPUTimes reads the current cumulative CPU times
+func ReadCPUTimes(ctx context.Context) (CPUTimes, error) {
+	times, err := cpu.TimesWithContext(ctx, false)
+	if err != nil {
+		return CPUTimes{}, err
+	}
+	if len(times) == 0 {
+		return CPUTimes{}, errors.New("no CPU times reported")
+	}
+	t := times[0]
+
+	total := t.User + t.System + t.Idle + t.Nice + t.Iowait + t.Irq +
+		t.Softirq + t.Steal + t.Guest + t.GuestNice
+	if runtime.GOOS == "linux" {
+		// Guest time is already accounted for in user time
+		total -= t.Guest + t.GuestNice
+	}
+	return CPUTimes{
+		Total:  total,
+		Busy:   total - t.Idle - t.Iowait,
+		Iowait: t.Iowait,
+	}, nil
+}
+
+// CPUPercent returns the busy percentage between two readings
+func CPUPercent(prev, cur CPUTimes) float64 {
+	total := cur.Total - prev.Total
+	if total <= 0 {
+		return 0
+	}
+	busy := cur.Busy - prev.Busy
+	return clampPercent(busy / total * 100)
+}
+
+// IowaitPercent returns the share of CPU time spent waiting on I/O between
+// two readings
+func IowaitPercent(prev, cur CPUTimes) float64 {
+	total := cur.Total - prev.Total
+	if total <= 0 {
+		return 0
+	}
+	return clampPercent((cur.Iowait - prev.Iowait) / total * 100)
+}
+
+func clampPercent(p float64) float64 {
+	if p < 0 {
+		return 0
+	}
+	if p > 100 {
+		return 100
+	}
+	return p
+}
@@ -0,0 +1,284 @@
+// Package metrics collects system performance snapshots.
+//
+// Collection is split into named collectors ("cpu", "load", "memory", "gpu",
+// "host", "process") so callers can pick only the data they need. Nothing in this
+// package writes to the terminal; failures are returned as errors and
+// recorded in the collector statistics.
+package metrics
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/shirou/gopsutil/v3/process"
+)
+
+// SystemMetrics holds all the system performance data captured in one snapshot
+type SystemMetrics struct {
+	Timestamp   time.Time
+	CPUUsage    float64 // percent across all cores
+	LoadAverage [3]float64
+	MemoryUsed  uint64 // bytes
+	MemoryTotal uint64 // bytes
+	SwapUsed    uint64 // bytes
+	SwapTotal   uint64 // bytes
+	MemPressure string // "normal", "warning", "critical" or "unknown"
+	GPUUsage    float64
+	CPUCores    int
+	CPUModel    string
+	MemorySpeed string
+	GPUModel    string
+	Uptime      time.Duration
+	// Processes lists the largest processes by resident memory and the
+	// busiest by CPU, largest first. Only the "process" collector fills it.
+	Processes []ProcessInfo
+}
+
+// MemoryPercent returns RAM usage as a percentage of total memory
+func (m *SystemMetrics) MemoryPercent() float64 {
+	if m.MemoryTotal == 0 {
+		return 0
+	}
+	return float64(m.MemoryUsed) / float64(m.MemoryTotal) * 100
+}
+
+// Collector names accepted in Options.Collectors
+const (
+	CollectorCPU     = "cpu"
+	CollectorLoad    = "load"
+	CollectorMemory  = "memory"
+	CollectorGPU     = "gpu"
+	CollectorHost    = "host"
+	CollectorProcess = "process"
+)
+
+// DefaultSampleWindow is how long the first CPU measurement waits
+const DefaultSampleWindow = time.Second
+
+// DefaultTopProcesses is how many processes the process collector keeps
+const DefaultTopProcesses = 15
+
+// DefaultProcessScan is how often the process collector reads every process
+const DefaultProcessScan = 30 * time.Second
+
+// Options selects which collectors run
+type Options struct {
+	// Collectors lists the collector names to run. Empty means all.
+	Collectors []string
+	// SampleWindow is how long the first Collect waits between two CPU
+	// counter readings. Later calls measure since the previous Collect and
+	// return immediately. Zero means DefaultSampleWindow.
+	SampleWindow time.Duration
+	// TopProcesses is how many of the largest processes, and of the
+	// busiest, to record. Zero means DefaultTopProcesses.
+	TopProcesses int
+	// ProcessScan is how often the process collector reads every process
+	// to pick the largest and busiest; in between it reads only those, so
+	// frequent samples stay cheap on hosts with many processes. Zero means
+	// DefaultProcessScan.
+	ProcessScan time.Duration
+}
+
+// collectFunc fills its part of the snapshot
+type collectFunc func(ctx context.Context, c *Collector, m *SystemMetrics) error
+
+type collectorDef struct {
+	name    string
+	collect collectFunc
+}
+
+// collectors run in this order
+var collectors = []collectorDef{
+	{CollectorCPU, collectCPU},
+	{CollectorLoad, collectLoad},
+	{CollectorMemory, collectMemory},
+	{CollectorGPU, collectGPU},
+	{CollectorHost, collectHost},
+	{CollectorProcess, collectProcesses},
+}
+
+// CollectorNames returns the names of all available collectors
+func CollectorNames() []string {
+	names := make([]string, len(collectors))
+	for i, c := range collectors {
+		names[i] = c.name
+	}
+	return names
+}
+
+// CollectorStat describes the health of one collector
+type CollectorStat struct {
+	Name         string
+	LastDuration time.Duration
+	LastError    error
+	Runs         uint64
+	Errors       uint64
+}
+
+// Collector gathers snapshots with a fixed set of collectors and keeps
+// per-collector statistics. Rates such as CPU usage are computed from the
+// counter deltas between successive calls to Collect. It is safe for
+// concurrent use.
+type Collector struct {
+	active       []collectorDef
+	window       time.Duration
+	topProcesses int
+	processScan  time.Duration
+
+	// collectMu serializes Collect and guards the counter state below
+	collectMu sync.Mutex
+	prevCPU   *CPUTimes
+	// prevProcCPU is each process's CPU seconds when it was last read
+	prevProcCPU map[int32]procCPU
+	// tracked are the processes kept by the last full scan, at lastScan;
+	// scanRated is whether that scan could measure CPU usage
+	tracked   []*process.Process
+	lastScan  time.Time
+	scanRated bool
+	cpuModel  string
+	cycle     cycleState
+
+	mu    sync.Mutex
+	stats map[string]*CollectorStat
+}
+
+// cycleState caches readings shared by several collectors within one Collect
+type cycleState struct {
+	cpuPercent float64
+	cpuErr     error
+	cpuRead    bool
+}
+
+// NewCollector returns a Collector for the given options. Unknown collector
+// names are reported as an error.
+func NewCollector(opts Options) (*Collector, error) {
+	c := &Collector{
+		window:       opts.SampleWindow,
+		topProcesses: opts.TopProcesses,
+		processScan:  opts.ProcessScan,
+		stats:        make(map[string]*CollectorStat),
+	}
+	if c.window <= 0 {
+		c.window = DefaultSampleWindow
+	}
+	if c.topProcesses <= 0 {
+		c.topProcesses = DefaultTopProcesses
+	}
+	if c.processScan <= 0 {
+		c.processScan = DefaultProcessScan
+	}
+	if len(opts.Collectors) == 0 {
+		c.active = collectors
+	} else {
+		wanted := make(map[string]bool)
+		for _, name := range opts.Collectors {
+			wanted[name] = true
+		}
+		for _, def := range collectors {
+			if wanted[def.name] {
+				c.active = append(c.active, def)
+				delete(wanted, def.name)
+			}
+		}
+		for name := range wanted {
+			return nil, fmt.Errorf("unknown collector %q", name)
+		}
+	}
+	for _, def := range c.active {
+		c.stats[def.name] = &CollectorStat{Name: def.name}
+	}
+	return c, nil
+}
+
+// Collect takes one snapshot. The snapshot is always returned; the error
+// joins the failures of individual collectors, whose fields stay zero.
+func (c *Collector) Collect(ctx context.Context) (*SystemMetrics, error) {
+	c.collectMu.Lock()
+	defer c.collectMu.Unlock()
+	c.cycle = cycleState{}
+
+	m := &SystemMetrics{Timestamp: time.Now(), MemPressure: "unknown"}
+
+	var errs []error
+	for _, def := range c.active {
+		if err := ctx.Err(); err != nil {
+			return m, err
+		}
+		start := time.Now()
+		err := def.collect(ctx, c, m)
+		c.record(def.name, time.Since(start), err)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", def.name, err))
+		}
+	}
+	return m, errors.Join(errs...)
+}
+
+func (c *Collector) record(name string, d time.Duration, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	stat := c.stats[name]
+	stat.LastDuration = d
+	stat.LastError = err
+	stat.Runs++
+	if err != nil {
+		stat.Errors++
+	}
+}
+
+// Stats returns a copy of the per-collector statistics in collection order
+func (c *Collector) Stats() []CollectorStat {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	stats := make([]CollectorStat, 0, len(c.active))
+	for _, def := range c.active {
+		stats = append(stats, *c.stats[def.name])
+	}
+	return stats
+}
+
+// cpuPercent returns CPU usage since the previous Collect, waiting one
+// sample window on the first call. The result is shared within a cycle.
+func (c *Collector) cpuPercent(ctx context.Context) (float64, error) {
+	if c.cycle.cpuRead {
+		return c.cycle.cpuPercent, c.cycle.cpuErr
+	}
+	c.cycle.cpuRead = true
+
+	if c.prevCPU == nil {
+		first, err := ReadCPUTimes(ctx)
+		if err != nil {
+			c.cycle.cpuErr = err
+			return 0, err
+		}
+		c.prevCPU = &first
+
+		select {
+		case <-time.After(c.window):
+		case <-ctx.Done():
+			c.cycle.cpuErr = ctx.Err()
+			return 0, ctx.Err()
+		}
+	}
+
+	cur, err := ReadCPUTimes(ctx)
+	if err != nil {
+		c.cycle.cpuErr = err
+		return 0, err
+	}
+	c.cycle.cpuPercent = CPUPercent(*c.prevCPU, cur)
+	c.prevCPU = &cur
+	return c.cycle.cpuPercent, nil
+}
+
+// Collect takes a single snapshot with the given options
+func Collect(ctx context.Context, opts Options) (*SystemMetrics, error) {
+	c, err := NewCollector(opts)
+	if err != nil {
+		return nil, err
+	}
+	return c.Collect(ctx)
+}
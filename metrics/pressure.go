@@ -0,0 +1,95 @@
+package metrics
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrPressureUnsupported is returned by ReadPressure where the kernel
+// doesn't report pressure stall information
+var ErrPressureUnsupported = errors.New("pressure stall information is not available on this system")
+
+// Stall is cumulative time in which tasks were stalled on a resource:
+// Some means at least one task waited, Full means all non-idle tasks did
+type Stall struct {
+	Some time.Duration `json:"some"`
+	Full time.Duration `json:"full"`
+}
+
+// Pressure is a cumulative reading of Linux pressure stall information
+// (PSI). Like CPUTimes, it is meant to be differenced between two readings.
+type Pressure struct {
+	CPU    Stall `json:"cpu"`
+	IO     Stall `json:"io"`
+	Memory Stall `json:"memory"`
+}
+
+// Sub returns the stall time accumulated between prev and p
+func (p Pressure) Sub(prev Pressure) Pressure {
+	sub := func(a, b Stall) Stall { return Stall{Some: a.Some - b.Some, Full: a.Full - b.Full} }
+	return Pressure{CPU: sub(p.CPU, prev.CPU), IO: sub(p.IO, prev.IO), Memory: sub(p.Memory, prev.Memory)}
+}
+
+// ReadPressure reads /proc/pressure (Linux 4.20 and later)
+func ReadPressure() (Pressure, error) {
+	if runtime.GOOS != "linux" {
+		return Pressure{}, ErrPressureUnsupported
+	}
+	var p Pressure
+	for _, r := range []struct {
+		name  string
+		stall *Stall
+	}{{"cpu", &p.CPU}, {"io", &p.IO}, {"memory", &p.Memory}} {
+		stall, err := readStall("/proc/pressure/" + r.name)
+		if errors.Is(err, os.ErrNotExist) {
+			return Pressure{}, ErrPressureUnsupported
+		}
+		if err != nil {
+			return Pressure{}, err
+		}
+		*r.stall = stall
+	}
+	return p, nil
+}
+
+// readStall parses lines like "some avg10=0.00 avg60=0.00 avg300=0.00 total=2104168",
+// where total is in microseconds
+func readStall(path string) (Stall, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return Stall{}, err
+	}
+	defer f.Close()
+
+	var stall Stall
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 {
+			continue
+		}
+		for _, field := range fields[1:] {
+			value, ok := strings.CutPrefix(field, "total=")
+			if !ok {
+				continue
+			}
+			us, err := strconv.ParseInt(value, 10, 64)
+			if err != nil {
+				return Stall{}, fmt.Errorf("%s: %w", path, err)
+			}
+			switch fields[0] {
+			case "some":
+				stall.Some = time.Duration(us) * time.Microsecond
+			case "full":
+				stall.Full = time.Duration(us) * time.Microsecond
+			}
+		}
+	}
+	return stall, scanner.Err()
+}
@@ -0,0 +1,108 @@
+package metrics
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/shirou/gopsutil/v3/process"
+)
+
+// ProcessInfo is the memory footprint and CPU usage of one process
+type ProcessInfo struct {
+	PID  int32
+	Name string
+	RSS  uint64 // bytes
+	// CPUPercent is CPU usage since the process was last read, where 100
+	// is one core fully busy. It is zero the first time.
+	CPUPercent float64
+}
+
+// procCPU is a process's CPU seconds at a time
+type procCPU struct {
+	busy float64
+	at   time.Time
+}
+
+// collectProcesses records the largest and busiest processes. Every
+// ProcessScan it reads every process to pick them; in between it reads only
+// the ones picked last time, as reading thousands of processes every few
+// seconds is a load of its own.
+func collectProcesses(ctx context.Context, c *Collector, m *SystemMetrics) error {
+	now := time.Now()
+	// Until a full scan has measured CPU usage, the busiest can't be told
+	full := !c.scanRated || now.Sub(c.lastScan) >= c.processScan
+	procs := c.tracked
+	if full {
+		var err error
+		if procs, err = process.ProcessesWithContext(ctx); err != nil {
+			return err
+		}
+	}
+
+	// Read RSS and CPU time for each process but only look up names for
+	// the ones kept; processes that exit or deny access in between are
+	// skipped. CPU usage is measured since each process was last read, so
+	// those read only by full scans get their average since the last one.
+	type sized struct {
+		p   *process.Process
+		rss uint64
+		cpu float64
+	}
+	cpuTimes := make(map[int32]procCPU, len(c.prevProcCPU))
+	if !full {
+		for pid, prev := range c.prevProcCPU {
+			cpuTimes[pid] = prev
+		}
+	}
+	all := make([]sized, 0, len(procs))
+	for _, p := range procs {
+		info, err := p.MemoryInfoWithContext(ctx)
+		if err != nil || info.RSS == 0 {
+			continue
+		}
+		s := sized{p: p, rss: info.RSS}
+		if times, err := p.TimesWithContext(ctx); err == nil {
+			busy := times.User + times.System
+			cpuTimes[p.Pid] = procCPU{busy: busy, at: now}
+			if prev, ok := c.prevProcCPU[p.Pid]; ok {
+				if elapsed := now.Sub(prev.at).Seconds(); elapsed > 0 {
+					s.cpu = max(busy-prev.busy, 0) / elapsed * 100
+				}
+			}
+		}
+		all = append(all, s)
+	}
+	rated := len(c.prevProcCPU) > 0
+	c.prevProcCPU = cpuTimes
+
+	// Keep the largest by memory and the busiest by CPU
+	sort.Slice(all, func(i, j int) bool { return all[i].rss > all[j].rss })
+	kept := all[:min(len(all), c.topProcesses)]
+	rest := append([]sized(nil), all[len(kept):]...)
+	sort.Slice(rest, func(i, j int) bool { return rest[i].cpu > rest[j].cpu })
+	for _, s := range rest[:min(len(rest), c.topProcesses)] {
+		if s.cpu > 0 {
+			kept = append(kept, s)
+		}
+	}
+	sort.Slice(kept, func(i, j int) bool { return kept[i].rss > kept[j].rss })
+	if full {
+		c.tracked = make([]*process.Process, len(kept))
+		for i, s := range kept {
+			c.tracked[i] = s.p
+		}
+		c.lastScan = now
+		c.scanRated = rated
+	}
+
+	m.Processes = make([]ProcessInfo, 0, len(kept))
+	for _, s := range kept {
+		name, err := s.p.NameWithContext(ctx)
+		if err != nil {
+			continue
+		}
+		m.Processes = append(m.Processes, ProcessInfo{PID: s.p.Pid, Name: name, RSS: s.rss, CPUPercent: s.cpu})
+	}
+	return nil
+}
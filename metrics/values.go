@@ -0,0 +1,116 @@
+package metrics
+
+import "time"
+
+// Series describes one numeric metric derived from a snapshot
+type Series struct {
+	Name string
+	Unit string
+	Help string
+}
+
+// Metric names produced by Values
+const (
+	MetricCPUUsage          = "cpu.usage"
+	MetricCPUCores          = "cpu.cores"
+	MetricLoad1             = "cpu.load1"
+	MetricLoad5             = "cpu.load5"
+	MetricLoad15            = "cpu.load15"
+	MetricMemoryUsedPercent = "memory.used_percent"
+	MetricMemoryUsedBytes   = "memory.used_bytes"
+	MetricMemoryTotalBytes  = "memory.total_bytes"
+	MetricSwapUsedBytes     = "swap.used_bytes"
+	MetricSwapTotalBytes    = "swap.total_bytes"
+	MetricMemoryPressure    = "memory.pressure"
+)
+
+// AllSeries lists every metric Values can produce
+var AllSeries = []Series{
+	{MetricCPUUsage, "percent", "CPU usage across all cores"},
+	{MetricCPUCores, "", "logical CPU cores"},
+	{MetricLoad1, "", "1-minute load average"},
+	{MetricLoad5, "", "5-minute load average"},
+	{MetricLoad15, "", "15-minute load average"},
+	{MetricMemoryUsedPercent, "percent", "RAM in use"},
+	{MetricMemoryUsedBytes, "bytes", "RAM in use"},
+	{MetricMemoryTotalBytes, "bytes", "installed RAM"},
+	{MetricSwapUsedBytes, "bytes", "swap in use"},
+	{MetricSwapTotalBytes, "bytes", "swap configured"},
+	{MetricMemoryPressure, "level", "memory pressure: 0 normal, 1 warning, 2 critical"},
+}
+
+// LookupSeries returns the description of a metric name
+func LookupSeries(name string) (Series, bool) {
+	for _, s := range AllSeries {
+		if s.Name == name {
+			return s, true
+		}
+	}
+	return Series{}, false
+}
+
+// PressureLevel maps a MemPressure string to a number, and false if unknown
+func PressureLevel(pressure string) (float64, bool) {
+	switch pressure {
+	case "normal":
+		return 0, true
+	case "warning":
+		return 1, true
+	case "critical", "urgent":
+		return 2, true
+	}
+	return 0, false
+}
+
+// Values flattens a snapshot into named numeric metrics. Metrics whose
+// collector didn't run are left out.
+func (m *SystemMetrics) Values() map[string]float64 {
+	values := make(map[string]float64, len(AllSeries))
+	if m.CPUCores > 0 {
+		values[MetricCPUUsage] = m.CPUUsage
+		values[MetricCPUCores] = float64(m.CPUCores)
+	}
+	if m.CPUCores > 0 || m.LoadAverage != [3]float64{} {
+		values[MetricLoad1] = m.LoadAverage[0]
+		values[MetricLoad5] = m.LoadAverage[1]
+		values[MetricLoad15] = m.LoadAverage[2]
+	}
+	if m.MemoryTotal > 0 {
+		values[MetricMemoryUsedPercent] = m.MemoryPercent()
+		values[MetricMemoryUsedBytes] = float64(m.MemoryUsed)
+		values[MetricMemoryTotalBytes] = float64(m.MemoryTotal)
+		values[MetricSwapUsedBytes] = float64(m.SwapUsed)
+		values[MetricSwapTotalBytes] = float64(m.SwapTotal)
+	}
+	if level, ok := PressureLevel(m.MemPressure); ok {
+		values[MetricMemoryPressure] = level
+	}
+	return values
+}
+
+// FromValues rebuilds the numeric fields of a snapshot from the output of
+// Values, e.g. from recorded history. Descriptive fields such as CPUModel
+// stay empty, and CPUCores is 1 for history recorded without a core count.
+func FromValues(t time.Time, values map[string]float64) SystemMetrics {
+	m := SystemMetrics{Timestamp: t, MemPressure: "unknown"}
+	if v, ok := values[MetricCPUUsage]; ok {
+		m.CPUUsage = v
+		m.CPUCores = max(int(values[MetricCPUCores]), 1)
+	}
+	m.LoadAverage = [3]float64{values[MetricLoad1], values[MetricLoad5], values[MetricLoad15]}
+	m.MemoryUsed = uint64(values[MetricMemoryUsedBytes])
+	m.MemoryTotal = uint64(values[MetricMemoryTotalBytes])
+	m.SwapUsed = uint64(values[MetricSwapUsedBytes])
+	m.SwapTotal = uint64(values[MetricSwapTotalBytes])
+	if level, ok := values[MetricMemoryPressure]; ok {
+		switch {
+		case level >= 1.5:
+			m.MemPressure = "critical"
+		case level >= 0.5:
+			m.MemPressure = "warning"
+		default:
+			m.MemPressure = "normal"
+		}
+	}
+	return m
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"flag"
+	"io"
+	"os"
+
+	"github.com/xmarkclx/bottleneck-check/render"
+)
+
+// stdout and stderr carry all human-readable output, adapted to what the
+// terminal can show. JSON goes to os.Stdout directly.
+var (
+	stdout io.Writer = os.Stdout
+	stderr io.Writer = os.Stderr
+	// stdoutProfile is what stdout can show, for the full-screen monitor
+	stdoutProfile = render.Full
+)
+
+// outputOptions are the flags every command takes to control its output
+type outputOptions struct {
+	color *string
+	plain *bool
+}
+
+// addOutputFlags adds -color and -plain to a command's flags
+func addOutputFlags(fs *flag.FlagSet) *outputOptions {
+	return &outputOptions{
+		color: fs.String("color", cfg.Color, "color output: auto (on terminals, unless NO_COLOR is set or TERM=dumb), always or never"),
+		plain: fs.Bool("plain", cfg.Plain, "plain ASCII output without colors, emoji or symbols, e.g. for logs"),
+	}
+}
+
+// apply sets up stdout and stderr for the parsed flags
+func (o *outputOptions) apply() error {
+	mode, err := render.ParseColorMode(*o.color)
+	if err != nil {
+		return err
+	}
+	setOutput(mode, *o.plain)
+	return nil
+}
+
+// setOutput detects what stdout and stderr can show
+func setOutput(mode render.ColorMode, plain bool) {
+	stdoutProfile = detectProfile(os.Stdout, mode, plain)
+	stdout = render.NewWriter(os.Stdout, stdoutProfile)
+	stderr = render.NewWriter(os.Stderr, detectProfile(os.Stderr, mode, plain))
+}
+
+func detectProfile(f *os.File, mode render.ColorMode, plain bool) render.Profile {
+	if plain {
+		return render.Plain
+	}
+	return render.Detect(f, mode)
+}
@@ -0,0 +1,99 @@
+package procs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/shirou/gopsutil/v3/process"
+)
+
+// ErrProcessChanged is returned by Do and Renice when the PID no longer
+// belongs to the process the action was meant for
+var ErrProcessChanged = errors.New("the process has exited and its PID now belongs to another")
+
+// Action is something the monitor can do to a process
+type Action string
+
+const (
+	ActionTerminate Action = "terminate" // SIGTERM: ask the process to exit
+	ActionKill      Action = "kill"      // SIGKILL: end it immediately
+	ActionSuspend   Action = "suspend"   // SIGSTOP
+	ActionResume    Action = "resume"    // SIGCONT
+)
+
+// Describe says what an action does, e.g. "send SIGTERM to"
+func (a Action) Describe() string {
+	switch a {
+	case ActionTerminate:
+		return "send SIGTERM to"
+	case ActionKill:
+		return "send SIGKILL to"
+	case ActionSuspend:
+		return "suspend"
+	case ActionResume:
+		return "resume"
+	}
+	return string(a)
+}
+
+// Do applies an action to the process with pid that started at started,
+// as listed in Process.Started. It refuses if the PID has been reused since.
+func Do(ctx context.Context, pid int32, started time.Time, a Action) error {
+	p, err := verify(ctx, pid, started)
+	if err != nil {
+		return err
+	}
+	switch a {
+	case ActionTerminate:
+		return p.TerminateWithContext(ctx)
+	case ActionKill:
+		return p.KillWithContext(ctx)
+	case ActionSuspend:
+		return p.SuspendWithContext(ctx)
+	case ActionResume:
+		return p.ResumeWithContext(ctx)
+	}
+	return fmt.Errorf("unknown action %q", a)
+}
+
+// Nice values range from MinNice, the highest priority, to MaxNice
+const (
+	MinNice = -20
+	MaxNice = 19
+)
+
+// Renice sets the nice value of the process with pid that started at
+// started, refusing if the PID has been reused since. Raising priority
+// (lowering the value) usually needs root.
+func Renice(ctx context.Context, pid int32, started time.Time, nice int) error {
+	if nice < MinNice || nice > MaxNice {
+		return fmt.Errorf("nice value must be between %d and %d", MinNice, MaxNice)
+	}
+	if _, err := verify(ctx, pid, started); err != nil {
+		return err
+	}
+	return renice(pid, nice)
+}
+
+// verify returns the process with pid if it is still the one that started
+// at started. A PID is only reused once its process has exited, so the
+// start time tells them apart; without one, nothing is done to the process.
+func verify(ctx context.Context, pid int32, started time.Time) (*process.Process, error) {
+	if started.IsZero() {
+		return nil, fmt.Errorf("can't verify PID %d: start time unknown", pid)
+	}
+	p, err := process.NewProcessWithContext(ctx, pid)
+	if err != nil {
+		return nil, err
+	}
+	ms, err := p.CreateTimeWithContext(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("can't tell whether PID %d is still the same process: %w", pid, err)
+	}
+	if ms != started.UnixMilli() {
+		return nil, ErrProcessChanged
+	}
+	return p, nil
+}
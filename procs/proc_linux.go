@@ -0,0 +1,76 @@
+package procs
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/shirou/gopsutil/v3/process"
+)
+
+// readNice reads the nice value from /proc/<pid>/stat. gopsutil returns the
+// raw getpriority value on Linux, which is 20 minus the nice value.
+func readNice(_ context.Context, p *process.Process) int32 {
+	data, err := os.ReadFile("/proc/" + strconv.Itoa(int(p.Pid)) + "/stat")
+	if err != nil {
+		return 0
+	}
+	// The command name in parentheses may contain spaces; nice is the 17th
+	// field after it
+	s := string(data)
+	fields := strings.Fields(s[strings.LastIndexByte(s, ')')+1:])
+	if len(fields) < 17 {
+		return 0
+	}
+	nice, _ := strconv.Atoi(fields[16])
+	return int32(nice)
+}
+
+// readSwap reads VmSwap from /proc/<pid>/status
+func readSwap(pid int32) uint64 {
+	data, err := os.ReadFile("/proc/" + strconv.Itoa(int(pid)) + "/status")
+	if err != nil {
+		return 0
+	}
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if !strings.HasPrefix(line, "VmSwap:") {
+			continue
+		}
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			return 0
+		}
+		kb, _ := strconv.ParseUint(fields[1], 10, 64)
+		return kb * 1024
+	}
+	return 0
+}
+
+// readCgroup reads the process's control group. With cgroup v2 there is
+// one line, "0::/path"; with v1 the memory controller's group is the most
+// telling.
+func readCgroup(pid int32) string {
+	data, err := os.ReadFile("/proc/" + strconv.Itoa(int(pid)) + "/cgroup")
+	if err != nil {
+		return ""
+	}
+	first := ""
+	for _, line := range strings.Split(strings.TrimSpace(string(data)), "\n") {
+		parts := strings.SplitN(line, ":", 3)
+		if len(parts) != 3 {
+			continue
+		}
+		if parts[0] == "0" || strings.Contains(","+parts[1]+",", ",memory,") {
+			return parts[2]
+		}
+		if first == "" {
+			first = parts[2]
+		}
+	}
+	return first
+}
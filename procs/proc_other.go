@@ -0,0 +1,20 @@
+//go:build !linux
+
+package procs
+
+import (
+	"context"
+
+	"github.com/shirou/gopsutil/v3/process"
+)
+
+func readNice(ctx context.Context, p *process.Process) int32 {
+	nice, _ := p.NiceWithContext(ctx)
+	return nice
+}
+
+// readSwap isn't available outside Linux
+func readSwap(int32) uint64 { return 0 }
+
+// readCgroup: control groups exist only on Linux
+func readCgroup(int32) string { return "" }
@@ -0,0 +1,197 @@
+// Package procs lists every process with its resource use and acts on
+// processes: the process table of the monitor. Unlike the metrics
+// package, which records only the largest processes in each snapshot, it
+// reads all of them, on demand.
+package procs
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/shirou/gopsutil/v3/process"
+)
+
+// Process is one process and its resource use. Rates are measured since
+// the previous List and are zero on the first.
+type Process struct {
+	PID        int32
+	Name       string
+	User       string
+	CPUPercent float64 // 100 is one core fully busy
+	RSS        uint64  // bytes
+	Swap       uint64  // bytes; zero where the OS doesn't report it
+	IORate     float64 // bytes read and written per second; zero if unreadable
+	Nice       int32
+	Status     string
+	// Started is when the process started, to tell it from a later process
+	// given the same PID; zero if unreadable
+	Started time.Time
+}
+
+// counters are the cumulative readings rates are computed from
+type counters struct {
+	started int64 // ms since the epoch
+	user    string
+	cpu     float64 // seconds
+	io      uint64  // bytes
+}
+
+// Table lists processes, computing CPU and I/O rates from the counters of
+// the previous List. It is not safe for concurrent use.
+type Table struct {
+	prev     map[int32]counters
+	prevTime time.Time
+}
+
+// NewTable returns a Table; the first List has no rates yet
+func NewTable() *Table {
+	return &Table{prev: make(map[int32]counters)}
+}
+
+// List reads every process. Processes that exit or deny access while being
+// read are skipped, and fields that can't be read stay zero.
+func (t *Table) List(ctx context.Context) ([]Process, error) {
+	procs, err := process.ProcessesWithContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	elapsed := now.Sub(t.prevTime).Seconds()
+	cur := make(map[int32]counters, len(procs))
+
+	list := make([]Process, 0, len(procs))
+	for _, p := range procs {
+		name, err := p.NameWithContext(ctx)
+		if err != nil {
+			continue
+		}
+		info := Process{PID: p.Pid, Name: name}
+		c := counters{}
+		if ms, err := p.CreateTimeWithContext(ctx); err == nil {
+			c.started = ms
+			info.Started = time.UnixMilli(ms)
+		}
+		// A PID reused by a new process starts over
+		prev, seen := t.prev[p.Pid]
+		seen = seen && prev.started == c.started
+
+		// Owners don't change, so look each up once
+		c.user = prev.user
+		if !seen {
+			c.user, _ = p.UsernameWithContext(ctx)
+		}
+		info.User = c.user
+
+		if mem, err := p.MemoryInfoWithContext(ctx); err == nil {
+			info.RSS = mem.RSS
+		}
+		info.Swap = readSwap(p.Pid)
+		if times, err := p.TimesWithContext(ctx); err == nil {
+			c.cpu = times.User + times.System
+			if seen && elapsed > 0 {
+				info.CPUPercent = max(c.cpu-prev.cpu, 0) / elapsed * 100
+			}
+		}
+		if io, err := p.IOCountersWithContext(ctx); err == nil {
+			c.io = io.ReadBytes + io.WriteBytes
+			if seen && elapsed > 0 && c.io >= prev.io {
+				info.IORate = float64(c.io-prev.io) / elapsed
+			}
+		}
+		info.Nice = readNice(ctx, p)
+		if status, err := p.StatusWithContext(ctx); err == nil && len(status) > 0 {
+			info.Status = status[0]
+		}
+		cur[p.Pid] = c
+		list = append(list, info)
+	}
+	t.prev, t.prevTime = cur, now
+	return list, nil
+}
+
+// SortKey is a column processes can be sorted by
+type SortKey string
+
+// Sort keys, in the order the monitor cycles through them
+const (
+	SortCPU  SortKey = "cpu"
+	SortRSS  SortKey = "rss"
+	SortSwap SortKey = "swap"
+	SortIO   SortKey = "io"
+)
+
+// SortKeys lists every SortKey
+var SortKeys = []SortKey{SortCPU, SortRSS, SortSwap, SortIO}
+
+// Sort orders processes by key, highest first; ties go to the lower PID
+func Sort(procs []Process, key SortKey) {
+	value := func(p *Process) float64 {
+		switch key {
+		case SortRSS:
+			return float64(p.RSS)
+		case SortSwap:
+			return float64(p.Swap)
+		case SortIO:
+			return p.IORate
+		}
+		return p.CPUPercent
+	}
+	sort.SliceStable(procs, func(i, j int) bool {
+		a, b := value(&procs[i]), value(&procs[j])
+		if a != b {
+			return a > b
+		}
+		return procs[i].PID < procs[j].PID
+	})
+}
+
+// Filter returns the processes whose name or user contains query, ignoring
+// case. An empty query matches everything.
+func Filter(procs []Process, query string) []Process {
+	query = strings.ToLower(strings.TrimSpace(query))
+	if query == "" {
+		return procs
+	}
+	var matched []Process
+	for _, p := range procs {
+		if strings.Contains(strings.ToLower(p.Name), query) || strings.Contains(strings.ToLower(p.User), query) {
+			matched = append(matched, p)
+		}
+	}
+	return matched
+}
+
+// Details is what the monitor shows about the selected process
+type Details struct {
+	PID     int32
+	PPID    int32
+	Cmdline string
+	// Cgroup is the control group on Linux, empty elsewhere
+	Cgroup string
+	// OpenFiles is the number of open file descriptors, or -1 if unknown
+	OpenFiles int32
+	Threads   int32
+	Started   time.Time
+}
+
+// ReadDetails reads the details of one process. Fields that can't be read,
+// e.g. for another user's process, are left empty.
+func ReadDetails(ctx context.Context, pid int32) (*Details, error) {
+	p, err := process.NewProcessWithContext(ctx, pid)
+	if err != nil {
+		return nil, err
+	}
+	d := &Details{PID: pid, OpenFiles: -1, Cgroup: readCgroup(pid)}
+	d.PPID, _ = p.PpidWithContext(ctx)
+	d.Cmdline, _ = p.CmdlineWithContext(ctx)
+	if n, err := p.NumFDsWithContext(ctx); err == nil {
+		d.OpenFiles = n
+	}
+	d.Threads, _ = p.NumThreadsWithContext(ctx)
+	if ms, err := p.CreateTimeWithContext(ctx); err == nil {
+		d.Started = time.UnixMilli(ms)
+	}
+	return d, nil
+}
@@ -0,0 +1,129 @@
+package procs
+
+import (
+	"context"
+	"errors"
+	"os/exec"
+	"reflect"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func pids(procs []Process) []int32 {
+	var out []int32
+	for _, p := range procs {
+		out = append(out, p.PID)
+	}
+	return out
+}
+
+func TestSort(t *testing.T) {
+	list := []Process{
+		{PID: 4, CPUPercent: 10, RSS: 100, Swap: 0, IORate: 5},
+		{PID: 2, CPUPercent: 50, RSS: 100, Swap: 30, IORate: 0},
+		{PID: 3, CPUPercent: 10, RSS: 300, Swap: 10, IORate: 1},
+		{PID: 1, CPUPercent: 0, RSS: 200, Swap: 0, IORate: 9},
+	}
+	tests := []struct {
+		key  SortKey
+		want []int32
+	}{
+		{SortCPU, []int32{2, 3, 4, 1}},
+		{SortRSS, []int32{3, 1, 2, 4}},
+		{SortSwap, []int32{2, 3, 1, 4}},
+		{SortIO, []int32{1, 4, 3, 2}},
+	}
+	for _, tt := range tests {
+		procs := append([]Process(nil), list...)
+		Sort(procs, tt.key)
+		if got := pids(procs); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Sort(%s) = %v, want %v", tt.key, got, tt.want)
+		}
+	}
+}
+
+func TestFilter(t *testing.T) {
+	list := []Process{
+		{PID: 1, Name: "systemd", User: "root"},
+		{PID: 2, Name: "Firefox", User: "alice"},
+		{PID: 3, Name: "bash", User: "alice"},
+	}
+	tests := []struct {
+		query string
+		want  []int32
+	}{
+		{"", []int32{1, 2, 3}},
+		{"  ", []int32{1, 2, 3}},
+		{"fire", []int32{2}},
+		{"FIRE", []int32{2}},
+		{"alice", []int32{2, 3}},
+		{"s", []int32{1, 3}},
+		{"nothing", nil},
+	}
+	for _, tt := range tests {
+		if got := pids(Filter(list, tt.query)); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Filter(%q) = %v, want %v", tt.query, got, tt.want)
+		}
+	}
+}
+
+// startSleep starts a process to act on and returns it as List reports it
+func startSleep(t *testing.T) (*exec.Cmd, Process) {
+	t.Helper()
+	cmd := exec.Command("sleep", "60")
+	if err := cmd.Start(); err != nil {
+		t.Skipf("can't start sleep: %v", err)
+	}
+	t.Cleanup(func() {
+		cmd.Process.Kill()
+		cmd.Wait()
+	})
+	list, err := NewTable().List(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, p := range list {
+		if p.PID == int32(cmd.Process.Pid) {
+			if p.Started.IsZero() {
+				t.Skip("process start times can't be read here")
+			}
+			return cmd, p
+		}
+	}
+	t.Fatalf("List() lacks PID %d", cmd.Process.Pid)
+	return nil, Process{}
+}
+
+func TestDoRefusesReusedPID(t *testing.T) {
+	cmd, p := startSleep(t)
+	ctx := context.Background()
+	if err := Do(ctx, p.PID, p.Started.Add(time.Second), ActionKill); !errors.Is(err, ErrProcessChanged) {
+		t.Errorf("Do() with another start time: error = %v, want ErrProcessChanged", err)
+	}
+	// Without a start time the process can't be told from a new one, but
+	// that doesn't mean it has changed
+	if err := Do(ctx, p.PID, time.Time{}, ActionKill); err == nil || errors.Is(err, ErrProcessChanged) {
+		t.Errorf("Do() without a start time: error = %v, want one saying it is unknown", err)
+	}
+	if err := Renice(ctx, p.PID, p.Started.Add(time.Second), 5); !errors.Is(err, ErrProcessChanged) {
+		t.Errorf("Renice() error = %v, want ErrProcessChanged", err)
+	}
+
+	if err := Do(ctx, p.PID, p.Started, ActionTerminate); err != nil {
+		t.Fatalf("Do() error = %v", err)
+	}
+	cmd.Wait()
+	status, ok := cmd.ProcessState.Sys().(syscall.WaitStatus)
+	if !ok || !status.Signaled() || status.Signal() != syscall.SIGTERM {
+		t.Errorf("process ended with %v, want SIGTERM", cmd.ProcessState)
+	}
+}
+
+func TestReniceRange(t *testing.T) {
+	for _, nice := range []int{MinNice - 1, MaxNice + 1} {
+		if err := Renice(context.Background(), 1, time.Now(), nice); err == nil {
+			t.Errorf("Renice(%d) succeeded", nice)
+		}
+	}
+}
@@ -0,0 +1,9 @@
+//go:build windows
+
+package procs
+
+import "errors"
+
+func renice(int32, int) error {
+	return errors.New("renice is not supported on Windows")
+}
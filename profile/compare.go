@@ -0,0 +1,125 @@
+package profile
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Unit says how a Delta's values are measured and displayed
+type Unit string
+
+const (
+	UnitSeconds Unit = "seconds"
+	UnitBytes   Unit = "bytes"
+	UnitShare   Unit = "share" // 0-1
+)
+
+// Delta compares one measure between run A and run B
+type Delta struct {
+	Name          string
+	Unit          Unit
+	A, B          float64
+	LowerIsBetter bool
+}
+
+// Change returns B minus A
+func (d Delta) Change() float64 {
+	return d.B - d.A
+}
+
+// Improved reports whether B is better than A
+func (d Delta) Improved() bool {
+	return d.Change() != 0 && (d.Change() < 0) == d.LowerIsBetter
+}
+
+// Comparison is an A/B comparison of two runs
+type Comparison struct {
+	A, B *Profile
+	// Measures are wall time, CPU efficiency, peak memory, I/O totals and
+	// stall times
+	Measures []Delta
+	// Bottlenecks compare the share of each run held back by CPU, I/O and
+	// memory
+	Bottlenecks []Delta
+}
+
+// Compare compares run b against run a
+func Compare(a, b *Profile) *Comparison {
+	c := &Comparison{A: a, B: b}
+	c.Measures = []Delta{
+		{"Wall time", UnitSeconds, a.Wall.Seconds(), b.Wall.Seconds(), true},
+		{"CPU time", UnitSeconds, a.CPUTime().Seconds(), b.CPUTime().Seconds(), true},
+		{"CPU efficiency", UnitShare, a.Efficiency(), b.Efficiency(), false},
+		{"Peak memory", UnitBytes, float64(a.PeakRSS), float64(b.PeakRSS), true},
+		{"Disk read", UnitBytes, float64(a.ReadBytes), float64(b.ReadBytes), true},
+		{"Disk written", UnitBytes, float64(a.WriteBytes), float64(b.WriteBytes), true},
+	}
+	if a.Pressure != nil && b.Pressure != nil {
+		c.Measures = append(c.Measures,
+			Delta{"Stalled on CPU", UnitSeconds, a.Pressure.CPU.Some.Seconds(), b.Pressure.CPU.Some.Seconds(), true},
+			Delta{"Stalled on I/O", UnitSeconds, a.Pressure.IO.Some.Seconds(), b.Pressure.IO.Some.Seconds(), true},
+			Delta{"Stalled on memory", UnitSeconds, a.Pressure.Memory.Some.Seconds(), b.Pressure.Memory.Some.Seconds(), true},
+		)
+	}
+
+	ba, bb := a.Bottlenecks(), b.Bottlenecks()
+	c.Bottlenecks = []Delta{
+		// Higher efficiency means the run used the CPU it had, so it's
+		// better, even though it also means the run is more CPU-bound
+		{"CPU", UnitShare, ba.CPU, bb.CPU, false},
+		{"I/O", UnitShare, ba.IO, bb.IO, true},
+		{"Memory", UnitShare, ba.Memory, bb.Memory, true},
+	}
+	return c
+}
+
+// Shift describes how the bottleneck moved between the runs, naming the
+// resources whose share changed most, e.g. "I/O fell from 42% to 6% of the
+// run (-36 points); CPU rose from 30% to 78% (+48 points)"
+func (c *Comparison) Shift() string {
+	var fell, rose *Delta
+	for i := range c.Bottlenecks {
+		d := &c.Bottlenecks[i]
+		if d.Change() < 0 && (fell == nil || d.Change() < fell.Change()) {
+			fell = d
+		}
+		if d.Change() > 0 && (rose == nil || d.Change() > rose.Change()) {
+			rose = d
+		}
+	}
+	var parts []string
+	for _, d := range []*Delta{fell, rose} {
+		if d == nil || math.Abs(d.Change()) < 0.05 {
+			continue
+		}
+		direction := "rose"
+		if d.Change() < 0 {
+			direction = "fell"
+		}
+		parts = append(parts, fmt.Sprintf("%s %s from %.0f%% to %.0f%% (%+.0f points)",
+			d.Name, direction, d.A*100, d.B*100, d.Change()*100))
+	}
+	summary := "No bottleneck moved by more than 5 points"
+	if len(parts) > 0 {
+		summary = strings.Join(parts, "; ")
+	}
+	if c.A.Verdict != c.B.Verdict {
+		return fmt.Sprintf("%s → %s: %s", c.A.Verdict.Describe(), c.B.Verdict.Describe(), summary)
+	}
+	return fmt.Sprintf("Still %s: %s", c.A.Verdict.Describe(), summary)
+}
+
+// Resolve finds a saved profile by path, or by file name in Dir with or
+// without the .json extension
+func Resolve(name string) (string, error) {
+	candidates := []string{name, filepath.Join(Dir(), name), filepath.Join(Dir(), name+".json")}
+	for _, path := range candidates {
+		if info, err := os.Stat(path); err == nil && !info.IsDir() {
+			return path, nil
+		}
+	}
+	return "", fmt.Errorf("no run profile %q (looked in the current directory and %s)", name, Dir())
+}
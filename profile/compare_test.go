@@ -0,0 +1,151 @@
+package profile
+
+import (
+	"testing"
+	"time"
+
+	"github.com/xmarkclx/bottleneck-check/metrics"
+)
+
+func TestDeltaImproved(t *testing.T) {
+	tests := []struct {
+		d    Delta
+		want bool
+	}{
+		{Delta{A: 10, B: 5, LowerIsBetter: true}, true},
+		{Delta{A: 5, B: 10, LowerIsBetter: true}, false},
+		{Delta{A: 0.3, B: 0.8, LowerIsBetter: false}, true},
+		{Delta{A: 0.8, B: 0.3, LowerIsBetter: false}, false},
+		{Delta{A: 5, B: 5, LowerIsBetter: true}, false},
+		{Delta{A: 5, B: 5, LowerIsBetter: false}, false},
+	}
+	for _, tt := range tests {
+		if got := tt.d.Improved(); got != tt.want {
+			t.Errorf("%+v Improved() = %v, want %v", tt.d, got, tt.want)
+		}
+	}
+}
+
+func TestCompare(t *testing.T) {
+	// Before: an I/O-bound build on 8 cores; after moving it to tmpfs with
+	// more jobs it runs faster and keeps the cores busy
+	a := &Profile{
+		Wall:       100 * time.Second,
+		Cores:      8,
+		UserTime:   240 * time.Second,
+		PeakRSS:    2 << 30,
+		ReadBytes:  4 << 30,
+		WriteBytes: 1 << 30,
+		Pressure: &metrics.Pressure{
+			IO:     metrics.Stall{Some: 42 * time.Second},
+			Memory: metrics.Stall{Some: 2 * time.Second},
+		},
+		Verdict: VerdictIOBound,
+	}
+	b := &Profile{
+		Wall:       50 * time.Second,
+		Cores:      8,
+		UserTime:   312 * time.Second,
+		PeakRSS:    3 << 30,
+		ReadBytes:  4 << 30,
+		WriteBytes: 512 << 20,
+		Pressure: &metrics.Pressure{
+			IO:     metrics.Stall{Some: 3 * time.Second},
+			Memory: metrics.Stall{Some: 1 * time.Second},
+		},
+		Verdict: VerdictCPUBound,
+	}
+	c := Compare(a, b)
+
+	// The sign of each change, and whether it counts as an improvement
+	want := map[string]struct {
+		sign     int
+		improved bool
+	}{
+		"Wall time":         {-1, true},
+		"CPU time":          {+1, false},
+		"CPU efficiency":    {+1, true},
+		"Peak memory":       {+1, false},
+		"Disk read":         {0, false},
+		"Disk written":      {-1, true},
+		"Stalled on CPU":    {0, false},
+		"Stalled on I/O":    {-1, true},
+		"Stalled on memory": {-1, true},
+	}
+	if len(c.Measures) != len(want) {
+		t.Fatalf("got %d measures, want %d", len(c.Measures), len(want))
+	}
+	for _, d := range c.Measures {
+		w, ok := want[d.Name]
+		if !ok {
+			t.Errorf("unexpected measure %q", d.Name)
+			continue
+		}
+		if sign(d.Change()) != w.sign || d.Improved() != w.improved {
+			t.Errorf("%s: change %v improved %v, want sign %d improved %v", d.Name, d.Change(), d.Improved(), w.sign, w.improved)
+		}
+	}
+
+	// CPU 30% -> 78%, I/O 42% -> 6%, memory 2% -> 2%
+	wantShift := "I/O-bound → CPU-bound: I/O fell from 42% to 6% (-36 points); CPU rose from 30% to 78% (+48 points)"
+	if got := c.Shift(); got != wantShift {
+		t.Errorf("Shift() = %q, want %q", got, wantShift)
+	}
+
+	// Without pressure stall information on either side, stall times are
+	// left out
+	a.Pressure = nil
+	if c := Compare(a, b); len(c.Measures) != 6 {
+		t.Errorf("got %d measures without pressure, want 6", len(c.Measures))
+	}
+}
+
+func TestShift(t *testing.T) {
+	profile := func(verdict Verdict, cpu, io float64) *Profile {
+		return &Profile{
+			Wall:     100 * time.Second,
+			Cores:    1,
+			UserTime: time.Duration(cpu * float64(100*time.Second)),
+			Pressure: &metrics.Pressure{IO: metrics.Stall{Some: time.Duration(io * float64(100*time.Second))}},
+			Verdict:  verdict,
+		}
+	}
+	tests := []struct {
+		name string
+		a, b *Profile
+		want string
+	}{
+		{
+			"nothing moved",
+			profile(VerdictCPUBound, 0.9, 0.1), profile(VerdictCPUBound, 0.92, 0.08),
+			"Still CPU-bound: No bottleneck moved by more than 5 points",
+		},
+		{
+			"one rose",
+			profile(VerdictUnderutilized, 0.2, 0.1), profile(VerdictUnderutilized, 0.4, 0.1),
+			"Still Underutilizing cores: CPU rose from 20% to 40% (+20 points)",
+		},
+		{
+			"one fell",
+			profile(VerdictIOBound, 0.3, 0.6), profile(VerdictIOBound, 0.3, 0.3),
+			"Still I/O-bound: I/O fell from 60% to 30% (-30 points)",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Compare(tt.a, tt.b).Shift(); got != tt.want {
+				t.Errorf("Shift() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func sign(v float64) int {
+	switch {
+	case v < 0:
+		return -1
+	case v > 0:
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,156 @@
+// Package profile runs a single command and records how it used the
+// machine: CPU, memory and I/O of its process tree, sampled at a high rate
+// alongside system-wide CPU, memory and pressure stall readings. The result
+// is a Profile with a bottleneck verdict and recommendations, which can be
+// saved and compared with another run of the same workload.
+package profile
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/xmarkclx/bottleneck-check/analysis"
+	"github.com/xmarkclx/bottleneck-check/history"
+	"github.com/xmarkclx/bottleneck-check/metrics"
+)
+
+// Sample is one reading taken while the command ran
+type Sample struct {
+	Time time.Time `json:"t"`
+	// Process tree of the command
+	Processes int     `json:"procs"`
+	Cores     float64 `json:"cores"`      // cores busy, e.g. 3.5
+	RSS       uint64  `json:"rss"`        // bytes, summed over the tree
+	ReadRate  float64 `json:"read_rate"`  // bytes per second from storage
+	WriteRate float64 `json:"write_rate"` // bytes per second to storage
+	// Whole system
+	SystemCPU  float64 `json:"system_cpu"` // percent
+	Iowait     float64 `json:"iowait"`     // percent of CPU time
+	MemoryUsed uint64  `json:"memory_used"`
+	SwapUsed   uint64  `json:"swap_used"`
+}
+
+// Verdict names the main bottleneck of a run
+type Verdict string
+
+const (
+	VerdictCPUBound      Verdict = "cpu-bound"
+	VerdictIOBound       Verdict = "io-bound"
+	VerdictMemoryBound   Verdict = "memory-bound"
+	VerdictUnderutilized Verdict = "underutilizing-cores"
+	VerdictWaiting       Verdict = "waiting"
+)
+
+// Describe returns a short human-readable verdict
+func (v Verdict) Describe() string {
+	switch v {
+	case VerdictCPUBound:
+		return "CPU-bound"
+	case VerdictIOBound:
+		return "I/O-bound"
+	case VerdictMemoryBound:
+		return "Memory-bound"
+	case VerdictUnderutilized:
+		return "Underutilizing cores"
+	case VerdictWaiting:
+		return "Mostly waiting"
+	}
+	return string(v)
+}
+
+// Profile is the record of one command's run
+type Profile struct {
+	Command  []string      `json:"command"`
+	Start    time.Time     `json:"start"`
+	Wall     time.Duration `json:"wall"`
+	ExitCode int           `json:"exit_code"`
+	Interval time.Duration `json:"interval"`
+	// Machine
+	Cores       int    `json:"cores"`
+	MemoryTotal uint64 `json:"memory_total"`
+	// Totals for the command, including children it waited for
+	UserTime   time.Duration `json:"user_time"`
+	SystemTime time.Duration `json:"system_time"`
+	ReadBytes  uint64        `json:"read_bytes"`
+	WriteBytes uint64        `json:"write_bytes"`
+	PeakRSS    uint64        `json:"peak_rss"`
+	// Pressure is the system-wide stall time during the run; nil where
+	// pressure stall information isn't available
+	Pressure *metrics.Pressure `json:"pressure,omitempty"`
+
+	Samples         []Sample                  `json:"samples"`
+	Verdict         Verdict                   `json:"verdict"`
+	Recommendations []analysis.Recommendation `json:"recommendations"`
+}
+
+// CPUTime returns the CPU time the command used
+func (p *Profile) CPUTime() time.Duration {
+	return p.UserTime + p.SystemTime
+}
+
+// Efficiency is CPU time divided by the CPU time all cores could have
+// given over the run, 0-1
+func (p *Profile) Efficiency() float64 {
+	if p.Wall <= 0 || p.Cores == 0 {
+		return 0
+	}
+	return p.CPUTime().Seconds() / (p.Wall.Seconds() * float64(p.Cores))
+}
+
+// AverageCores is how many cores the command kept busy on average
+func (p *Profile) AverageCores() float64 {
+	if p.Wall <= 0 {
+		return 0
+	}
+	return p.CPUTime().Seconds() / p.Wall.Seconds()
+}
+
+// Dir returns the directory run profiles are saved in
+func Dir() string {
+	return filepath.Join(history.DataDir(), "runs")
+}
+
+// DefaultPath returns a file name in Dir for a profile, from its start
+// time and command name
+func DefaultPath(p *Profile) string {
+	name := "run"
+	if len(p.Command) > 0 {
+		name = filepath.Base(p.Command[0])
+	}
+	name = strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-' || r == '_' || r == '.' {
+			return r
+		}
+		return '_'
+	}, name)
+	return filepath.Join(Dir(), p.Start.Format("20060102-150405")+"-"+name+".json")
+}
+
+// Save writes a profile as JSON, creating the directory if needed
+func Save(path string, p *Profile) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(p, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0o644)
+}
+
+// Load reads a saved profile
+func Load(path string) (*Profile, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var p Profile
+	if err := json.Unmarshal(data, &p); err != nil {
+		return nil, fmt.Errorf("reading run profile %s: %w", path, err)
+	}
+	return &p, nil
+}
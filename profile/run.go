@@ -0,0 +1,214 @@
+package profile
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"runtime"
+	"syscall"
+	"time"
+
+	"github.com/shirou/gopsutil/v3/process"
+
+	"github.com/xmarkclx/bottleneck-check/metrics"
+)
+
+// DefaultInterval is how often Run samples when Options leaves it zero
+const DefaultInterval = 250 * time.Millisecond
+
+// Options configures Run
+type Options struct {
+	// Interval between samples
+	Interval time.Duration
+	// Started, when set, is called with the process once it has started,
+	// e.g. to pass signals on to it
+	Started func(*os.Process)
+}
+
+// Run starts cmd, samples it and the system until it exits, and returns the
+// profile. cmd must not have been started. A command that runs but exits
+// with a non-zero status is not an error; its status is in ExitCode.
+func Run(ctx context.Context, cmd *exec.Cmd, opts Options) (*Profile, error) {
+	if opts.Interval <= 0 {
+		opts.Interval = DefaultInterval
+	}
+	p := &Profile{Command: cmd.Args, Interval: opts.Interval, Cores: runtime.NumCPU()}
+
+	prevCPU, cpuErr := metrics.ReadCPUTimes(ctx)
+	startPressure, pressureErr := metrics.ReadPressure()
+
+	p.Start = time.Now()
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+	if opts.Started != nil {
+		opts.Started(cmd.Process)
+	}
+	done := make(chan error, 1)
+	go func() { done <- cmd.Wait() }()
+
+	tree := newTreeSampler(int32(cmd.Process.Pid), p.Start)
+	ticker := time.NewTicker(opts.Interval)
+	defer ticker.Stop()
+
+	var waitErr error
+sampling:
+	for {
+		select {
+		case <-ticker.C:
+			now := time.Now()
+			s := tree.sample(ctx, now)
+			if cur, err := metrics.ReadCPUTimes(ctx); err == nil && cpuErr == nil {
+				s.SystemCPU = metrics.CPUPercent(prevCPU, cur)
+				s.Iowait = metrics.IowaitPercent(prevCPU, cur)
+				prevCPU = cur
+			}
+			if mem, err := metrics.GetMemoryInfo(ctx); err == nil {
+				s.MemoryUsed, s.SwapUsed = mem.Used, mem.SwapUsed
+				p.MemoryTotal = mem.Total
+			}
+			p.Samples = append(p.Samples, s)
+			p.PeakRSS = max(p.PeakRSS, s.RSS)
+		case waitErr = <-done:
+			break sampling
+		}
+	}
+	p.Wall = time.Since(p.Start)
+
+	var exitErr *exec.ExitError
+	if waitErr != nil && !errors.As(waitErr, &exitErr) {
+		return nil, waitErr
+	}
+	p.ExitCode = exitCode(cmd.ProcessState)
+	p.UserTime = cmd.ProcessState.UserTime()
+	p.SystemTime = cmd.ProcessState.SystemTime()
+	if p.CPUTime() == 0 {
+		// Fall back to the sampled usage where the OS doesn't report it
+		for _, s := range p.Samples {
+			p.UserTime += time.Duration(s.Cores * float64(opts.Interval))
+		}
+	}
+	p.ReadBytes, p.WriteBytes = tree.readBytes, tree.writeBytes
+	if pressureErr == nil {
+		if end, err := metrics.ReadPressure(); err == nil {
+			stalled := end.Sub(startPressure)
+			p.Pressure = &stalled
+		}
+	}
+	if p.MemoryTotal == 0 {
+		if mem, err := metrics.GetMemoryInfo(ctx); err == nil {
+			p.MemoryTotal = mem.Total
+		}
+	}
+
+	p.Verdict, p.Recommendations = judge(p)
+	return p, nil
+}
+
+// exitCode returns the command's exit status, or 128 plus the signal
+// number when it was killed by a signal, as shells report it
+func exitCode(state *os.ProcessState) int {
+	if code := state.ExitCode(); code >= 0 {
+		return code
+	}
+	if ws, ok := state.Sys().(syscall.WaitStatus); ok && ws.Signaled() {
+		return 128 + int(ws.Signal())
+	}
+	return 1
+}
+
+// treeSampler follows the process tree under a root process and turns
+// cumulative per-process counters into rates
+type treeSampler struct {
+	root      int32
+	last      time.Time
+	prevCPU   map[int32]float64 // CPU seconds at the last sample
+	prevRead  map[int32]uint64
+	prevWrite map[int32]uint64
+
+	readBytes  uint64
+	writeBytes uint64
+}
+
+func newTreeSampler(root int32, start time.Time) *treeSampler {
+	return &treeSampler{
+		root:      root,
+		last:      start,
+		prevCPU:   make(map[int32]float64),
+		prevRead:  make(map[int32]uint64),
+		prevWrite: make(map[int32]uint64),
+	}
+}
+
+func (t *treeSampler) sample(ctx context.Context, now time.Time) Sample {
+	s := Sample{Time: now}
+	elapsed := now.Sub(t.last).Seconds()
+	t.last = now
+
+	members := t.members(ctx)
+	s.Processes = len(members)
+	cpu := make(map[int32]float64, len(members))
+	read := make(map[int32]uint64, len(members))
+	written := make(map[int32]uint64, len(members))
+	var cpuDelta float64
+	var readDelta, writeDelta uint64
+	for _, p := range members {
+		if times, err := p.TimesWithContext(ctx); err == nil {
+			total := times.User + times.System
+			cpu[p.Pid] = total
+			// A process first seen now used all of its CPU time since the
+			// last sample, as far as we can tell
+			cpuDelta += max(total-t.prevCPU[p.Pid], 0)
+		}
+		if mem, err := p.MemoryInfoWithContext(ctx); err == nil {
+			s.RSS += mem.RSS
+		}
+		if io, err := p.IOCountersWithContext(ctx); err == nil {
+			read[p.Pid], written[p.Pid] = io.ReadBytes, io.WriteBytes
+			if io.ReadBytes >= t.prevRead[p.Pid] {
+				readDelta += io.ReadBytes - t.prevRead[p.Pid]
+			}
+			if io.WriteBytes >= t.prevWrite[p.Pid] {
+				writeDelta += io.WriteBytes - t.prevWrite[p.Pid]
+			}
+		}
+	}
+	t.prevCPU, t.prevRead, t.prevWrite = cpu, read, written
+	t.readBytes += readDelta
+	t.writeBytes += writeDelta
+
+	if elapsed > 0 {
+		s.Cores = cpuDelta / elapsed
+		s.ReadRate = float64(readDelta) / elapsed
+		s.WriteRate = float64(writeDelta) / elapsed
+	}
+	return s
+}
+
+// members returns the root process and all of its descendants
+func (t *treeSampler) members(ctx context.Context) []*process.Process {
+	all, err := process.ProcessesWithContext(ctx)
+	if err != nil {
+		return nil
+	}
+	children := make(map[int32][]*process.Process)
+	var root *process.Process
+	for _, p := range all {
+		if p.Pid == t.root {
+			root = p
+			continue
+		}
+		if ppid, err := p.PpidWithContext(ctx); err == nil {
+			children[ppid] = append(children[ppid], p)
+		}
+	}
+	if root == nil {
+		return nil
+	}
+	members := []*process.Process{root}
+	for i := 0; i < len(members); i++ {
+		members = append(members, children[members[i].Pid]...)
+	}
+	return members
+}
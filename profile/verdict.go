@@ -0,0 +1,169 @@
+package profile
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/xmarkclx/bottleneck-check/analysis"
+	"github.com/xmarkclx/bottleneck-check/internal/units"
+)
+
+// Limits used to judge a run
+const (
+	// cpuBoundEfficiency is the share of all cores' time above which a run
+	// is CPU-bound
+	cpuBoundEfficiency = 0.75
+	// saturatedSystemCPU is system-wide CPU usage at which the machine as a
+	// whole is out of CPU, whoever is using it
+	saturatedSystemCPU = 90.0
+	// ioBoundStall is the share of wall time stalled on I/O, or spent in
+	// iowait, above which a run is I/O-bound
+	ioBoundStall = 0.20
+	// memoryBoundStall is the share of wall time stalled on memory above
+	// which a run is memory-bound
+	memoryBoundStall = 0.10
+	// memoryBoundSwap is swap growth during the run that counts as
+	// memory-bound on its own
+	memoryBoundSwap = 256 << 20
+	// busyCores is the least average parallelism of a run that is computing
+	// rather than waiting
+	busyCores = 0.5
+)
+
+// findings are the conditions a run meets, used to pick a verdict
+type findings struct {
+	efficiency float64
+	avgCores   float64
+	peakCores  float64
+	systemCPU  float64 // average percent
+	ioShare    float64 // of wall time
+	memShare   float64 // of wall time
+	swapGrowth int64
+	peakMemory float64 // percent of RAM
+}
+
+func measure(p *Profile) findings {
+	f := findings{efficiency: p.Efficiency(), avgCores: p.AverageCores()}
+
+	var iowait float64
+	cores := make([]float64, 0, len(p.Samples))
+	for _, s := range p.Samples {
+		f.systemCPU += s.SystemCPU
+		iowait += s.Iowait
+		cores = append(cores, s.Cores)
+		if p.MemoryTotal > 0 {
+			f.peakMemory = max(f.peakMemory, float64(s.MemoryUsed)/float64(p.MemoryTotal)*100)
+		}
+	}
+	if n := len(p.Samples); n > 0 {
+		f.systemCPU /= float64(n)
+		iowait /= float64(n)
+		f.swapGrowth = int64(p.Samples[n-1].SwapUsed) - int64(p.Samples[0].SwapUsed)
+		// Use the 90th percentile so one sampling hiccup isn't the peak
+		sort.Float64s(cores)
+		f.peakCores = cores[(n-1)*9/10]
+	}
+
+	// Prefer pressure stall information; fall back to iowait
+	f.ioShare = iowait / 100
+	if p.Pressure != nil && p.Wall > 0 {
+		f.ioShare = p.Pressure.IO.Some.Seconds() / p.Wall.Seconds()
+		f.memShare = p.Pressure.Memory.Some.Seconds() / p.Wall.Seconds()
+	}
+	return f
+}
+
+// judge picks the main bottleneck and explains every condition that held.
+// Memory comes first because swapping also shows up as I/O, and I/O before
+// CPU because a saturated disk leaves cores idle.
+func judge(p *Profile) (Verdict, []analysis.Recommendation) {
+	f := measure(p)
+	var verdict Verdict
+	var recs []analysis.Recommendation
+	add := func(v Verdict, rec analysis.Recommendation) {
+		if verdict == "" {
+			verdict = v
+		}
+		recs = append(recs, rec)
+	}
+
+	if f.memShare >= memoryBoundStall || f.swapGrowth >= memoryBoundSwap || f.peakMemory >= 95 {
+		severity := analysis.SeverityHigh
+		if f.swapGrowth >= memoryBoundSwap {
+			severity = analysis.SeverityCritical
+		}
+		add(VerdictMemoryBound, analysis.Recommendation{
+			Rule:      "run.memory_bound",
+			Component: "Memory",
+			Severity:  severity,
+			Reason: fmt.Sprintf("Memory-bound: peak %s RSS, RAM %.0f%% full, swap grew %s, %.0f%% of the run stalled on memory",
+				units.Bytes(float64(p.PeakRSS)), f.peakMemory, units.Bytes(float64(max(f.swapGrowth, 0))), f.memShare*100),
+			Suggestion: "Add RAM, or lower parallelism so the working set fits in memory.",
+		})
+	}
+	if f.ioShare >= ioBoundStall && f.efficiency < cpuBoundEfficiency {
+		add(VerdictIOBound, analysis.Recommendation{
+			Rule:      "run.io_bound",
+			Component: "Disk",
+			Severity:  analysis.SeverityHigh,
+			Reason: fmt.Sprintf("I/O-bound: %.0f%% of the run waited on I/O (read %s, wrote %s)",
+				f.ioShare*100, units.Bytes(float64(p.ReadBytes)), units.Bytes(float64(p.WriteBytes))),
+			Suggestion: "Faster storage (NVMe), a tmpfs for build output or temporary files, or caching inputs would shorten this run.",
+		})
+	}
+	if f.efficiency >= cpuBoundEfficiency || f.systemCPU >= saturatedSystemCPU && f.avgCores >= busyCores {
+		severity := analysis.SeverityHigh
+		suggestion := "A CPU with more or faster cores would shorten this run."
+		if f.efficiency < cpuBoundEfficiency {
+			// The machine is saturated, but not by this command
+			severity = analysis.SeverityMedium
+			suggestion = "Other processes are competing for CPU; stop them or run this job at a quieter time."
+		}
+		add(VerdictCPUBound, analysis.Recommendation{
+			Rule:      "run.cpu_bound",
+			Component: "CPU",
+			Severity:  severity,
+			Reason: fmt.Sprintf("CPU-bound: used %.1f of %d cores on average (%.0f%% efficiency), system CPU %.0f%%",
+				f.avgCores, p.Cores, f.efficiency*100, f.systemCPU),
+			Suggestion: suggestion,
+		})
+	}
+	if p.Cores > 1 && f.avgCores >= busyCores && f.efficiency < 0.5 && f.systemCPU < saturatedSystemCPU {
+		add(VerdictUnderutilized, analysis.Recommendation{
+			Rule:      "run.underutilized",
+			Component: "CPU",
+			Severity:  analysis.SeverityMedium,
+			Reason: fmt.Sprintf("Used only %.1f of %d cores on average (%.1f at peak)",
+				f.avgCores, p.Cores, f.peakCores),
+			Suggestion: fmt.Sprintf("The job is largely serial. Raise its parallelism (e.g. make -j%d or more test workers); more cores won't help until then.", p.Cores),
+		})
+	}
+	if verdict == "" {
+		add(VerdictWaiting, analysis.Recommendation{
+			Rule:      "run.waiting",
+			Component: "CPU",
+			Severity:  analysis.SeverityLow,
+			Reason: fmt.Sprintf("Mostly waiting: %.1f cores busy on average with little I/O or memory pressure",
+				f.avgCores),
+			Suggestion: "Time is probably spent on the network, locks or sleeps; profile the application itself.",
+		})
+	}
+	return verdict, recs
+}
+
+// Bottlenecks are the shares of a run that point at each resource, each 0-1
+type Bottlenecks struct {
+	// CPU is the CPU efficiency: CPU time / (wall time × cores)
+	CPU float64
+	// IO is the share of wall time stalled on I/O, or in iowait without
+	// pressure stall information
+	IO float64
+	// Memory is the share of wall time stalled on memory
+	Memory float64
+}
+
+// Bottlenecks measures how much each resource held the run back
+func (p *Profile) Bottlenecks() Bottlenecks {
+	f := measure(p)
+	return Bottlenecks{CPU: f.efficiency, IO: f.ioShare, Memory: f.memShare}
+}
@@ -0,0 +1,165 @@
+package profile
+
+import (
+	"math"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/xmarkclx/bottleneck-check/analysis"
+	"github.com/xmarkclx/bottleneck-check/metrics"
+)
+
+// run describes a synthetic 100-second run
+type run struct {
+	cores      int
+	avgCores   float64 // cores the command kept busy
+	systemCPU  float64 // percent
+	iowait     float64 // percent
+	memory     float64 // percent of RAM at the peak
+	swapGrowth uint64
+	// Shares of the run stalled on I/O and memory; without psi the profile
+	// has no pressure stall information
+	psi               bool
+	ioStall, memStall float64
+}
+
+func (r run) profile() *Profile {
+	const wall = 100 * time.Second
+	const total = 16 << 30
+	p := &Profile{
+		Wall:        wall,
+		Cores:       r.cores,
+		MemoryTotal: total,
+		UserTime:    time.Duration(r.avgCores * float64(wall)),
+	}
+	if r.psi {
+		p.Pressure = &metrics.Pressure{
+			IO:     metrics.Stall{Some: time.Duration(r.ioStall * float64(wall))},
+			Memory: metrics.Stall{Some: time.Duration(r.memStall * float64(wall))},
+		}
+	}
+	for i := 0; i < 10; i++ {
+		p.Samples = append(p.Samples, Sample{
+			Cores:      r.avgCores,
+			SystemCPU:  r.systemCPU,
+			Iowait:     r.iowait,
+			MemoryUsed: uint64(r.memory / 100 * total),
+			SwapUsed:   r.swapGrowth * uint64(i) / 9,
+		})
+	}
+	return p
+}
+
+func TestJudge(t *testing.T) {
+	tests := []struct {
+		name    string
+		run     run
+		verdict Verdict
+		// rules and severities of the recommendations, in order
+		rules      []string
+		severities []analysis.Severity
+	}{
+		{
+			"memory before I/O before CPU",
+			run{cores: 8, avgCores: 2, systemCPU: 95, psi: true, ioStall: 0.4, memStall: 0.3},
+			VerdictMemoryBound,
+			[]string{"run.memory_bound", "run.io_bound", "run.cpu_bound"},
+			[]analysis.Severity{analysis.SeverityHigh, analysis.SeverityHigh, analysis.SeverityMedium},
+		},
+		{
+			"I/O before CPU",
+			run{cores: 8, avgCores: 2, systemCPU: 95, psi: true, ioStall: 0.4},
+			VerdictIOBound,
+			[]string{"run.io_bound", "run.cpu_bound"},
+			[]analysis.Severity{analysis.SeverityHigh, analysis.SeverityMedium},
+		},
+		{
+			"swap growth",
+			run{cores: 8, avgCores: 7, systemCPU: 90, swapGrowth: 1 << 30},
+			VerdictMemoryBound,
+			[]string{"run.memory_bound", "run.cpu_bound"},
+			[]analysis.Severity{analysis.SeverityCritical, analysis.SeverityHigh},
+		},
+		{
+			"RAM full",
+			run{cores: 8, avgCores: 0.2, systemCPU: 10, memory: 97},
+			VerdictMemoryBound,
+			[]string{"run.memory_bound"},
+			[]analysis.Severity{analysis.SeverityHigh},
+		},
+		{
+			"CPU-bound by itself",
+			run{cores: 8, avgCores: 7, systemCPU: 90, psi: true, ioStall: 0.4},
+			VerdictCPUBound,
+			[]string{"run.cpu_bound"},
+			[]analysis.Severity{analysis.SeverityHigh},
+		},
+		{
+			"CPU saturated by others",
+			run{cores: 8, avgCores: 2, systemCPU: 95},
+			VerdictCPUBound,
+			[]string{"run.cpu_bound"},
+			[]analysis.Severity{analysis.SeverityMedium},
+		},
+		{
+			"iowait without pressure stall information",
+			run{cores: 8, avgCores: 1, systemCPU: 20, iowait: 30},
+			VerdictIOBound,
+			[]string{"run.io_bound", "run.underutilized"},
+			[]analysis.Severity{analysis.SeverityHigh, analysis.SeverityMedium},
+		},
+		{
+			"underutilized",
+			run{cores: 8, avgCores: 1, systemCPU: 20},
+			VerdictUnderutilized,
+			[]string{"run.underutilized"},
+			[]analysis.Severity{analysis.SeverityMedium},
+		},
+		{
+			"one core is not underutilized",
+			run{cores: 1, avgCores: 0.6, systemCPU: 60},
+			VerdictWaiting,
+			[]string{"run.waiting"},
+			[]analysis.Severity{analysis.SeverityLow},
+		},
+		{
+			"waiting",
+			run{cores: 8, avgCores: 0.1, systemCPU: 5, psi: true},
+			VerdictWaiting,
+			[]string{"run.waiting"},
+			[]analysis.Severity{analysis.SeverityLow},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			verdict, recs := judge(tt.run.profile())
+			if verdict != tt.verdict {
+				t.Errorf("verdict = %s, want %s", verdict, tt.verdict)
+			}
+			var rules []string
+			var severities []analysis.Severity
+			for _, rec := range recs {
+				rules = append(rules, rec.Rule)
+				severities = append(severities, rec.Severity)
+			}
+			if !reflect.DeepEqual(rules, tt.rules) || !reflect.DeepEqual(severities, tt.severities) {
+				t.Errorf("recommendations = %v %v, want %v %v", rules, severities, tt.rules, tt.severities)
+			}
+		})
+	}
+}
+
+func TestBottlenecks(t *testing.T) {
+	near := func(a, b float64) bool { return math.Abs(a-b) < 1e-9 }
+
+	// Pressure stall information is preferred over iowait
+	b := run{cores: 4, avgCores: 2, iowait: 30, psi: true, ioStall: 0.1, memStall: 0.05}.profile().Bottlenecks()
+	if !near(b.CPU, 0.5) || !near(b.IO, 0.1) || !near(b.Memory, 0.05) {
+		t.Errorf("Bottlenecks() with pressure = %+v, want {CPU:0.5 IO:0.1 Memory:0.05}", b)
+	}
+	b = run{cores: 4, avgCores: 2, iowait: 30}.profile().Bottlenecks()
+	if !near(b.CPU, 0.5) || !near(b.IO, 0.3) || b.Memory != 0 {
+		t.Errorf("Bottlenecks() without pressure = %+v, want {CPU:0.5 IO:0.3 Memory:0}", b)
+	}
+}
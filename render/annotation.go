@@ -0,0 +1,34 @@
+package render
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/xmarkclx/bottleneck-check/history"
+)
+
+// AnnotationList writes annotations oldest first, numbered to match the
+// marks of MarkLabel
+func AnnotationList(w io.Writer, annotations []history.Annotation) {
+	if len(annotations) == 0 {
+		fmt.Fprintf(w, "%sNo annotations in this range.%s\n", ColorYellow, ColorReset)
+		return
+	}
+	for i, a := range annotations {
+		source := ""
+		if a.Source != "" {
+			source = " (" + a.Source + ")"
+		}
+		fmt.Fprintf(w, "%s%s%s %s  %s%s\n", ColorYellow+ColorBold, MarkLabel(i), ColorReset,
+			a.Time.Local().Format("2006-01-02 15:04:05"), a.Text, source)
+	}
+}
+
+// MarkLabel labels the i-th annotation on a chart: 1 to 9, then *
+func MarkLabel(i int) string {
+	if i < 9 {
+		return strconv.Itoa(i + 1)
+	}
+	return "*"
+}
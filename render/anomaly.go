@@ -0,0 +1,69 @@
+package render
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"time"
+
+	"github.com/xmarkclx/bottleneck-check/anomaly"
+	"github.com/xmarkclx/bottleneck-check/metrics"
+)
+
+// Anomalies writes readings that are unusual for this host, kept apart from
+// upgrade recommendations because they describe a change in behavior
+// rather than a shortage of hardware
+func Anomalies(w io.Writer, anomalies []anomaly.Anomaly) {
+	if len(anomalies) == 0 {
+		return
+	}
+	fmt.Fprintf(w, "\n%s🔎 Unusual For This Host%s\n", ColorBold, ColorReset)
+	fmt.Fprintf(w, "────────────────────────\n")
+	for _, a := range anomalies {
+		name, unit := a.Metric, ""
+		if series, ok := metrics.LookupSeries(a.Metric); ok {
+			name, unit = series.Help, series.Unit
+		}
+		format := FormatValue(unit)
+
+		icon, direction := "📈", "above"
+		if !a.Above() {
+			icon, direction = "📉", "below"
+		}
+		color := ColorYellow
+		if math.Abs(a.Z) >= 5 {
+			color = ColorRed
+		}
+		fmt.Fprintf(w, "%s %s%s%s is %s (%s) — %.1fσ %s normal %s ±%s for this %s\n",
+			icon, color, name, ColorReset, format(a.Value), heldSince(a.Since), math.Abs(a.Z), direction,
+			format(a.Expected.Mean), format(a.Expected.StdDev), basisName(a.Expected.Basis))
+	}
+}
+
+// AnomalyBaseline writes how much history the anomaly baseline was learned from
+func AnomalyBaseline(w io.Writer, b *anomaly.Baseline) {
+	if b == nil {
+		fmt.Fprintf(w, "%sAnomaly baseline:%s learning from recorded history...\n", ColorBlue, ColorReset)
+		return
+	}
+	from, to := b.Span()
+	if from.IsZero() || !b.Ready(metrics.MetricCPUUsage) {
+		fmt.Fprintf(w, "%sAnomaly baseline:%s needs at least a day of recorded history\n", ColorBlue, ColorReset)
+		return
+	}
+	fmt.Fprintf(w, "%sAnomaly baseline:%s learned from %s of history\n", ColorBlue, ColorReset, FormatSpan(to.Sub(from)))
+}
+
+func heldSince(since time.Time) string {
+	return "for " + FormatSpan(time.Since(since))
+}
+
+func basisName(b anomaly.Basis) string {
+	switch b {
+	case anomaly.BasisWeekHour:
+		return "weekday and hour"
+	case anomaly.BasisHour:
+		return "hour"
+	}
+	return "host"
+}
@@ -0,0 +1,115 @@
+package render
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/xmarkclx/bottleneck-check/baseline"
+	"github.com/xmarkclx/bottleneck-check/metrics"
+)
+
+// capacityMetrics are better when higher; every other metric measures load
+var capacityMetrics = map[string]bool{
+	metrics.MetricCPUCores:         true,
+	metrics.MetricMemoryTotalBytes: true,
+	metrics.MetricSwapTotalBytes:   true,
+}
+
+// BaselineSaved confirms a saved baseline
+func BaselineSaved(w io.Writer, p *baseline.Profile) {
+	fmt.Fprintf(w, "%s✅ Saved baseline '%s'%s\n", ColorGreen, p.Name, ColorReset)
+	profileWindow(w, "Window", p)
+	fmt.Fprintf(w, "  %d recommendation(s) raised during the window\n", len(p.Findings))
+}
+
+// BaselineDiff writes metric deltas and how recommendations changed between
+// a saved baseline and another window
+func BaselineDiff(w io.Writer, c *baseline.Comparison) {
+	fmt.Fprintf(w, "%s%s📐 Baseline Diff: %s%s\n", ColorBold, ColorCyan, c.Base.Name, ColorReset)
+	fmt.Fprintf(w, "═══════════════════════════════════\n")
+	profileWindow(w, "Baseline", c.Base)
+	profileWindow(w, "Current ", c.Current)
+
+	fmt.Fprintf(w, "\n%s📊 Metrics%s\n", ColorBold, ColorReset)
+	fmt.Fprintf(w, "──────────\n")
+	fmt.Fprintf(w, "%-20s %-32s %s\n", "Metric", "Avg (baseline → current)", "P95 (baseline → current)")
+	for _, d := range c.Metrics {
+		format := FormatValue(d.Unit)
+		avg, p95 := d.Change()
+		better := capacityMetrics[d.Metric]
+		fmt.Fprintf(w, "%-20s %s %s\n", d.Metric,
+			deltaCell(format, d.Base.Avg, d.Current.Avg, avg, better, 32),
+			deltaCell(format, d.Base.P95, d.Current.P95, p95, better, 0))
+	}
+
+	fmt.Fprintf(w, "\n%s💡 Recommendations%s\n", ColorBold, ColorReset)
+	fmt.Fprintf(w, "──────────────────\n")
+	if len(c.Resolved)+len(c.Appeared)+len(c.Persisting) == 0 {
+		fmt.Fprintf(w, "%sNo recommendations in either window.%s\n", ColorGreen, ColorReset)
+		return
+	}
+	for _, f := range c.Resolved {
+		fmt.Fprintf(w, "%s✅ Resolved%s  %s%-8s%s %s — %s\n", ColorGreen, ColorReset,
+			SeverityColor(f.Severity), f.Severity, ColorReset, f.Rule, f.Reason)
+	}
+	for _, f := range c.Appeared {
+		fmt.Fprintf(w, "%s🆕 Appeared%s  %s%-8s%s %s — %s\n", ColorRed, ColorReset,
+			SeverityColor(f.Severity), f.Severity, ColorReset, f.Rule, f.Reason)
+	}
+	for _, p := range c.Persisting {
+		fmt.Fprintf(w, "%s↔  Persists%s  %s%-8s%s %s — active %.0f%% → %.0f%% of the time",
+			ColorYellow, ColorReset, SeverityColor(p.Current.Severity), p.Current.Severity, ColorReset,
+			p.Current.Rule, p.Base.Share*100, p.Current.Share*100)
+		if p.Base.Severity != p.Current.Severity {
+			fmt.Fprintf(w, " (was %s)", p.Base.Severity)
+		}
+		fmt.Fprintln(w)
+	}
+}
+
+// BaselineList writes the names of saved baselines
+func BaselineList(w io.Writer, profiles []*baseline.Profile) {
+	if len(profiles) == 0 {
+		fmt.Fprintf(w, "No baselines saved yet. Use 'bottleneck-check baseline save <name>'.\n")
+		return
+	}
+	for _, p := range profiles {
+		fmt.Fprintf(w, "%-20s %s → %s  %d samples, %d recommendation(s)\n", p.Name,
+			p.From.Format("2006-01-02 15:04"), p.To.Format("2006-01-02 15:04"), p.Samples, len(p.Findings))
+	}
+}
+
+func profileWindow(w io.Writer, label string, p *baseline.Profile) {
+	fmt.Fprintf(w, "%s: %s → %s (%s, %d samples)\n", label,
+		p.From.Format("2006-01-02 15:04"), p.To.Format("2006-01-02 15:04"), p.Source, p.Samples)
+}
+
+// deltaCell writes "base → current ▲change" padded to width, with the change
+// colored green when it is an improvement
+func deltaCell(format func(float64) string, base, current, change float64, higherIsBetter bool, width int) string {
+	arrow := "="
+	switch {
+	case change > 0:
+		arrow = "▲"
+	case change < 0:
+		arrow = "▼"
+	}
+	color := ""
+	if change != 0 {
+		color = ColorRed
+		if (change > 0) == higherIsBetter {
+			color = ColorGreen
+		}
+	}
+	text := fmt.Sprintf("%s → %s ", format(base), format(current))
+	delta := arrow
+	if change != 0 {
+		delta += format(math.Abs(change))
+	}
+	// Pad by visible width, since color codes don't take up columns
+	pad := max(width-utf8.RuneCountInString(text+delta), 0)
+	return text + color + delta + ColorReset + strings.Repeat(" ", pad)
+}
@@ -0,0 +1,161 @@
+package render
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"strings"
+	"time"
+)
+
+// sparkBlocks are the eighth-height blocks used by Sparkline
+var sparkBlocks = []rune("▁▂▃▄▅▆▇█")
+
+// Sparkline draws values as a row of block characters at most width wide,
+// scaled so that top fills a whole cell. When there are more values than
+// columns, each column shows the highest value it covers so peaks stay
+// visible. A top of zero scales to the largest value.
+func Sparkline(values []float64, width int, top float64) string {
+	if len(values) == 0 || width <= 0 {
+		return ""
+	}
+	columns := resample(values, width)
+	if top <= 0 {
+		for _, v := range columns {
+			top = max(top, v)
+		}
+	}
+
+	var b strings.Builder
+	for _, v := range columns {
+		level := 0
+		if top > 0 {
+			level = int(v / top * float64(len(sparkBlocks)-1))
+		}
+		level = min(max(level, 0), len(sparkBlocks)-1)
+		b.WriteRune(sparkBlocks[level])
+	}
+	return b.String()
+}
+
+// resample reduces values to at most width columns, keeping each column's
+// maximum
+func resample(values []float64, width int) []float64 {
+	if len(values) <= width {
+		return values
+	}
+	columns := make([]float64, width)
+	for i := range columns {
+		lo, hi := i*len(values)/width, (i+1)*len(values)/width
+		for _, v := range values[lo:hi] {
+			columns[i] = max(columns[i], v)
+		}
+	}
+	return columns
+}
+
+// brailleDots are the bits of a braille cell's dots, by row from the top
+// and then column
+var brailleDots = [4][2]rune{{0x01, 0x08}, {0x02, 0x10}, {0x04, 0x20}, {0x40, 0x80}}
+
+// BrailleChart draws values as a filled area chart of braille characters,
+// height rows tall. Each cell holds two values side by side and four levels,
+// so the chart is len(values)/2 cells wide. Values are scaled so that top
+// reaches the top row; NaN values leave a gap.
+func BrailleChart(values []float64, height int, top float64) []string {
+	if height <= 0 {
+		return nil
+	}
+	width := (len(values) + 1) / 2
+	cells := make([][]rune, height)
+	for row := range cells {
+		cells[row] = []rune(strings.Repeat(string(rune(0x2800)), width))
+	}
+
+	dots := height * 4
+	for i, v := range values {
+		if math.IsNaN(v) {
+			continue
+		}
+		level := 0
+		if top > 0 {
+			level = int(math.Round(v / top * float64(dots)))
+		}
+		// Anything above zero shows at least one dot
+		if v > 0 {
+			level = max(level, 1)
+		}
+		for dot := 0; dot < min(level, dots); dot++ {
+			row := height - 1 - dot/4
+			cells[row][i/2] |= brailleDots[3-dot%4][i%2]
+		}
+	}
+
+	lines := make([]string, height)
+	for row := range cells {
+		lines[row] = string(cells[row])
+	}
+	return lines
+}
+
+// Mark labels a moment on a TimeChart's axis, such as an annotation
+type Mark struct {
+	// Column is the index into the chart's values
+	Column int
+	// Label is one cell wide
+	Label string
+}
+
+// TimeChart writes a titled BrailleChart with the scale on the left and the
+// span it covers underneath. values holds two per cell, oldest first, and
+// format labels the scale. marks are drawn on the axis.
+func TimeChart(w io.Writer, title string, values []float64, height int, top float64, span time.Duration, format func(float64) string, marks []Mark) {
+	if top <= 0 {
+		for _, v := range values {
+			if !math.IsNaN(v) {
+				top = max(top, v)
+			}
+		}
+	}
+	latest := "no data"
+	for i := len(values) - 1; i >= 0; i-- {
+		if !math.IsNaN(values[i]) {
+			latest = format(values[i])
+			break
+		}
+	}
+	fmt.Fprintf(w, "%s%s%s %s\n", ColorBold, title, ColorReset, latest)
+
+	labels := map[int]string{0: format(top), height - 1: format(0)}
+	if height >= 3 {
+		labels[(height-1)/2] = format(top / 2)
+	}
+	labelWidth := 0
+	for _, l := range labels {
+		labelWidth = max(labelWidth, len(l))
+	}
+	for row, line := range BrailleChart(values, height, top) {
+		fmt.Fprintf(w, "%*s ┤%s%s%s\n", labelWidth, labels[row], ColorCyan, line, ColorReset)
+	}
+
+	width := (len(values) + 1) / 2
+	start, end := "-"+SpanLabel(span), "now"
+	gap := max(width-len(start)-len(end), 1)
+	axis := make([]string, width)
+	for i := range axis {
+		axis[i] = "─"
+	}
+	for _, m := range marks {
+		if cell := m.Column / 2; cell >= 0 && cell < width {
+			axis[cell] = ColorYellow + ColorBold + m.Label + ColorReset
+		}
+	}
+	fmt.Fprintf(w, "%*s └%s\n", labelWidth, "", strings.Join(axis, ""))
+	fmt.Fprintf(w, "%*s  %s%s%s\n", labelWidth, "", start, strings.Repeat(" ", gap), end)
+}
+
+// SpanLabel is FormatSpan without a trailing zero unit, e.g. "1d" rather
+// than "1d 0h"
+func SpanLabel(d time.Duration) string {
+	return strings.TrimSuffix(strings.TrimSuffix(FormatSpan(d), " 0h"), " 0m")
+}
@@ -0,0 +1,60 @@
+package render
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/xmarkclx/bottleneck-check/internal/units"
+	"github.com/xmarkclx/bottleneck-check/profile"
+)
+
+// RunComparison writes an A/B comparison of two profiled runs
+func RunComparison(w io.Writer, c *profile.Comparison) {
+	fmt.Fprintf(w, "%s%s🆚 Run Comparison%s\n", ColorBold, ColorCyan, ColorReset)
+	fmt.Fprintf(w, "═════════════════\n")
+	fmt.Fprintf(w, "A: %s  (%s)\n", strings.Join(c.A.Command, " "), c.A.Start.Format("2006-01-02 15:04:05"))
+	fmt.Fprintf(w, "B: %s  (%s)\n\n", strings.Join(c.B.Command, " "), c.B.Start.Format("2006-01-02 15:04:05"))
+
+	fmt.Fprintf(w, "%-18s %12s %12s  %s\n", "", "A", "B", "Change")
+	for _, d := range c.Measures {
+		comparisonRow(w, d)
+	}
+
+	fmt.Fprintf(w, "\n%s🧭 Bottleneck%s (share of the run)\n", ColorBold, ColorReset)
+	fmt.Fprintf(w, "─────────────\n")
+	for _, d := range c.Bottlenecks {
+		comparisonRow(w, d)
+	}
+	fmt.Fprintf(w, "%-18s %12s %12s\n", "Verdict", c.A.Verdict, c.B.Verdict)
+	fmt.Fprintf(w, "\n%s%s%s\n", ColorBold, c.Shift(), ColorReset)
+}
+
+func comparisonRow(w io.Writer, d profile.Delta) {
+	format := func(v float64) string { return fmt.Sprintf("%.2fs", v) }
+	change := ""
+	switch d.Unit {
+	case profile.UnitBytes:
+		format = units.Bytes
+	case profile.UnitShare:
+		format = func(v float64) string { return fmt.Sprintf("%.0f%%", v*100) }
+		change = fmt.Sprintf("%+.0f pts", d.Change()*100)
+	}
+	if change == "" {
+		switch {
+		case d.A != 0:
+			change = fmt.Sprintf("%+.1f%%", d.Change()/d.A*100)
+		case d.B != 0:
+			change = "new"
+		}
+	}
+
+	color := ""
+	if d.Change() != 0 {
+		color = ColorRed
+		if d.Improved() {
+			color = ColorGreen
+		}
+	}
+	fmt.Fprintf(w, "%-18s %12s %12s  %s%s%s\n", d.Name, format(d.A), format(d.B), color, change, ColorReset)
+}
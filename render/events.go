@@ -0,0 +1,123 @@
+package render
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/xmarkclx/bottleneck-check/analysis"
+	"github.com/xmarkclx/bottleneck-check/events"
+	"github.com/xmarkclx/bottleneck-check/history"
+	"github.com/xmarkclx/bottleneck-check/metrics"
+)
+
+// eventIcons mark each kind of transition in the timeline
+var eventIcons = map[analysis.TransitionKind]string{
+	analysis.TransitionRaised:      "▲",
+	analysis.TransitionEscalated:   "⇑",
+	analysis.TransitionDeescalated: "⇓",
+	analysis.TransitionResolved:    "✓",
+}
+
+// SeverityStrip draws one block per column, colored by the worst severity
+// active in it, and a dot where nothing was
+func SeverityStrip(columns []analysis.Severity) string {
+	var b strings.Builder
+	for _, s := range columns {
+		if s == "" {
+			b.WriteString(ColorGreen + "·" + ColorReset)
+			continue
+		}
+		block := "▄"
+		if s.Rank() >= analysis.SeverityHigh.Rank() {
+			block = "█"
+		}
+		b.WriteString(SeverityColor(s) + block + ColorReset)
+	}
+	return b.String()
+}
+
+// Timeline writes events and annotations newest first, under a heading for
+// each day
+func Timeline(w io.Writer, log []events.Event, annotations []history.Annotation) {
+	if len(log) == 0 && len(annotations) == 0 {
+		fmt.Fprintf(w, "  No recommendations raised yet.\n")
+		return
+	}
+	day := ""
+	heading := func(t time.Time) {
+		if d := t.Format("Mon 2 Jan 2006"); d != day {
+			if day != "" {
+				fmt.Fprintln(w)
+			}
+			day = d
+			fmt.Fprintf(w, "%s%s%s\n", ColorBold, day, ColorReset)
+		}
+	}
+	i, j := len(log)-1, len(annotations)-1
+	for i >= 0 || j >= 0 {
+		if j >= 0 && (i < 0 || annotations[j].Time.After(log[i].Time)) {
+			a := annotations[j]
+			j--
+			heading(a.Time.Local())
+			fmt.Fprintf(w, "  %s %s✎ %-8s%s %s\n", a.Time.Local().Format("15:04:05"), ColorCyan, "NOTE", ColorReset, a.Text)
+			continue
+		}
+		e := log[i]
+		i--
+		local := e.Time.Local()
+		heading(local)
+		fmt.Fprintf(w, "  %s %s%s %-8s%s %s: %s\n", local.Format("15:04:05"),
+			SeverityColor(e.Severity), eventIcons[e.Kind], e.Severity, ColorReset, e.Component, e.Reason)
+		if detail := eventDetail(e); detail != "" {
+			fmt.Fprintf(w, "             %s\n", detail)
+		}
+	}
+}
+
+// eventDetail describes how an event changed things and the peaks seen
+// since the recommendation was raised
+func eventDetail(e events.Event) string {
+	var parts []string
+	switch e.Kind {
+	case analysis.TransitionEscalated:
+		parts = append(parts, fmt.Sprintf("up from %s", e.Previous))
+	case analysis.TransitionDeescalated:
+		parts = append(parts, fmt.Sprintf("down from %s", e.Previous))
+	case analysis.TransitionResolved:
+		parts = append(parts, "resolved after "+FormatSpan(e.Time.Sub(e.Since)))
+		if e.PeakSeverity != "" && e.PeakSeverity != e.Severity {
+			parts = append(parts, fmt.Sprintf("peaked at %s", e.PeakSeverity))
+		}
+	}
+	if e.Kind != analysis.TransitionRaised {
+		parts = append(parts, peaks(e.Peak)...)
+	}
+	return strings.Join(parts, " · ")
+}
+
+// peaks formats the peak readings that mean something on their own,
+// leaving out sizes and counts that don't change
+func peaks(peak map[string]float64) []string {
+	names := make([]string, 0, len(peak))
+	for name := range peak {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var out []string
+	for _, name := range names {
+		series, ok := metrics.LookupSeries(name)
+		switch {
+		case ok && (series.Unit == "percent" || series.Unit == "bytes" && !strings.HasSuffix(name, "total_bytes")):
+			out = append(out, fmt.Sprintf("peak %s %s", name, FormatValue(series.Unit)(peak[name])))
+		case ok && strings.HasPrefix(name, "cpu.load"):
+			out = append(out, fmt.Sprintf("peak %s %.2f", name, peak[name]))
+		case !ok && name == "rss_bytes":
+			out = append(out, fmt.Sprintf("peak RSS %s", FormatValue("bytes")(peak[name])))
+		}
+	}
+	return out
+}
@@ -0,0 +1,216 @@
+package render
+
+import (
+	"fmt"
+	"io"
+	"math/bits"
+	"os"
+	"unicode/utf8"
+
+	"golang.org/x/term"
+)
+
+// ColorMode chooses when output is colored
+type ColorMode string
+
+const (
+	// ColorAuto colors output on a terminal, unless NO_COLOR is set or
+	// TERM is dumb
+	ColorAuto ColorMode = "auto"
+	// ColorAlways colors output even when it goes to a file or pipe
+	ColorAlways ColorMode = "always"
+	// ColorNever never colors output
+	ColorNever ColorMode = "never"
+)
+
+// ParseColorMode accepts auto, always or never
+func ParseColorMode(s string) (ColorMode, error) {
+	switch mode := ColorMode(s); mode {
+	case ColorAuto, ColorAlways, ColorNever:
+		return mode, nil
+	}
+	return "", fmt.Errorf("invalid color mode %q (want auto, always or never)", s)
+}
+
+// Profile is what an output can show. Renderers always lay out the same
+// text, with ANSI styles and Unicode symbols; NewWriter or Adapt strips what
+// the output can't show, so every screen has a plain form for free.
+type Profile struct {
+	// Color keeps ANSI colors and styles
+	Color bool
+	// Unicode keeps emoji, box drawing and chart symbols; without it output
+	// is plain ASCII
+	Unicode bool
+}
+
+// Full is the profile of a capable terminal: output passes unchanged
+var Full = Profile{Color: true, Unicode: true}
+
+// Plain is the profile for dumb terminals: no styles and ASCII only
+var Plain = Profile{}
+
+// Detect works out the profile of an output. Colors need a terminal that
+// isn't dumb, with NO_COLOR unset, unless mode overrides it. Output stays
+// Unicode except on dumb terminals and Windows consoles without escape
+// sequence support, which also can't show the symbols. Files and pipes
+// keep Unicode but get no colors by default, so logs stay readable.
+func Detect(f *os.File, mode ColorMode) Profile {
+	tty := term.IsTerminal(int(f.Fd()))
+	dumb := os.Getenv("TERM") == "dumb"
+	capable := !dumb && (!tty || EnableVirtualTerminal(f))
+
+	p := Profile{Unicode: capable}
+	switch mode {
+	case ColorAlways:
+		p.Color = true
+	case ColorNever:
+		p.Color = false
+	default:
+		p.Color = tty && capable && os.Getenv("NO_COLOR") == ""
+	}
+	return p
+}
+
+// Adapt converts text laid out for a full terminal to the profile
+func (p Profile) Adapt(s string) string {
+	if p == Full {
+		return s
+	}
+	a := adapter{profile: p}
+	out, rest := a.convert(nil, []byte(s))
+	return string(append(out, rest...))
+}
+
+// NewWriter returns a writer that adapts everything written through it to
+// the profile, or w itself when nothing needs adapting
+func NewWriter(w io.Writer, p Profile) io.Writer {
+	if p == Full {
+		return w
+	}
+	return &adaptWriter{w: w, adapter: adapter{profile: p}}
+}
+
+type adaptWriter struct {
+	w io.Writer
+	adapter
+	// pending holds an escape sequence or rune cut off at the end of the
+	// last write
+	pending []byte
+	buf     []byte
+}
+
+func (aw *adaptWriter) Write(p []byte) (int, error) {
+	in := p
+	if len(aw.pending) > 0 {
+		in = append(aw.pending, p...)
+	}
+	out, rest := aw.convert(aw.buf[:0], in)
+	aw.pending = append(aw.pending[:0:0], rest...)
+	aw.buf = out
+	if _, err := aw.w.Write(out); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// adapter strips styles and transliterates symbols
+type adapter struct {
+	profile Profile
+	// skipSpaces drops the spaces after a removed emoji
+	skipSpaces bool
+}
+
+// convert appends the adapted form of src to dst and returns an incomplete
+// escape sequence or rune at the end of src, to be completed by more input
+func (a *adapter) convert(dst, src []byte) (out, rest []byte) {
+	for i := 0; i < len(src); {
+		c := src[i]
+		if a.skipSpaces && c != ' ' {
+			a.skipSpaces = false
+		}
+		switch {
+		case c == 0x1b:
+			n := escapeLength(src[i:])
+			if n == 0 {
+				return dst, src[i:]
+			}
+			if a.profile.Color || src[i+n-1] != 'm' || src[i+1] != '[' {
+				dst = append(dst, src[i:i+n]...)
+			}
+			i += n
+		case c == ' ' && a.skipSpaces:
+			i++
+		case c < utf8.RuneSelf || a.profile.Unicode:
+			dst = append(dst, c)
+			i++
+		default:
+			if !utf8.FullRune(src[i:]) {
+				return dst, src[i:]
+			}
+			r, size := utf8.DecodeRune(src[i:])
+			i += size
+			ascii, decoration := transliterate(r)
+			dst = append(dst, ascii...)
+			a.skipSpaces = decoration
+		}
+	}
+	return dst, nil
+}
+
+// escapeLength is the length of the escape sequence at the start of b, or
+// 0 if it is incomplete
+func escapeLength(b []byte) int {
+	if len(b) < 2 {
+		return 0
+	}
+	if b[1] != '[' {
+		return 2
+	}
+	// A control sequence ends with a byte from @ to ~
+	for i := 2; i < len(b); i++ {
+		if b[i] >= 0x40 && b[i] <= 0x7e {
+			return i + 1
+		}
+	}
+	return 0
+}
+
+// asciiSymbols are the ASCII forms of the symbols renderers use
+var asciiSymbols = map[rune]string{
+	'─': "-", '═': "=", '│': "|", '┤': "|", '└': "+", '┬': "+", '┴': "+",
+	'→': "->", '←': "<-", '↔': "<->", '↑': "^", '↓': "v", '⇑': "^", '⇓': "v",
+	'▲': "^", '▼': "v", '•': "*", '●': "*", '·': ".", '…': "...",
+	'—': "-", '–': "-", '×': "x", '±': "+/-", 'σ': "sd", '✓': "+", '✎': "*",
+	'█': "#", '▁': "_", '▂': ".", '▃': "-", '▄': "=", '▅': "+", '▆': "*", '▇': "%",
+	'✅': "OK", '®': "(R)", '™': "(TM)",
+}
+
+// transliterate returns the ASCII form of a rune, and whether it is a
+// decorative emoji, which is dropped along with the spaces after it
+func transliterate(r rune) (string, bool) {
+	if s, ok := asciiSymbols[r]; ok {
+		return s, false
+	}
+	switch {
+	case r >= 0x2800 && r <= 0x28ff:
+		return brailleASCII(r), false
+	case r >= 0x1f000, r == 0xfe0f, r == '⚠', r == '⏱':
+		return "", true
+	}
+	return "?", false
+}
+
+// brailleASCII shades a braille chart cell by how many of its dots are set
+func brailleASCII(r rune) string {
+	switch dots := bits.OnesCount(uint(r - 0x2800)); {
+	case dots == 0:
+		return " "
+	case dots <= 2:
+		return "."
+	case dots <= 4:
+		return ":"
+	case dots <= 6:
+		return "|"
+	}
+	return "#"
+}
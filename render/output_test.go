@@ -0,0 +1,62 @@
+package render
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestAdapt(t *testing.T) {
+	const styled = "\x1b[1m🔧 Configuration\x1b[0m"
+	tests := []struct {
+		name    string
+		profile Profile
+		in      string
+		want    string
+	}{
+		{"full is unchanged", Full, styled + " █▁ ⣿", styled + " █▁ ⣿"},
+		{"plain strips styles and emoji", Plain, styled, "Configuration"},
+		{"color keeps styles", Profile{Color: true}, styled, "\x1b[1mConfiguration\x1b[0m"},
+		{"unicode keeps symbols", Profile{Unicode: true}, styled + " → 5%", "🔧 Configuration → 5%"},
+		{"emoji with a variation selector", Plain, "⚠️  High load", "High load"},
+		{"symbols", Plain, "CPU ▲ 5% → 10% … ✓", "CPU ^ 5% -> 10% ... +"},
+		{"braille is shaded", Plain, "⠀⠁⠇⠿⣿", " .:|#"},
+		{"unknown runes", Plain, "naïve", "na?ve"},
+		{"other escapes are kept", Plain, "\x1b[2J\x1b[1;1Hhi", "\x1b[2J\x1b[1;1Hhi"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.profile.Adapt(tt.in); got != tt.want {
+				t.Errorf("Adapt(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+// TestWriterSplitWrites writes one byte at a time, so every escape sequence
+// and multi-byte rune is split across writes
+func TestWriterSplitWrites(t *testing.T) {
+	const in = "\x1b[31m⚠️  Memory\x1b[0m │ ▲ 85% → naïve ⣿\n"
+	for _, p := range []Profile{Plain, {Color: true}, {Unicode: true}} {
+		var buf bytes.Buffer
+		w := NewWriter(&buf, p)
+		for i := 0; i < len(in); i++ {
+			if n, err := w.Write([]byte{in[i]}); n != 1 || err != nil {
+				t.Fatalf("Write() = %d, %v", n, err)
+			}
+		}
+		if got, want := buf.String(), p.Adapt(in); got != want {
+			t.Errorf("profile %+v: wrote %q, want %q", p, got, want)
+		}
+	}
+}
+
+func TestParseColorMode(t *testing.T) {
+	for _, s := range []string{"auto", "always", "never"} {
+		if mode, err := ParseColorMode(s); err != nil || string(mode) != s {
+			t.Errorf("ParseColorMode(%q) = %q, %v", s, mode, err)
+		}
+	}
+	if _, err := ParseColorMode("sometimes"); err == nil {
+		t.Error(`ParseColorMode("sometimes") succeeded`)
+	}
+}
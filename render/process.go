@@ -0,0 +1,48 @@
+package render
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/xmarkclx/bottleneck-check/analysis"
+	"github.com/xmarkclx/bottleneck-check/metrics"
+)
+
+// processNameWidth is the width of the name column in process tables
+const processNameWidth = 24
+
+// TopProcesses writes up to limit processes as a table, in the order given
+func TopProcesses(w io.Writer, procs []metrics.ProcessInfo, limit int) {
+	if len(procs) == 0 {
+		fmt.Fprintf(w, "  No process information available.\n")
+		return
+	}
+	fmt.Fprintf(w, "  %s%7s  %-*s %7s %9s%s\n", ColorBold, "PID", processNameWidth, "NAME", "CPU", "MEMORY", ColorReset)
+	for _, p := range procs[:min(len(procs), limit)] {
+		name := []rune(p.Name)
+		if len(name) > processNameWidth {
+			name = append(name[:processNameWidth-1], '…')
+		}
+		fmt.Fprintf(w, "  %7d  %-*s %6.1f%% %9s\n", p.PID, processNameWidth, string(name), p.CPUPercent, FormatBytes(p.RSS))
+	}
+}
+
+// FormatBytes writes a size in GB, or MB below a gigabyte
+func FormatBytes(b uint64) string {
+	if b < gib {
+		return fmt.Sprintf("%.0fMB", float64(b)/(1024*1024))
+	}
+	return fmt.Sprintf("%.2fGB", float64(b)/gib)
+}
+
+// RecommendationList writes recommendations most severe first, without the
+// headings and tips of Recommendations
+func RecommendationList(w io.Writer, recommendations []analysis.Recommendation) {
+	groups := analysis.GroupBySeverity(recommendations)
+	for _, severity := range analysis.Severities {
+		for _, rec := range groups[severity] {
+			fmt.Fprintf(w, "%s• %s: %s%s%s\n", SeverityColor(rec.Severity), severity, rec.Reason, ColorReset, heldFor(rec))
+			fmt.Fprintf(w, "  → %s\n", rec.Suggestion)
+		}
+	}
+}
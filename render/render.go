@@ -0,0 +1,316 @@
+// Package render formats snapshots and recommendations as colored terminal
+// text. Every function writes to the io.Writer it is given and never clears
+// the screen or reads input, so output can be sent to files or buffers.
+package render
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/xmarkclx/bottleneck-check/analysis"
+	"github.com/xmarkclx/bottleneck-check/metrics"
+)
+
+// ANSI color codes
+const (
+	ColorReset  = "\033[0m"
+	ColorRed    = "\033[31m"
+	ColorYellow = "\033[33m"
+	ColorGreen  = "\033[32m"
+	ColorBlue   = "\033[34m"
+	ColorPurple = "\033[35m"
+	ColorCyan   = "\033[36m"
+	ColorWhite  = "\033[37m"
+	ColorBold   = "\033[1m"
+)
+
+const gib = 1024 * 1024 * 1024
+
+// SeverityColor returns the color used for a severity
+func SeverityColor(s analysis.Severity) string {
+	switch s {
+	case analysis.SeverityCritical:
+		return ColorRed
+	case analysis.SeverityLow:
+		return ColorGreen
+	}
+	return ColorYellow
+}
+
+// QuickStatus writes the one-line CPU/Load/Memory/Swap summary. With two or
+// more recent samples, each value is followed by a sparkline of its trend.
+func QuickStatus(w io.Writer, m *metrics.SystemMetrics, recent []metrics.SystemMetrics) {
+	// CPU Status with color coding
+	cpuColor := ColorGreen
+	if m.CPUUsage > 80 {
+		cpuColor = ColorRed
+	} else if m.CPUUsage > 60 {
+		cpuColor = ColorYellow
+	}
+
+	// Memory Status with color coding
+	memUsagePercent := m.MemoryPercent()
+	memColor := ColorGreen
+	if memUsagePercent > 90 {
+		memColor = ColorRed
+	} else if memUsagePercent > 75 {
+		memColor = ColorYellow
+	}
+
+	// Load Average Status
+	loadColor := ColorGreen
+	if m.LoadAverage[0] > float64(m.CPUCores)*1.5 {
+		loadColor = ColorRed
+	} else if m.LoadAverage[0] > float64(m.CPUCores) {
+		loadColor = ColorYellow
+	}
+
+	// Display compact status
+	fmt.Fprintf(w, "📊 %sQuick Status%s\n", ColorBold, ColorReset)
+	fmt.Fprintf(w, "─────────────\n")
+	trend := quickTrends(m, recent)
+	fmt.Fprintf(w, "CPU: %s%.1f%%%s%s | Load: %s%.2f%s%s | Memory: %s%.1f%%%s%s",
+		cpuColor, m.CPUUsage, ColorReset, trend.cpu,
+		loadColor, m.LoadAverage[0], ColorReset, trend.load,
+		memColor, memUsagePercent, ColorReset, trend.memory)
+
+	if m.SwapUsed > 0 {
+		swapGB := float64(m.SwapUsed) / gib
+		swapColor := ColorYellow
+		if swapGB > 2 {
+			swapColor = ColorRed
+		}
+		fmt.Fprintf(w, " | Swap: %s%.1fGB%s%s", swapColor, swapGB, ColorReset, trend.swap)
+	}
+	fmt.Fprintln(w)
+}
+
+// QuickTrendSpan is how much history callers pass to QuickStatus for its
+// sparklines
+const QuickTrendSpan = 5 * time.Minute
+
+// quickTrendWidth is the width of the sparklines in the quick status
+const quickTrendWidth = 8
+
+// quickTrend holds the sparklines shown after each quick status value,
+// each with a leading space, or empty without enough samples
+type quickTrend struct {
+	cpu, load, memory, swap string
+}
+
+func quickTrends(m *metrics.SystemMetrics, recent []metrics.SystemMetrics) quickTrend {
+	if len(recent) < 2 {
+		return quickTrend{}
+	}
+	cpu := make([]float64, len(recent))
+	load := make([]float64, len(recent))
+	memory := make([]float64, len(recent))
+	swap := make([]float64, len(recent))
+	for i := range recent {
+		cpu[i] = recent[i].CPUUsage
+		load[i] = recent[i].LoadAverage[0]
+		memory[i] = recent[i].MemoryPercent()
+		swap[i] = float64(recent[i].SwapUsed)
+	}
+	spark := func(values []float64, top float64) string {
+		return " " + ColorCyan + Sparkline(values, quickTrendWidth, top) + ColorReset
+	}
+	// Load is scaled to the core count, or its peak if higher, so a
+	// saturated CPU fills the sparkline
+	return quickTrend{
+		cpu:    spark(cpu, 100),
+		load:   spark(load, max(float64(m.CPUCores), maxOf(load))),
+		memory: spark(memory, 100),
+		swap:   spark(swap, float64(m.SwapTotal)),
+	}
+}
+
+// CriticalAlerts writes a count of CRITICAL and HIGH recommendations
+func CriticalAlerts(w io.Writer, recommendations []analysis.Recommendation) {
+	criticalCount := 0
+	highCount := 0
+
+	for _, rec := range recommendations {
+		if rec.Severity == analysis.SeverityCritical {
+			criticalCount++
+		} else if rec.Severity == analysis.SeverityHigh {
+			highCount++
+		}
+	}
+
+	if criticalCount > 0 || highCount > 0 {
+		fmt.Fprintf(w, "\n🚨 %sActive Alerts%s\n", ColorBold, ColorReset)
+		fmt.Fprintf(w, "──────────────\n")
+		if criticalCount > 0 {
+			fmt.Fprintf(w, "%s● CRITICAL: %d issue(s) need immediate attention%s\n", ColorRed, criticalCount, ColorReset)
+		}
+		if highCount > 0 {
+			fmt.Fprintf(w, "%s● HIGH: %d issue(s) affecting performance%s\n", ColorYellow, highCount, ColorReset)
+		}
+	} else {
+		fmt.Fprintf(w, "\n✅ %sSystem Status: Good%s\n", ColorGreen, ColorReset)
+	}
+}
+
+// SystemStatus writes the current CPU, memory and GPU status block
+func SystemStatus(w io.Writer, m *metrics.SystemMetrics) {
+	fmt.Fprintf(w, "%s📊 Current System Status%s\n", ColorBold, ColorReset)
+	fmt.Fprintf(w, "─────────────────────────\n")
+
+	// CPU Status
+	fmt.Fprintf(w, "%sCPU:%s %s (%d cores)\n", ColorBlue, ColorReset, m.CPUModel, m.CPUCores)
+	fmt.Fprintf(w, "  Usage: %.1f%%\n", m.CPUUsage)
+	fmt.Fprintf(w, "  Load Average: %.2f, %.2f, %.2f\n", m.LoadAverage[0], m.LoadAverage[1], m.LoadAverage[2])
+
+	// Memory Status
+	fmt.Fprintf(w, "%sMemory:%s %.1fGB used / %.1fGB total (%.1f%%)\n",
+		ColorPurple, ColorReset,
+		float64(m.MemoryUsed)/gib,
+		float64(m.MemoryTotal)/gib,
+		m.MemoryPercent())
+
+	if m.SwapUsed > 0 {
+		fmt.Fprintf(w, "  Swap: %.1fMB used / %.1fMB total\n",
+			float64(m.SwapUsed)/(1024*1024),
+			float64(m.SwapTotal)/(1024*1024))
+	}
+	fmt.Fprintf(w, "  Memory Pressure: %s\n", m.MemPressure)
+
+	// GPU Status
+	if m.GPUModel != "" {
+		fmt.Fprintf(w, "%sGPU:%s %s\n", ColorYellow, ColorReset, m.GPUModel)
+	}
+
+	fmt.Fprintln(w)
+}
+
+// DetailedInfo writes hardware, performance and uptime details
+func DetailedInfo(w io.Writer, m *metrics.SystemMetrics) {
+	fmt.Fprintf(w, "%sSystem Hardware:%s\n", ColorBlue, ColorReset)
+	fmt.Fprintf(w, "  CPU: %s (%d cores)\n", m.CPUModel, m.CPUCores)
+	if m.GPUModel != "" {
+		fmt.Fprintf(w, "  GPU: %s\n", m.GPUModel)
+	}
+	fmt.Fprintf(w, "  Total RAM: %.1fGB\n", float64(m.MemoryTotal)/gib)
+
+	fmt.Fprintf(w, "\n%sPerformance Metrics:%s\n", ColorPurple, ColorReset)
+	fmt.Fprintf(w, "  CPU Usage: %.1f%%\n", m.CPUUsage)
+	fmt.Fprintf(w, "  Load Averages: %.2f (1m), %.2f (5m), %.2f (15m)\n",
+		m.LoadAverage[0], m.LoadAverage[1], m.LoadAverage[2])
+	fmt.Fprintf(w, "  Memory Usage: %.1f%% (%.1fGB used)\n",
+		m.MemoryPercent(), float64(m.MemoryUsed)/gib)
+	if m.SwapUsed > 0 {
+		fmt.Fprintf(w, "  Swap Usage: %.1fGB\n", float64(m.SwapUsed)/gib)
+	}
+	fmt.Fprintf(w, "  Memory Pressure: %s\n", m.MemPressure)
+
+	if m.Uptime > 0 {
+		fmt.Fprintf(w, "\n%sSystem Uptime:%s\n", ColorGreen, ColorReset)
+		fmt.Fprintf(w, "  %s\n", FormatUptime(m.Uptime))
+	}
+}
+
+// HistorySummary writes average and peak usage over recent samples
+func HistorySummary(w io.Writer, samples []metrics.SystemMetrics) {
+	if len(samples) < 2 {
+		return
+	}
+
+	var cpuSum, cpuPeak, memSum, memPeak float64
+	var swapPeak uint64
+	for i := range samples {
+		cpu, mem := samples[i].CPUUsage, samples[i].MemoryPercent()
+		cpuSum += cpu
+		memSum += mem
+		cpuPeak = max(cpuPeak, cpu)
+		memPeak = max(memPeak, mem)
+		swapPeak = max(swapPeak, samples[i].SwapUsed)
+	}
+	n := float64(len(samples))
+	span := samples[len(samples)-1].Timestamp.Sub(samples[0].Timestamp)
+
+	fmt.Fprintf(w, "\n%sRecent History (last %s, %d samples):%s\n", ColorCyan, span.Round(time.Second), len(samples), ColorReset)
+	fmt.Fprintf(w, "  CPU Usage: avg %.1f%%, peak %.1f%%\n", cpuSum/n, cpuPeak)
+	fmt.Fprintf(w, "  Memory Usage: avg %.1f%%, peak %.1f%%\n", memSum/n, memPeak)
+	if swapPeak > 0 {
+		fmt.Fprintf(w, "  Swap Peak: %.1fGB\n", float64(swapPeak)/gib)
+	}
+}
+
+// Recommendations writes recommendations grouped by severity
+func Recommendations(w io.Writer, recommendations []analysis.Recommendation) {
+	if len(recommendations) == 0 {
+		fmt.Fprintf(w, "%s✅ Great! No bottlenecks detected%s\n", ColorGreen, ColorReset)
+		fmt.Fprintf(w, "Your system appears to be running optimally.\n")
+		return
+	}
+
+	fmt.Fprintf(w, "%s🔧 Upgrade Recommendations%s\n", ColorBold, ColorReset)
+	fmt.Fprintf(w, "═══════════════════════════\n\n")
+
+	// Display by priority
+	groups := analysis.GroupBySeverity(recommendations)
+	recommendationGroup(w, "🚨 CRITICAL", groups[analysis.SeverityCritical], ColorRed)
+	recommendationGroup(w, "⚠️  HIGH", groups[analysis.SeverityHigh], ColorYellow)
+	recommendationGroup(w, "📋 MEDIUM", groups[analysis.SeverityMedium], ColorYellow)
+	recommendationGroup(w, "💡 LOW", groups[analysis.SeverityLow], ColorGreen)
+
+	fmt.Fprintf(w, "\n%s💡 Pro Tips:%s\n", ColorBold, ColorReset)
+	fmt.Fprintf(w, "• Run this tool regularly to monitor system performance\n")
+	fmt.Fprintf(w, "• Close unnecessary applications before intensive tasks\n")
+	fmt.Fprintf(w, "• Consider upgrading components in order of severity\n")
+	fmt.Fprintf(w, "• Monitor Activity Monitor for specific resource-heavy processes\n")
+}
+
+func recommendationGroup(w io.Writer, title string, recommendations []analysis.Recommendation, color string) {
+	if len(recommendations) == 0 {
+		return
+	}
+
+	fmt.Fprintf(w, "%s%s%s\n", color, title, ColorReset)
+	fmt.Fprintf(w, "────────────────\n")
+
+	for _, rec := range recommendations {
+		fmt.Fprintf(w, "%s• %s (%s)%s%s\n", SeverityColor(rec.Severity), rec.Component, rec.Reason, ColorReset, heldFor(rec))
+		fmt.Fprintf(w, "  → %s\n", rec.Suggestion)
+		fmt.Fprintln(w)
+	}
+}
+
+// heldFor describes how long a sustained recommendation has been active
+func heldFor(rec analysis.Recommendation) string {
+	if rec.Duration <= 0 {
+		return ""
+	}
+	return fmt.Sprintf(" — for %s", rec.Duration.Round(time.Second))
+}
+
+// FormatUptime converts a duration to a human-readable uptime string
+func FormatUptime(uptime time.Duration) string {
+	days := int(uptime.Hours() / 24)
+	hours := int(uptime.Hours()) % 24
+	minutes := int(uptime.Minutes()) % 60
+
+	if days > 0 {
+		return fmt.Sprintf("%d days, %d hours, %d minutes", days, hours, minutes)
+	} else if hours > 0 {
+		return fmt.Sprintf("%d hours, %d minutes", hours, minutes)
+	} else {
+		return fmt.Sprintf("%d minutes", minutes)
+	}
+}
+
+// CheckResult writes the one-line outcome of a check
+func CheckResult(w io.Writer, worst analysis.Severity, count, exitCode int) {
+	if worst == "" {
+		fmt.Fprintf(w, "%sOK%s: no recommendations (exit %d)\n", ColorGreen, ColorReset, exitCode)
+		return
+	}
+	status, color := "FAIL", SeverityColor(worst)
+	if exitCode == 0 {
+		status, color = "OK", ColorGreen
+	}
+	fmt.Fprintf(w, "%s%s%s: worst severity %s%s%s across %d recommendation(s) (exit %d)\n",
+		color, status, ColorReset, SeverityColor(worst), worst, ColorReset, count, exitCode)
+}
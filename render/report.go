@@ -0,0 +1,134 @@
+package render
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/xmarkclx/bottleneck-check/metrics"
+	"github.com/xmarkclx/bottleneck-check/report"
+)
+
+// Report writes a long-term usage report
+func Report(w io.Writer, r *report.Report) {
+	fmt.Fprintf(w, "%s%s📑 Usage Report%s\n", ColorBold, ColorCyan, ColorReset)
+	fmt.Fprintf(w, "═══════════════\n")
+	fmt.Fprintf(w, "%s → %s | %s resolution | %s of data\n\n",
+		r.From.Format("2006-01-02 15:04"), r.To.Format("2006-01-02 15:04"), r.Resolution, FormatSpan(r.Covered))
+
+	if len(r.Components) == 0 {
+		fmt.Fprintf(w, "%sNo history recorded in this range.%s\n", ColorYellow, ColorReset)
+		return
+	}
+
+	fmt.Fprintf(w, "%s📊 Usage Percentiles%s\n", ColorBold, ColorReset)
+	fmt.Fprintf(w, "────────────────────\n")
+	fmt.Fprintf(w, "%-16s %10s %10s %10s %10s %10s\n", "Component", "Avg", "P50", "P95", "P99", "Max")
+	for _, c := range r.Components {
+		format := FormatValue(c.Unit)
+		d := c.Distribution
+		fmt.Fprintf(w, "%-16s %10s %10s %10s %10s %10s\n", c.Name,
+			format(d.Avg), format(d.P50), format(d.P95), format(d.P99), format(d.Max))
+	}
+
+	fmt.Fprintf(w, "\n%s⏱  Time Above Thresholds%s\n", ColorBold, ColorReset)
+	fmt.Fprintf(w, "────────────────────────\n")
+	for _, c := range r.Components {
+		format := FormatValue(c.Unit)
+		for i, t := range c.Thresholds {
+			name := ""
+			if i == 0 {
+				name = c.Name
+			}
+			fmt.Fprintf(w, "%-16s %s%-9s%s > %-8s %10s (%.1f%%)\n", name,
+				SeverityColor(t.Severity), t.Severity, ColorReset, format(t.Above), FormatSpan(t.Duration), t.Share*100)
+		}
+	}
+
+	fmt.Fprintf(w, "\n%s🕐 Peak Hours%s\n", ColorBold, ColorReset)
+	fmt.Fprintf(w, "─────────────\n")
+	for _, c := range r.Components {
+		hours, ok := r.PeakHours[c.Metric]
+		if !ok {
+			continue
+		}
+		fmt.Fprintf(w, "%-16s", c.Name)
+		for i, h := range hours {
+			if i > 0 {
+				fmt.Fprintf(w, ", ")
+			}
+			fmt.Fprintf(w, "%02d:00 (avg %s)", h.Hour, FormatValue(c.Unit)(h.Avg))
+		}
+		fmt.Fprintln(w)
+	}
+
+	if r.RAM != nil {
+		ram := r.RAM
+		fmt.Fprintf(w, "\n%s💾 RAM Sizing (from p95/p99 usage)%s\n", ColorBold, ColorReset)
+		fmt.Fprintf(w, "──────────────────────────────────\n")
+		fmt.Fprintf(w, "  Installed: %.1fGB\n", ram.InstalledGB)
+		fmt.Fprintf(w, "  P95 need: %.1fGB used + %.1fGB swap\n", ram.P95UsedGB, ram.P95SwapGB)
+		fmt.Fprintf(w, "  P99 need: %.1fGB used + %.1fGB swap\n", ram.P99UsedGB, ram.P99SwapGB)
+		fmt.Fprintf(w, "  %sMinimum: %.0fGB%s (p95 need + 15%% buffer)\n", ColorGreen, ram.MinimumGB, ColorReset)
+		fmt.Fprintf(w, "  %sRecommended: %.0fGB%s (p99 need + 25%% buffer)\n", ColorGreen, ram.RecommendedGB, ColorReset)
+		if ram.Sufficient {
+			fmt.Fprintf(w, "  %s✅ Installed RAM already covers p99 usage with headroom%s\n", ColorGreen, ColorReset)
+		}
+	}
+
+	if len(r.Annotations) > 0 {
+		fmt.Fprintf(w, "\n%s📝 Annotations%s (average usage %s before → after)\n", ColorBold, ColorReset, SpanLabel(report.AnnotationWindow))
+		fmt.Fprintf(w, "──────────────\n")
+		for i, a := range r.Annotations {
+			fmt.Fprintf(w, "%s%s%s %s  %s\n", ColorYellow+ColorBold, MarkLabel(i), ColorReset, a.Time.Local().Format("2006-01-02 15:04"), a.Text)
+			var changes []string
+			for _, c := range []struct{ name, metric string }{{"CPU", metrics.MetricCPUUsage}, {"Memory", metrics.MetricMemoryUsedPercent}} {
+				before, okBefore := a.Before[c.metric]
+				after, okAfter := a.After[c.metric]
+				if okBefore || okAfter {
+					changes = append(changes, fmt.Sprintf("%s %s → %s", c.name, percentOrDash(before, okBefore), percentOrDash(after, okAfter)))
+				}
+			}
+			if len(changes) > 0 {
+				fmt.Fprintf(w, "    %s\n", strings.Join(changes, ", "))
+			}
+		}
+	}
+}
+
+func percentOrDash(v float64, ok bool) string {
+	if !ok {
+		return "–"
+	}
+	return fmt.Sprintf("%.1f%%", v)
+}
+
+// FormatValue formats metric values for display according to their unit
+func FormatValue(unit string) func(float64) string {
+	switch unit {
+	case "percent":
+		return func(v float64) string { return fmt.Sprintf("%.1f%%", v) }
+	case "bytes":
+		return func(v float64) string { return fmt.Sprintf("%.2fGB", v/gib) }
+	}
+	return func(v float64) string { return fmt.Sprintf("%.2f", v) }
+}
+
+// FormatSpan writes a duration compactly, e.g. "3d 4h", "2h 15m" or "40s"
+func FormatSpan(d time.Duration) string {
+	d = d.Round(time.Second)
+	days := int(d.Hours() / 24)
+	hours := int(d.Hours()) % 24
+	minutes := int(d.Minutes()) % 60
+
+	switch {
+	case days > 0:
+		return fmt.Sprintf("%dd %dh", days, hours)
+	case hours > 0:
+		return fmt.Sprintf("%dh %dm", hours, minutes)
+	case minutes > 0:
+		return fmt.Sprintf("%dm", minutes)
+	}
+	return fmt.Sprintf("%ds", int(d.Seconds()))
+}
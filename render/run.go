@@ -0,0 +1,92 @@
+package render
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/xmarkclx/bottleneck-check/analysis"
+	"github.com/xmarkclx/bottleneck-check/internal/units"
+	"github.com/xmarkclx/bottleneck-check/profile"
+)
+
+// timelineWidth is the number of columns in run timeline charts
+const timelineWidth = 60
+
+// RunProfile writes the verdict, timelines, peaks and recommendations of a
+// profiled command
+func RunProfile(w io.Writer, p *profile.Profile) {
+	fmt.Fprintf(w, "\n%s%s⏱  Run Profile%s\n", ColorBold, ColorCyan, ColorReset)
+	fmt.Fprintf(w, "══════════════\n")
+	fmt.Fprintf(w, "Command:   %s\n", strings.Join(p.Command, " "))
+	exitColor := ColorGreen
+	if p.ExitCode != 0 {
+		exitColor = ColorRed
+	}
+	fmt.Fprintf(w, "Wall time: %s | CPU time: %s | Exit code: %s%d%s\n",
+		preciseSpan(p.Wall), preciseSpan(p.CPUTime()), exitColor, p.ExitCode, ColorReset)
+	fmt.Fprintf(w, "Verdict:   %s%s%s (%.1f of %d cores on average, %.0f%% CPU efficiency)\n",
+		ColorBold, p.Verdict.Describe(), ColorReset, p.AverageCores(), p.Cores, p.Efficiency()*100)
+
+	if len(p.Samples) > 1 {
+		fmt.Fprintf(w, "\n%s📈 Timeline%s (%s per sample)\n", ColorBold, ColorReset, p.Interval)
+		fmt.Fprintf(w, "───────────\n")
+		cores, rss, disk, iowait := runSeries(p)
+		timeline(w, "CPU cores", cores, float64(p.Cores), fmt.Sprintf("0-%d", p.Cores))
+		timeline(w, "Memory RSS", rss, 0, "0-"+units.Bytes(maxOf(rss)))
+		timeline(w, "Disk I/O", disk, 0, fmt.Sprintf("0-%.1fMB/s", maxOf(disk)/(1024*1024)))
+		timeline(w, "I/O wait", iowait, 100, "0-100%")
+	}
+
+	fmt.Fprintf(w, "\n%s🔝 Peaks & Totals%s\n", ColorBold, ColorReset)
+	fmt.Fprintf(w, "─────────────────\n")
+	cores, _, disk, _ := runSeries(p)
+	fmt.Fprintf(w, "  Peak CPU:     %.1f cores\n", maxOf(cores))
+	fmt.Fprintf(w, "  Peak memory:  %s RSS (of %s RAM)\n", units.Bytes(float64(p.PeakRSS)), units.Bytes(float64(p.MemoryTotal)))
+	fmt.Fprintf(w, "  Peak disk:    %.1fMB/s\n", maxOf(disk)/(1024*1024))
+	fmt.Fprintf(w, "  Disk total:   read %s, wrote %s\n", units.Bytes(float64(p.ReadBytes)), units.Bytes(float64(p.WriteBytes)))
+	if p.Pressure != nil {
+		fmt.Fprintf(w, "  Stalled on:   CPU %s, I/O %s, memory %s (system-wide)\n",
+			preciseSpan(p.Pressure.CPU.Some), preciseSpan(p.Pressure.IO.Some), preciseSpan(p.Pressure.Memory.Some))
+	}
+
+	fmt.Fprintf(w, "\n%s🔧 Recommendations%s\n", ColorBold, ColorReset)
+	fmt.Fprintf(w, "══════════════════\n\n")
+	groups := analysis.GroupBySeverity(p.Recommendations)
+	recommendationGroup(w, "🚨 CRITICAL", groups[analysis.SeverityCritical], ColorRed)
+	recommendationGroup(w, "⚠️  HIGH", groups[analysis.SeverityHigh], ColorYellow)
+	recommendationGroup(w, "📋 MEDIUM", groups[analysis.SeverityMedium], ColorYellow)
+	recommendationGroup(w, "💡 LOW", groups[analysis.SeverityLow], ColorGreen)
+}
+
+func runSeries(p *profile.Profile) (cores, rss, disk, iowait []float64) {
+	for _, s := range p.Samples {
+		cores = append(cores, s.Cores)
+		rss = append(rss, float64(s.RSS))
+		disk = append(disk, s.ReadRate+s.WriteRate)
+		iowait = append(iowait, s.Iowait)
+	}
+	return cores, rss, disk, iowait
+}
+
+func timeline(w io.Writer, label string, values []float64, top float64, scale string) {
+	fmt.Fprintf(w, "  %-11s %s%s%s %s\n", label, ColorCyan, Sparkline(values, timelineWidth, top), ColorReset, scale)
+}
+
+// preciseSpan is FormatSpan with hundredths of a second for short spans,
+// which matter when comparing quick runs
+func preciseSpan(d time.Duration) string {
+	if d < time.Minute {
+		return fmt.Sprintf("%.2fs", d.Seconds())
+	}
+	return FormatSpan(d)
+}
+
+func maxOf(values []float64) float64 {
+	var m float64
+	for _, v := range values {
+		m = max(m, v)
+	}
+	return m
+}
@@ -0,0 +1,11 @@
+//go:build !windows
+
+package render
+
+import "os"
+
+// EnableVirtualTerminal reports whether f understands escape sequences,
+// which every terminal outside Windows does
+func EnableVirtualTerminal(f *os.File) bool {
+	return true
+}
@@ -0,0 +1,311 @@
+// Package report summarizes recorded history over a date range: usage
+// percentiles, time spent above each severity threshold, peak hours, and
+// RAM sizing based on high-percentile usage rather than a single moment.
+package report
+
+import (
+	"sort"
+	"time"
+
+	"github.com/xmarkclx/bottleneck-check/analysis"
+	"github.com/xmarkclx/bottleneck-check/history"
+	"github.com/xmarkclx/bottleneck-check/internal/stats"
+	"github.com/xmarkclx/bottleneck-check/metrics"
+)
+
+// Options selects the range and limits a report uses
+type Options struct {
+	From time.Time
+	To   time.Time
+	// Resolution overrides the automatic choice of the finest retained data
+	Resolution history.Resolution
+	// Thresholds overrides the severity limits; nil means the defaults
+	Thresholds *analysis.Thresholds
+}
+
+// Distribution describes a metric over the range. Percentiles are taken
+// over the points read; with 1m or 1h data they are percentiles of bucket
+// averages, while Max is always the true peak.
+type Distribution struct {
+	Avg float64 `json:"avg"`
+	P50 float64 `json:"p50"`
+	P95 float64 `json:"p95"`
+	P99 float64 `json:"p99"`
+	Max float64 `json:"max"`
+}
+
+// SeverityTime is how long a metric stayed above a severity level
+type SeverityTime struct {
+	Severity analysis.Severity
+	Above    float64
+	Duration time.Duration
+	Share    float64 // of the covered time, 0-1
+}
+
+// Component summarizes one metric
+type Component struct {
+	Name         string
+	Metric       string
+	Unit         string
+	Points       int
+	Distribution Distribution
+	Thresholds   []SeverityTime
+}
+
+// PeakHour is an hour of the day (local time) and the metric's average in it
+type PeakHour struct {
+	Hour int
+	Avg  float64
+}
+
+// RAMAdvice sizes memory from high-percentile usage
+type RAMAdvice struct {
+	InstalledGB float64
+	P95UsedGB   float64
+	P99UsedGB   float64
+	P95SwapGB   float64
+	P99SwapGB   float64
+	// MinimumGB covers p95 usage plus swap with a 15% buffer
+	MinimumGB float64
+	// RecommendedGB covers p99 usage plus swap with a 25% buffer, and is
+	// never below MinimumGB
+	RecommendedGB float64
+	// Sufficient reports whether installed RAM already covers p99 usage
+	// plus swap with a 25% buffer
+	Sufficient bool
+}
+
+// Report is a per-component usage summary for a date range
+type Report struct {
+	From       time.Time
+	To         time.Time
+	Resolution history.Resolution
+	Covered    time.Duration
+	Components []Component
+	// PeakHours lists the three busiest hours of the day per metric
+	PeakHours map[string][]PeakHour
+	// RAM is nil when no memory data was recorded
+	RAM *RAMAdvice
+	// Annotations made in the range, oldest first
+	Annotations []Annotated
+}
+
+// Annotated is an annotation with the average usage on either side of it,
+// to see whether what it marks changed anything
+type Annotated struct {
+	history.Annotation
+	// Before and After average CPU and memory usage over AnnotationWindow,
+	// keyed by metric; a metric without data in the window is left out
+	Before map[string]float64
+	After  map[string]float64
+}
+
+// AnnotationWindow is how long before and after an annotation usage is
+// averaged
+const AnnotationWindow = 30 * time.Minute
+
+// reportedMetrics are summarized in this order
+var reportedMetrics = []struct{ name, metric string }{
+	{"CPU", metrics.MetricCPUUsage},
+	{"Memory", metrics.MetricMemoryUsedPercent},
+	{"Swap", metrics.MetricSwapUsedBytes},
+	// memory.pressure is the 0/1/2 level estimated from usage and swap, not
+	// the kernel's pressure stall information
+	{"Pressure Level", metrics.MetricMemoryPressure},
+}
+
+const gib = 1024 * 1024 * 1024
+
+// Build reads history from the store and summarizes it
+func Build(store *history.Store, opts Options) (*Report, error) {
+	thresholds := analysis.DefaultThresholds()
+	if opts.Thresholds != nil {
+		thresholds = *opts.Thresholds
+	}
+	res := opts.Resolution
+	if res == "" || res == history.ResolutionAuto {
+		res = store.FinestResolution(opts.From)
+	}
+
+	r := &Report{From: opts.From, To: opts.To, Resolution: res, PeakHours: make(map[string][]PeakHour)}
+	series := make(map[string][]history.Point)
+	for _, rm := range reportedMetrics {
+		points, _, err := store.Query(rm.metric, opts.From, opts.To, res)
+		if err != nil {
+			return nil, err
+		}
+		series[rm.metric] = points
+		if len(points) == 0 {
+			continue
+		}
+
+		weights, covered := pointWeights(points, res)
+		r.Covered = max(r.Covered, covered)
+		desc, _ := metrics.LookupSeries(rm.metric)
+		component := Component{
+			Name:         rm.name,
+			Metric:       rm.metric,
+			Unit:         desc.Unit,
+			Points:       len(points),
+			Distribution: distribution(points),
+		}
+		for _, level := range thresholds.Levels(rm.metric) {
+			var above time.Duration
+			for i, p := range points {
+				if p.Avg > level.Above {
+					above += weights[i]
+				}
+			}
+			component.Thresholds = append(component.Thresholds, SeverityTime{
+				Severity: level.Severity,
+				Above:    level.Above,
+				Duration: above,
+				Share:    float64(above) / float64(covered),
+			})
+		}
+		r.Components = append(r.Components, component)
+
+		if rm.metric == metrics.MetricCPUUsage || rm.metric == metrics.MetricMemoryUsedPercent {
+			r.PeakHours[rm.metric] = peakHours(points, weights, 3)
+		}
+	}
+
+	total, err := installedMemory(store, opts, res)
+	if err != nil {
+		return nil, err
+	}
+	if total > 0 && len(series[metrics.MetricMemoryUsedPercent]) > 0 {
+		r.RAM = ramAdvice(total, series[metrics.MetricMemoryUsedPercent], series[metrics.MetricSwapUsedBytes])
+	}
+
+	annotations, err := store.Annotations(opts.From, opts.To)
+	if err != nil {
+		return nil, err
+	}
+	for _, a := range annotations {
+		annotated := Annotated{Annotation: a, Before: make(map[string]float64), After: make(map[string]float64)}
+		for _, metric := range []string{metrics.MetricCPUUsage, metrics.MetricMemoryUsedPercent} {
+			if avg, ok := average(series[metric], a.Time.Add(-AnnotationWindow), a.Time); ok {
+				annotated.Before[metric] = avg
+			}
+			if avg, ok := average(series[metric], a.Time, a.Time.Add(AnnotationWindow)); ok {
+				annotated.After[metric] = avg
+			}
+		}
+		r.Annotations = append(r.Annotations, annotated)
+	}
+	return r, nil
+}
+
+// average is the mean of the points from from up to to, and false if there
+// are none
+func average(points []history.Point, from, to time.Time) (float64, bool) {
+	var sum float64
+	var n int
+	for _, p := range points {
+		if !p.Time.Before(from) && p.Time.Before(to) {
+			sum += p.Avg
+			n++
+		}
+	}
+	if n == 0 {
+		return 0, false
+	}
+	return sum / float64(n), true
+}
+
+func distribution(points []history.Point) Distribution {
+	values := make([]float64, len(points))
+	var peak float64
+	for i, p := range points {
+		values[i] = p.Avg
+		peak = max(peak, p.Max)
+	}
+	s := stats.Summarize(values)
+	return Distribution{Avg: s.Avg, P50: s.P50, P95: s.P95, P99: s.P99, Max: peak}
+}
+
+// pointWeights gives each point the time until the next one, capped so
+// that gaps while nothing was recording don't count, and returns the total
+func pointWeights(points []history.Point, res history.Resolution) ([]time.Duration, time.Duration) {
+	gaps := make([]float64, 0, len(points))
+	for i := 1; i < len(points); i++ {
+		gaps = append(gaps, float64(points[i].Time.Sub(points[i-1].Time)))
+	}
+	var limit time.Duration
+	switch res {
+	case history.ResolutionMinute:
+		limit = time.Minute
+	case history.ResolutionHour:
+		limit = time.Hour
+	default:
+		limit = 3 * time.Duration(stats.Percentile(gaps, 50))
+		if limit == 0 {
+			limit = time.Second
+		}
+	}
+
+	weights := make([]time.Duration, len(points))
+	var covered time.Duration
+	for i := range points {
+		w := limit
+		if i+1 < len(points) {
+			w = min(points[i+1].Time.Sub(points[i].Time), limit)
+		} else if len(gaps) > 0 {
+			w = min(time.Duration(stats.Percentile(gaps, 50)), limit)
+		}
+		weights[i] = w
+		covered += w
+	}
+	return weights, covered
+}
+
+// peakHours returns the n hours of the day with the highest weighted average
+func peakHours(points []history.Point, weights []time.Duration, n int) []PeakHour {
+	var sum, weight [24]float64
+	for i, p := range points {
+		hour := p.Time.Local().Hour()
+		sum[hour] += p.Avg * float64(weights[i])
+		weight[hour] += float64(weights[i])
+	}
+	var hours []PeakHour
+	for h := 0; h < 24; h++ {
+		if weight[h] > 0 {
+			hours = append(hours, PeakHour{Hour: h, Avg: sum[h] / weight[h]})
+		}
+	}
+	sort.SliceStable(hours, func(i, j int) bool { return hours[i].Avg > hours[j].Avg })
+	if len(hours) > n {
+		hours = hours[:n]
+	}
+	return hours
+}
+
+func installedMemory(store *history.Store, opts Options, res history.Resolution) (float64, error) {
+	points, _, err := store.Query(metrics.MetricMemoryTotalBytes, opts.From, opts.To, res)
+	if err != nil {
+		return 0, err
+	}
+	var total float64
+	for _, p := range points {
+		total = max(total, p.Max)
+	}
+	return total, nil
+}
+
+func ramAdvice(totalBytes float64, memory, swap []history.Point) *RAMAdvice {
+	installedGB := totalBytes / gib
+	mem := distribution(memory)
+	swapDist := distribution(swap)
+	advice := &RAMAdvice{
+		InstalledGB: installedGB,
+		P95UsedGB:   mem.P95 / 100 * installedGB,
+		P99UsedGB:   mem.P99 / 100 * installedGB,
+		P95SwapGB:   swapDist.P95 / gib,
+		P99SwapGB:   swapDist.P99 / gib,
+	}
+	advice.MinimumGB = analysis.ConservativeRAM(installedGB, mem.P95, advice.P95SwapGB)
+	advice.RecommendedGB = max(analysis.RecommendedRAM(installedGB, mem.P99, advice.P99SwapGB), advice.MinimumGB)
+	advice.Sufficient = (advice.P99UsedGB+advice.P99SwapGB)*1.25 <= installedGB
+	return advice
+}
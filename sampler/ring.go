@@ -0,0 +1,61 @@
+package sampler
+
+// Ring is a fixed-capacity circular buffer that overwrites its oldest
+// element when full. It is not safe for concurrent use on its own.
+type Ring[T any] struct {
+	items []T
+	start int
+	size  int
+}
+
+// NewRing returns an empty ring holding at most capacity items
+func NewRing[T any](capacity int) *Ring[T] {
+	if capacity < 1 {
+		capacity = 1
+	}
+	return &Ring[T]{items: make([]T, capacity)}
+}
+
+// Push appends an item, dropping the oldest one if the ring is full
+func (r *Ring[T]) Push(item T) {
+	if r.size < len(r.items) {
+		r.items[(r.start+r.size)%len(r.items)] = item
+		r.size++
+		return
+	}
+	r.items[r.start] = item
+	r.start = (r.start + 1) % len(r.items)
+}
+
+// Len returns the number of stored items
+func (r *Ring[T]) Len() int {
+	return r.size
+}
+
+// Cap returns the maximum number of items
+func (r *Ring[T]) Cap() int {
+	return len(r.items)
+}
+
+// At returns the i-th item counting from the oldest
+func (r *Ring[T]) At(i int) T {
+	return r.items[(r.start+i)%len(r.items)]
+}
+
+// Last returns the newest item and false if the ring is empty
+func (r *Ring[T]) Last() (T, bool) {
+	if r.size == 0 {
+		var zero T
+		return zero, false
+	}
+	return r.At(r.size - 1), true
+}
+
+// Slice copies the items out, oldest first
+func (r *Ring[T]) Slice() []T {
+	out := make([]T, r.size)
+	for i := range out {
+		out[i] = r.At(i)
+	}
+	return out
+}
@@ -0,0 +1,43 @@
+package sampler
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestRing(t *testing.T) {
+	tests := []struct {
+		name     string
+		capacity int
+		push     []int
+		want     []int
+	}{
+		{"empty", 3, nil, []int{}},
+		{"partly full", 3, []int{1, 2}, []int{1, 2}},
+		{"full", 3, []int{1, 2, 3}, []int{1, 2, 3}},
+		{"wrapped", 3, []int{1, 2, 3, 4, 5}, []int{3, 4, 5}},
+		{"wrapped twice", 3, []int{1, 2, 3, 4, 5, 6, 7}, []int{5, 6, 7}},
+		{"capacity below one holds one", 0, []int{1, 2}, []int{2}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := NewRing[int](tt.capacity)
+			for _, v := range tt.push {
+				r.Push(v)
+			}
+			if got := r.Slice(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Slice() = %v, want %v", got, tt.want)
+			}
+			if r.Len() != len(tt.want) {
+				t.Errorf("Len() = %d, want %d", r.Len(), len(tt.want))
+			}
+			last, ok := r.Last()
+			if ok != (len(tt.want) > 0) {
+				t.Fatalf("Last() ok = %v with %d items", ok, len(tt.want))
+			}
+			if ok && last != tt.want[len(tt.want)-1] {
+				t.Errorf("Last() = %d, want %d", last, tt.want[len(tt.want)-1])
+			}
+		})
+	}
+}
@@ -0,0 +1,178 @@
+// Package sampler collects snapshots in the background at a fixed interval
+// and keeps recent history in memory.
+//
+// Rates such as CPU usage come from counter deltas between consecutive
+// samples, so readers never wait on a measurement: Latest and History return
+// immediately with whatever has been collected so far.
+package sampler
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/xmarkclx/bottleneck-check/metrics"
+)
+
+// Defaults used when Options leaves a field zero
+const (
+	DefaultInterval = 2 * time.Second
+	DefaultHistory  = 30 * time.Minute
+)
+
+// Options configures a Sampler
+type Options struct {
+	// Interval between samples
+	Interval time.Duration
+	// History is how far back the in-memory ring buffer reaches
+	History time.Duration
+	// Metrics selects the collectors to run
+	Metrics metrics.Options
+}
+
+// Sampler periodically collects snapshots into a ring buffer. It is safe for
+// concurrent use.
+type Sampler struct {
+	interval  time.Duration
+	collector *metrics.Collector
+
+	mu      sync.RWMutex
+	ring    *Ring[metrics.SystemMetrics]
+	lastErr error
+	subs    map[chan metrics.SystemMetrics]struct{}
+}
+
+// New creates a Sampler; call Start to begin sampling
+func New(opts Options) (*Sampler, error) {
+	if opts.Interval <= 0 {
+		opts.Interval = DefaultInterval
+	}
+	if opts.History <= 0 {
+		opts.History = DefaultHistory
+	}
+	if opts.Metrics.SampleWindow <= 0 || opts.Metrics.SampleWindow > opts.Interval {
+		// The first CPU reading shouldn't hold up the first sample for
+		// longer than a regular interval would
+		opts.Metrics.SampleWindow = min(metrics.DefaultSampleWindow, opts.Interval)
+	}
+
+	collector, err := metrics.NewCollector(opts.Metrics)
+	if err != nil {
+		return nil, err
+	}
+
+	capacity := int(opts.History / opts.Interval)
+	return &Sampler{
+		interval:  opts.Interval,
+		collector: collector,
+		ring:      NewRing[metrics.SystemMetrics](capacity),
+		subs:      make(map[chan metrics.SystemMetrics]struct{}),
+	}, nil
+}
+
+// Start launches the sampling goroutine, which runs until ctx is cancelled
+func (s *Sampler) Start(ctx context.Context) {
+	go s.run(ctx)
+}
+
+func (s *Sampler) run(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		s.sample(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *Sampler) sample(ctx context.Context) {
+	snapshot, err := s.collector.Collect(ctx)
+	if ctx.Err() != nil {
+		return
+	}
+
+	s.mu.Lock()
+	s.ring.Push(*snapshot)
+	s.lastErr = err
+	for ch := range s.subs {
+		// Never block sampling on a slow subscriber
+		select {
+		case ch <- *snapshot:
+		default:
+		}
+	}
+	s.mu.Unlock()
+}
+
+// Interval returns the time between samples
+func (s *Sampler) Interval() time.Duration {
+	return s.interval
+}
+
+// Collector returns the underlying collector, e.g. for its statistics
+func (s *Sampler) Collector() *metrics.Collector {
+	return s.collector
+}
+
+// Latest returns the newest snapshot and false if none has been taken yet
+func (s *Sampler) Latest() (*metrics.SystemMetrics, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	last, ok := s.ring.Last()
+	if !ok {
+		return nil, false
+	}
+	return &last, true
+}
+
+// Err returns the collector error from the newest sample, if any
+func (s *Sampler) Err() error {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.lastErr
+}
+
+// History returns the samples taken within the given window, oldest first.
+// A window of zero or less returns everything retained.
+func (s *Sampler) History(window time.Duration) []metrics.SystemMetrics {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	all := s.ring.Slice()
+	if window <= 0 || len(all) == 0 {
+		return all
+	}
+	cutoff := all[len(all)-1].Timestamp.Add(-window)
+	for i, m := range all {
+		if !m.Timestamp.Before(cutoff) {
+			return all[i:]
+		}
+	}
+	return nil
+}
+
+// Subscribe returns a channel that receives each new snapshot. Snapshots
+// are dropped rather than delayed if the receiver falls behind. Call the
+// returned function to unsubscribe; it closes the channel.
+func (s *Sampler) Subscribe(buffer int) (<-chan metrics.SystemMetrics, func()) {
+	ch := make(chan metrics.SystemMetrics, buffer)
+
+	s.mu.Lock()
+	s.subs[ch] = struct{}{}
+	s.mu.Unlock()
+
+	var once sync.Once
+	return ch, func() {
+		once.Do(func() {
+			s.mu.Lock()
+			delete(s.subs, ch)
+			// Sends happen under mu, so none can follow the close
+			close(ch)
+			s.mu.Unlock()
+		})
+	}
+}
@@ -0,0 +1,20 @@
+package sampler
+
+import "testing"
+
+func TestUnsubscribeClosesChannel(t *testing.T) {
+	s, err := New(Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ch, unsubscribe := s.Subscribe(1)
+	unsubscribe()
+	// A second call must not close the channel again
+	unsubscribe()
+	if _, ok := <-ch; ok {
+		t.Fatal("channel still open after unsubscribing")
+	}
+	if len(s.subs) != 0 {
+		t.Errorf("%d subscribers left, want 0", len(s.subs))
+	}
+}
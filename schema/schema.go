@@ -0,0 +1,285 @@
+// Package schema defines the machine-readable output of bottleneck-check:
+// a JSON Document holding a snapshot of the system, the recommendations
+// raised for it, anomalies and a health summary.
+//
+// The same document is printed once by "check -format json" and once per
+// tick, one per line (NDJSON), by "monitor -format ndjson". Every document
+// carries the schema identifier in its "schema" field. Fields may be added
+// within a version; renaming or removing one, or changing its meaning, bumps
+// Version.
+package schema
+
+import (
+	"encoding/json"
+	"io"
+	"math"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/xmarkclx/bottleneck-check/analysis"
+	"github.com/xmarkclx/bottleneck-check/anomaly"
+	"github.com/xmarkclx/bottleneck-check/metrics"
+)
+
+// Version is the current schema version, and ID the value of
+// Document.Schema for it
+const (
+	Version = 1
+	ID      = "bottleneck-check/v1"
+)
+
+// Document kinds
+const (
+	// KindCheck is the result of a one-shot check
+	KindCheck = "check"
+	// KindTick is one update of the continuous monitor
+	KindTick = "tick"
+)
+
+// Document is one complete JSON output
+type Document struct {
+	Schema string    `json:"schema"`
+	Kind   string    `json:"kind"`
+	Time   time.Time `json:"time"`
+	Host   string    `json:"host,omitempty"`
+	// Window is the sampled period a check judged; monitor ticks leave it out
+	Window *Window `json:"window,omitempty"`
+	// Snapshot is the latest reading; null if nothing has been sampled yet
+	Snapshot        *Snapshot        `json:"snapshot"`
+	Recommendations []Recommendation `json:"recommendations"`
+	Anomalies       []Anomaly        `json:"anomalies"`
+	Health          Health           `json:"health"`
+}
+
+// Window is the span of samples behind a document
+type Window struct {
+	From    time.Time `json:"from"`
+	To      time.Time `json:"to"`
+	Samples int       `json:"samples"`
+}
+
+// Snapshot is metrics.SystemMetrics with units spelled out
+type Snapshot struct {
+	Time          time.Time `json:"time"`
+	CPU           CPU       `json:"cpu"`
+	Memory        Memory    `json:"memory"`
+	GPU           GPU       `json:"gpu"`
+	UptimeSeconds float64   `json:"uptime_seconds"`
+	// Processes are the largest processes by resident memory, largest first
+	Processes []Process `json:"processes"`
+}
+
+// CPU is processor usage
+type CPU struct {
+	Model        string     `json:"model"`
+	Cores        int        `json:"cores"`
+	UsagePercent float64    `json:"usage_percent"`
+	LoadAverage  [3]float64 `json:"load_average"` // 1, 5 and 15 minutes
+}
+
+// Memory is RAM and swap usage
+type Memory struct {
+	TotalBytes     uint64  `json:"total_bytes"`
+	UsedBytes      uint64  `json:"used_bytes"`
+	UsedPercent    float64 `json:"used_percent"`
+	SwapTotalBytes uint64  `json:"swap_total_bytes"`
+	SwapUsedBytes  uint64  `json:"swap_used_bytes"`
+	// Pressure is "normal", "warning", "critical" or "unknown"
+	Pressure string `json:"pressure"`
+	Speed    string `json:"speed,omitempty"`
+}
+
+// GPU is the graphics adapter
+type GPU struct {
+	Model        string  `json:"model"`
+	UsagePercent float64 `json:"usage_percent"`
+}
+
+// Process is one process's memory footprint and CPU usage
+type Process struct {
+	PID        int32   `json:"pid"`
+	Name       string  `json:"name"`
+	RSSBytes   uint64  `json:"rss_bytes"`
+	CPUPercent float64 `json:"cpu_percent"`
+}
+
+// Recommendation is an analysis.Recommendation. ID is stable across
+// documents for as long as the condition holds, so consumers can track it.
+type Recommendation struct {
+	ID        string `json:"id"`
+	Rule      string `json:"rule"`
+	Subject   string `json:"subject,omitempty"`
+	Component string `json:"component"`
+	// Severity is "low", "medium", "high" or "critical"
+	Severity   string `json:"severity"`
+	Reason     string `json:"reason"`
+	Suggestion string `json:"suggestion"`
+	// Since and DurationSeconds say how long a sustained condition has held
+	Since           *time.Time `json:"since,omitempty"`
+	DurationSeconds float64    `json:"duration_seconds,omitempty"`
+	// Evidence holds the readings the recommendation is based on
+	Evidence map[string]float64 `json:"evidence,omitempty"`
+}
+
+// Transition is a change in one recommendation between two evaluations
+type Transition struct {
+	// Kind is "raised", "escalated", "deescalated" or "resolved"
+	Kind string    `json:"kind"`
+	Time time.Time `json:"time"`
+	// PreviousSeverity is set on escalations and de-escalations
+	PreviousSeverity string `json:"previous_severity,omitempty"`
+	// Recommendation is the current state, or the last one seen when resolved
+	Recommendation Recommendation `json:"recommendation"`
+}
+
+// Anomaly is a reading far from what is normal for this host
+type Anomaly struct {
+	Metric string  `json:"metric"`
+	Value  float64 `json:"value"`
+	// Expected and StdDev describe normal for this time of day and week
+	Expected float64   `json:"expected"`
+	StdDev   float64   `json:"stddev"`
+	Basis    string    `json:"basis"`
+	Z        float64   `json:"z"`
+	Since    time.Time `json:"since"`
+}
+
+// Health summarizes the recommendations in one score
+type Health struct {
+	Score int `json:"score"` // 0-100
+	// Status is "ok", "degraded" or "critical"
+	Status string `json:"status"`
+	Worst  string `json:"worst,omitempty"`
+	// Counts has a count for every severity, including zeros
+	Counts map[string]int `json:"counts"`
+}
+
+// New builds a document of the given kind. m may be nil.
+func New(kind string, m *metrics.SystemMetrics, recs []analysis.Recommendation, anomalies []anomaly.Anomaly) Document {
+	doc := Document{
+		Schema:          ID,
+		Kind:            kind,
+		Time:            time.Now(),
+		Recommendations: make([]Recommendation, 0, len(recs)),
+		Anomalies:       make([]Anomaly, 0, len(anomalies)),
+		Health:          NewHealth(analysis.Health(recs)),
+	}
+	if host, err := os.Hostname(); err == nil {
+		doc.Host = host
+	}
+	if m != nil {
+		snapshot := NewSnapshot(m)
+		doc.Snapshot = &snapshot
+	}
+	for _, rec := range recs {
+		doc.Recommendations = append(doc.Recommendations, NewRecommendation(rec))
+	}
+	for _, a := range anomalies {
+		doc.Anomalies = append(doc.Anomalies, NewAnomaly(a))
+	}
+	return doc
+}
+
+// NewSnapshot converts a snapshot
+func NewSnapshot(m *metrics.SystemMetrics) Snapshot {
+	s := Snapshot{
+		Time: m.Timestamp,
+		CPU: CPU{
+			Model:        m.CPUModel,
+			Cores:        m.CPUCores,
+			UsagePercent: m.CPUUsage,
+			LoadAverage:  m.LoadAverage,
+		},
+		Memory: Memory{
+			TotalBytes:     m.MemoryTotal,
+			UsedBytes:      m.MemoryUsed,
+			UsedPercent:    m.MemoryPercent(),
+			SwapTotalBytes: m.SwapTotal,
+			SwapUsedBytes:  m.SwapUsed,
+			Pressure:       m.MemPressure,
+			Speed:          m.MemorySpeed,
+		},
+		GPU:           GPU{Model: m.GPUModel, UsagePercent: m.GPUUsage},
+		UptimeSeconds: m.Uptime.Seconds(),
+		Processes:     make([]Process, 0, len(m.Processes)),
+	}
+	for _, p := range m.Processes {
+		s.Processes = append(s.Processes, Process{PID: p.PID, Name: p.Name, RSSBytes: p.RSS, CPUPercent: p.CPUPercent})
+	}
+	return s
+}
+
+// NewRecommendation converts a recommendation
+func NewRecommendation(rec analysis.Recommendation) Recommendation {
+	out := Recommendation{
+		ID:              rec.ID(),
+		Rule:            rec.Rule,
+		Subject:         rec.Subject,
+		Component:       rec.Component,
+		Severity:        severity(rec.Severity),
+		Reason:          rec.Reason,
+		Suggestion:      rec.Suggestion,
+		DurationSeconds: rec.Duration.Seconds(),
+	}
+	if !rec.Since.IsZero() {
+		since := rec.Since
+		out.Since = &since
+	}
+	if len(rec.Evidence) > 0 {
+		out.Evidence = make(map[string]float64, len(rec.Evidence))
+		for name, v := range rec.Evidence {
+			// JSON has no NaN or infinity
+			if !math.IsNaN(v) && !math.IsInf(v, 0) {
+				out.Evidence[name] = v
+			}
+		}
+	}
+	return out
+}
+
+// NewTransition converts a transition
+func NewTransition(t analysis.Transition) Transition {
+	return Transition{
+		Kind:             string(t.Kind),
+		Time:             t.Time,
+		PreviousSeverity: severity(t.Previous),
+		Recommendation:   NewRecommendation(t.Recommendation),
+	}
+}
+
+// NewAnomaly converts an anomaly
+func NewAnomaly(a anomaly.Anomaly) Anomaly {
+	return Anomaly{
+		Metric:   a.Metric,
+		Value:    a.Value,
+		Expected: a.Expected.Mean,
+		StdDev:   a.Expected.StdDev,
+		Basis:    string(a.Expected.Basis),
+		Z:        a.Z,
+		Since:    a.Since,
+	}
+}
+
+// NewHealth converts a health summary
+func NewHealth(h analysis.HealthSummary) Health {
+	out := Health{Score: h.Score, Status: h.Status, Worst: severity(h.Worst), Counts: make(map[string]int, len(h.Counts))}
+	for sev, n := range h.Counts {
+		out.Counts[severity(sev)] = n
+	}
+	return out
+}
+
+func severity(s analysis.Severity) string {
+	return strings.ToLower(string(s))
+}
+
+// Write encodes a document as a single line, the NDJSON framing, or
+// indented when indent is set
+func Write(w io.Writer, doc Document, indent bool) error {
+	enc := json.NewEncoder(w)
+	if indent {
+		enc.SetIndent("", "  ")
+	}
+	return enc.Encode(doc)
+}
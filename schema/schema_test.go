@@ -0,0 +1,154 @@
+package schema
+
+import (
+	"bytes"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/xmarkclx/bottleneck-check/analysis"
+	"github.com/xmarkclx/bottleneck-check/anomaly"
+	"github.com/xmarkclx/bottleneck-check/metrics"
+)
+
+func TestDocumentGolden(t *testing.T) {
+	now := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+	m := &metrics.SystemMetrics{
+		Timestamp:   now,
+		CPUUsage:    97.5,
+		LoadAverage: [3]float64{7.5, 6.25, 4},
+		MemoryUsed:  6 << 30,
+		MemoryTotal: 8 << 30,
+		SwapUsed:    1 << 30,
+		SwapTotal:   2 << 30,
+		MemPressure: "warning",
+		CPUCores:    4,
+		CPUModel:    "Example CPU",
+		Uptime:      90 * time.Minute,
+		Processes:   []metrics.ProcessInfo{{PID: 42, Name: "make", RSS: 512 << 20, CPUPercent: 180}},
+	}
+	recs := []analysis.Recommendation{{
+		Rule:       "cpu.usage",
+		Component:  "CPU",
+		Severity:   analysis.SeverityCritical,
+		Reason:     "CPU usage is very high (97.5%)",
+		Suggestion: "Close unnecessary applications.",
+		Since:      now.Add(-4 * time.Minute),
+		Duration:   4 * time.Minute,
+		// JSON has no NaN or infinity, so those readings are dropped
+		Evidence: map[string]float64{"usage": 97.5, "ratio": math.NaN(), "rate": math.Inf(1)},
+	}}
+	anomalies := []anomaly.Anomaly{{
+		Metric:   metrics.MetricCPUUsage,
+		Value:    90,
+		Expected: anomaly.Expectation{Mean: 30, StdDev: 10, Basis: anomaly.BasisWeekHour, Count: 60},
+		Z:        6,
+		Since:    now.Add(-time.Minute),
+	}}
+
+	doc := New(KindCheck, m, recs, anomalies)
+	doc.Time = now
+	doc.Host = "example"
+	doc.Window = &Window{From: now.Add(-time.Minute), To: now, Samples: 60}
+	var b bytes.Buffer
+	if err := Write(&b, doc, true); err != nil {
+		t.Fatal(err)
+	}
+	if got := b.String(); got != golden {
+		t.Errorf("document =\n%s\nwant\n%s", got, golden)
+	}
+
+	// Without indent it is one NDJSON line
+	b.Reset()
+	if err := Write(&b, doc, false); err != nil {
+		t.Fatal(err)
+	}
+	if n := bytes.Count(b.Bytes(), []byte("\n")); n != 1 || !bytes.HasSuffix(b.Bytes(), []byte("\n")) {
+		t.Errorf("NDJSON document spans %d lines:\n%s", n, b.String())
+	}
+}
+
+// golden is the check document above, as "check -format json" prints it
+const golden = `{
+  "schema": "bottleneck-check/v1",
+  "kind": "check",
+  "time": "2026-10-18T12:00:00Z",
+  "host": "example",
+  "window": {
+    "from": "2026-10-18T11:59:00Z",
+    "to": "2026-10-18T12:00:00Z",
+    "samples": 60
+  },
+  "snapshot": {
+    "time": "2026-10-18T12:00:00Z",
+    "cpu": {
+      "model": "Example CPU",
+      "cores": 4,
+      "usage_percent": 97.5,
+      "load_average": [
+        7.5,
+        6.25,
+        4
+      ]
+    },
+    "memory": {
+      "total_bytes": 8589934592,
+      "used_bytes": 6442450944,
+      "used_percent": 75,
+      "swap_total_bytes": 2147483648,
+      "swap_used_bytes": 1073741824,
+      "pressure": "warning"
+    },
+    "gpu": {
+      "model": "",
+      "usage_percent": 0
+    },
+    "uptime_seconds": 5400,
+    "processes": [
+      {
+        "pid": 42,
+        "name": "make",
+        "rss_bytes": 536870912,
+        "cpu_percent": 180
+      }
+    ]
+  },
+  "recommendations": [
+    {
+      "id": "cpu.usage",
+      "rule": "cpu.usage",
+      "component": "CPU",
+      "severity": "critical",
+      "reason": "CPU usage is very high (97.5%)",
+      "suggestion": "Close unnecessary applications.",
+      "since": "2026-10-18T11:56:00Z",
+      "duration_seconds": 240,
+      "evidence": {
+        "usage": 97.5
+      }
+    }
+  ],
+  "anomalies": [
+    {
+      "metric": "cpu.usage",
+      "value": 90,
+      "expected": 30,
+      "stddev": 10,
+      "basis": "weekday-hour",
+      "z": 6,
+      "since": "2026-10-18T11:59:00Z"
+    }
+  ],
+  "health": {
+    "score": 60,
+    "status": "critical",
+    "worst": "critical",
+    "counts": {
+      "critical": 1,
+      "high": 0,
+      "low": 0,
+      "medium": 0
+    }
+  }
+}
+`
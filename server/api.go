@@ -0,0 +1,312 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/xmarkclx/bottleneck-check/analysis"
+	"github.com/xmarkclx/bottleneck-check/history"
+	"github.com/xmarkclx/bottleneck-check/metrics"
+	"github.com/xmarkclx/bottleneck-check/schema"
+)
+
+// errNoSnapshot is served until the first sample has been evaluated
+var errNoSnapshot = errors.New("no snapshot taken yet")
+
+// recommendationsResponse is the body of /v1/recommendations
+type recommendationsResponse struct {
+	Schema          string                  `json:"schema"`
+	Updated         time.Time               `json:"updated"`
+	Recommendations []schema.Recommendation `json:"recommendations"`
+	Anomalies       []schema.Anomaly        `json:"anomalies"`
+}
+
+// healthResponse is the body of /v1/health
+type healthResponse struct {
+	Schema  string    `json:"schema"`
+	Updated time.Time `json:"updated"`
+	schema.Health
+}
+
+// historyResponse is the body of /v1/history
+type historyResponse struct {
+	Schema     string         `json:"schema"`
+	Metric     string         `json:"metric"`
+	Unit       string         `json:"unit,omitempty"`
+	From       time.Time      `json:"from"`
+	To         time.Time      `json:"to"`
+	Resolution string         `json:"resolution"`
+	Points     []historyPoint `json:"points"`
+	// Annotations made in the range, to mark on a chart of the points
+	Annotations []annotation `json:"annotations"`
+}
+
+// annotationsResponse is the body of GET /v1/annotations
+type annotationsResponse struct {
+	Schema      string       `json:"schema"`
+	From        time.Time    `json:"from"`
+	To          time.Time    `json:"to"`
+	Annotations []annotation `json:"annotations"`
+}
+
+// annotationRequest is the body of POST /v1/annotations. Time takes the
+// formats of the history command and defaults to now.
+type annotationRequest struct {
+	Text string `json:"text"`
+	Time string `json:"time,omitempty"`
+}
+
+type annotation struct {
+	Time   time.Time `json:"time"`
+	Text   string    `json:"text"`
+	Source string    `json:"source,omitempty"`
+}
+
+type historyPoint struct {
+	Time  time.Time `json:"time"`
+	Min   float64   `json:"min"`
+	Avg   float64   `json:"avg"`
+	Max   float64   `json:"max"`
+	P95   float64   `json:"p95"`
+	Count int       `json:"count"`
+}
+
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	enc.Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, errorResponse{Error: err.Error()})
+}
+
+// getOrPost rejects methods other than GET, HEAD and POST
+func getOrPost(h http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead && r.Method != http.MethodPost {
+			w.Header().Set("Allow", "GET, HEAD, POST")
+			writeError(w, http.StatusMethodNotAllowed, fmt.Errorf("method %s not allowed", r.Method))
+			return
+		}
+		h(w, r)
+	}
+}
+
+// getOnly rejects methods other than GET and HEAD
+func getOnly(h http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			w.Header().Set("Allow", "GET, HEAD")
+			writeError(w, http.StatusMethodNotAllowed, fmt.Errorf("method %s not allowed", r.Method))
+			return
+		}
+		h(w, r)
+	}
+}
+
+func (s *Server) serveSnapshot(w http.ResponseWriter, _ *http.Request) {
+	state := s.State()
+	if state.Snapshot == nil {
+		writeError(w, http.StatusServiceUnavailable, errNoSnapshot)
+		return
+	}
+	writeJSON(w, http.StatusOK, schema.NewSnapshot(state.Snapshot))
+}
+
+func (s *Server) serveRecommendations(w http.ResponseWriter, _ *http.Request) {
+	state := s.State()
+	if state.Snapshot == nil {
+		writeError(w, http.StatusServiceUnavailable, errNoSnapshot)
+		return
+	}
+	doc := schema.New(schema.KindTick, nil, state.Recommendations, state.Anomalies)
+	writeJSON(w, http.StatusOK, recommendationsResponse{
+		Schema:          schema.ID,
+		Updated:         state.Updated,
+		Recommendations: doc.Recommendations,
+		Anomalies:       doc.Anomalies,
+	})
+}
+
+// serveHealth answers 200 while the monitor is ok or degraded and 503 when
+// a recommendation is critical or nothing has been sampled, so it can back
+// a load balancer or uptime check as is
+func (s *Server) serveHealth(w http.ResponseWriter, _ *http.Request) {
+	state := s.State()
+	if state.Snapshot == nil {
+		writeError(w, http.StatusServiceUnavailable, errNoSnapshot)
+		return
+	}
+	doc := schema.New(schema.KindTick, nil, state.Recommendations, nil)
+	status := http.StatusOK
+	if doc.Health.Status == analysis.HealthCritical {
+		status = http.StatusServiceUnavailable
+	}
+	writeJSON(w, status, healthResponse{Schema: schema.ID, Updated: state.Updated, Health: doc.Health})
+}
+
+// serveHistory answers /v1/history?metric=cpu.usage&from=2h&to=now&res=auto,
+// with times in the formats the history command accepts
+func (s *Server) serveHistory(w http.ResponseWriter, r *http.Request) {
+	if s.store == nil {
+		writeError(w, http.StatusNotFound, errors.New("history recording is disabled"))
+		return
+	}
+	q := r.URL.Query()
+	name := q.Get("metric")
+	if name == "" {
+		name = metrics.MetricCPUUsage
+	}
+	series, ok := metrics.LookupSeries(name)
+	if !ok {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("unknown metric %q", name))
+		return
+	}
+	now := time.Now()
+	from, err := history.ParseTime(valueOr(q.Get("from"), "1h"), now)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	to, err := history.ParseTime(valueOr(q.Get("to"), "now"), now)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	res, err := history.ParseResolution(valueOr(q.Get("res"), string(history.ResolutionAuto)))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	points, used, err := s.store.Query(series.Name, from, to, res)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	resp := historyResponse{
+		Schema:     schema.ID,
+		Metric:     series.Name,
+		Unit:       series.Unit,
+		From:       from,
+		To:         to,
+		Resolution: string(used),
+		Points:     make([]historyPoint, 0, len(points)),
+	}
+	for _, p := range points {
+		resp.Points = append(resp.Points, historyPoint(p))
+	}
+	if resp.Annotations, err = s.annotations(from, to); err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// maxAnnotationBody bounds the body of POST /v1/annotations
+const maxAnnotationBody = 4096
+
+// serveAnnotations lists the annotations between ?from= and ?to= (the last
+// day by default) on GET, and records one on POST
+func (s *Server) serveAnnotations(w http.ResponseWriter, r *http.Request) {
+	if s.store == nil {
+		writeError(w, http.StatusNotFound, errors.New("history recording is disabled"))
+		return
+	}
+	now := time.Now()
+	if r.Method == http.MethodPost {
+		if status, err := checkJSONPost(r); err != nil {
+			writeError(w, status, err)
+			return
+		}
+		var req annotationRequest
+		if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxAnnotationBody)).Decode(&req); err != nil {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("invalid annotation: %w", err))
+			return
+		}
+		at, err := history.ParseTime(req.Time, now)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+		a, err := s.store.Annotate(history.Annotation{Time: at, Text: req.Text, Source: "api"})
+		if err != nil {
+			status := http.StatusInternalServerError
+			if errors.Is(err, history.ErrInvalidAnnotation) {
+				status = http.StatusBadRequest
+			}
+			writeError(w, status, err)
+			return
+		}
+		s.announce(a)
+		writeJSON(w, http.StatusCreated, annotation{Time: a.Time, Text: a.Text, Source: a.Source})
+		return
+	}
+
+	q := r.URL.Query()
+	from, err := history.ParseTime(valueOr(q.Get("from"), "24h"), now)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	to, err := history.ParseTime(valueOr(q.Get("to"), "now"), now)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	resp := annotationsResponse{Schema: schema.ID, From: from, To: to}
+	if resp.Annotations, err = s.annotations(from, to); err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// checkJSONPost rejects a POST that a web page on another site could have
+// sent: browsers send form and text/plain bodies cross-site without asking,
+// and say where a request comes from in Origin. Without a token, the API is
+// otherwise open to any page the user visits.
+func checkJSONPost(r *http.Request) (int, error) {
+	if mt, _, err := mime.ParseMediaType(r.Header.Get("Content-Type")); err != nil || mt != "application/json" {
+		return http.StatusUnsupportedMediaType, errors.New("the body must be application/json")
+	}
+	if origin := r.Header.Get("Origin"); origin != "" {
+		if u, err := url.Parse(origin); err != nil || u.Host != r.Host {
+			return http.StatusForbidden, fmt.Errorf("cross-origin request from %s refused", origin)
+		}
+	}
+	return 0, nil
+}
+
+// annotations reads the annotations between from and to, never nil so they
+// encode as an empty list
+func (s *Server) annotations(from, to time.Time) ([]annotation, error) {
+	stored, err := s.store.Annotations(from, to)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]annotation, 0, len(stored))
+	for _, a := range stored {
+		out = append(out, annotation{Time: a.Time, Text: a.Text, Source: a.Source})
+	}
+	return out, nil
+}
+
+func valueOr(v, fallback string) string {
+	if v == "" {
+		return fallback
+	}
+	return v
+}
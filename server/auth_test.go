@@ -0,0 +1,107 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestAuthorize(t *testing.T) {
+	s := New(Options{Token: "secret"})
+	handler := s.authorize(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	do := func(method, target, auth string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(method, target, nil)
+		if auth != "" {
+			r.Header.Set("Authorization", auth)
+		}
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+		return w
+	}
+
+	tests := []struct {
+		name, target, auth string
+		want               int
+	}{
+		{"no token", "/v1/snapshot", "", http.StatusUnauthorized},
+		{"wrong token", "/v1/snapshot", "Bearer wrong", http.StatusUnauthorized},
+		{"not bearer", "/v1/snapshot", "secret", http.StatusUnauthorized},
+		{"token", "/v1/snapshot", "Bearer secret", http.StatusNoContent},
+		{"token in the URL", "/v1/stream?access_token=secret", "", http.StatusUnauthorized},
+		{"token as a ticket", "/v1/stream?ticket=secret", "", http.StatusUnauthorized},
+		{"unknown ticket", "/v1/stream?ticket=0123", "", http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		if w := do(http.MethodGet, tt.target, tt.auth); w.Code != tt.want {
+			t.Errorf("%s: status %d, want %d", tt.name, w.Code, tt.want)
+		}
+	}
+	if w := do(http.MethodGet, "/v1/snapshot", ""); w.Header().Get("WWW-Authenticate") == "" {
+		t.Error("401 response lacks WWW-Authenticate")
+	}
+}
+
+func TestStreamTickets(t *testing.T) {
+	s := New(Options{Token: "secret"})
+	handler := s.Handler()
+	do := func(method, target, auth string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(method, target, nil)
+		if auth != "" {
+			r.Header.Set("Authorization", auth)
+		}
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+		return w
+	}
+
+	if w := do(http.MethodPost, "/v1/stream/ticket", ""); w.Code != http.StatusUnauthorized {
+		t.Errorf("ticket without the token: status %d, want 401", w.Code)
+	}
+	if w := do(http.MethodGet, "/v1/stream/ticket", "Bearer secret"); w.Code != http.StatusMethodNotAllowed {
+		t.Errorf("GET ticket: status %d, want 405", w.Code)
+	}
+	w := do(http.MethodPost, "/v1/stream/ticket", "Bearer secret")
+	var ticket ticketResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &ticket); w.Code != http.StatusOK || err != nil || ticket.Ticket == "" {
+		t.Fatalf("POST ticket: status %d, body %q", w.Code, w.Body)
+	}
+	if d := time.Until(ticket.Expires); d <= 0 || d > ticketLifetime {
+		t.Errorf("ticket expires in %s, want within %s", d, ticketLifetime)
+	}
+
+	// Tickets open streams only, once
+	if s.redeemTicket("") {
+		t.Error("redeemed an empty ticket")
+	}
+	if w := do(http.MethodGet, "/v1/snapshot?ticket="+ticket.Ticket, ""); w.Code != http.StatusUnauthorized {
+		t.Errorf("ticket for a snapshot: status %d, want 401", w.Code)
+	}
+	if !s.redeemTicket(ticket.Ticket) {
+		t.Fatal("ticket was not accepted")
+	}
+	if s.redeemTicket(ticket.Ticket) {
+		t.Error("ticket was accepted twice")
+	}
+
+	// Expired tickets are refused, and purged when the next is issued
+	s.mu.Lock()
+	s.tickets["stale"] = time.Now().Add(-time.Second)
+	s.mu.Unlock()
+	if s.redeemTicket("stale") {
+		t.Error("expired ticket was accepted")
+	}
+	s.mu.Lock()
+	s.tickets["stale"] = time.Now().Add(-time.Second)
+	s.mu.Unlock()
+	do(http.MethodPost, "/v1/stream/ticket", "Bearer secret")
+	s.mu.Lock()
+	_, kept := s.tickets["stale"]
+	s.mu.Unlock()
+	if kept {
+		t.Error("expired ticket was not purged")
+	}
+}
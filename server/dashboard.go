@@ -0,0 +1,22 @@
+package server
+
+import (
+	"embed"
+	"io/fs"
+	"net/http"
+)
+
+// web holds the dashboard, a static page that reads the /v1/ API and
+// stream. It has no external dependencies, so it works offline.
+//
+//go:embed web
+var web embed.FS
+
+// dashboard serves the embedded dashboard files at the root
+func dashboard() http.Handler {
+	files, err := fs.Sub(web, "web")
+	if err != nil {
+		panic(err) // the directory is embedded at build time
+	}
+	return http.FileServer(http.FS(files))
+}
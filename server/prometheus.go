@@ -0,0 +1,200 @@
+package server
+
+import (
+	"bufio"
+	"fmt"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/xmarkclx/bottleneck-check/analysis"
+	"github.com/xmarkclx/bottleneck-check/metrics"
+)
+
+// namespace prefixes every exported metric name
+const namespace = "bottleneck_"
+
+// gib converts the GB figures of analysis.RecommendedRAM to bytes
+const gib = 1 << 30
+
+// exposition writes the Prometheus text format, version 0.0.4
+type exposition struct {
+	w *bufio.Writer
+}
+
+// family starts a metric family with its HELP and TYPE lines
+func (e exposition) family(name, typ, help string) {
+	fmt.Fprintf(e.w, "# HELP %s%s %s\n# TYPE %s%s %s\n", namespace, name, help, namespace, name, typ)
+}
+
+// sample writes one series; labels alternate names and values
+func (e exposition) sample(name string, value float64, labels ...string) {
+	e.w.WriteString(namespace + name)
+	if len(labels) > 0 {
+		e.w.WriteByte('{')
+		for i := 0; i+1 < len(labels); i += 2 {
+			if i > 0 {
+				e.w.WriteByte(',')
+			}
+			fmt.Fprintf(e.w, "%s=\"%s\"", labels[i], escapeLabel(labels[i+1]))
+		}
+		e.w.WriteByte('}')
+	}
+	e.w.WriteByte(' ')
+	e.w.WriteString(formatValue(value))
+	e.w.WriteByte('\n')
+}
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func escapeLabel(v string) string {
+	return labelEscaper.Replace(v)
+}
+
+func formatValue(v float64) string {
+	switch {
+	case math.IsNaN(v):
+		return "NaN"
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+// seriesName turns a metric name such as "cpu.usage" with unit "percent"
+// into "cpu_usage_percent"
+func seriesName(s metrics.Series) string {
+	name := strings.ReplaceAll(s.Name, ".", "_")
+	if s.Unit != "" && !strings.HasSuffix(name, "_"+s.Unit) {
+		name += "_" + s.Unit
+	}
+	return name
+}
+
+func (s *Server) serveMetrics(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	e := exposition{bufio.NewWriter(w)}
+	defer e.w.Flush()
+
+	state := s.State()
+	e.family("up", "gauge", "Whether the monitor has taken a snapshot yet.")
+	if state.Snapshot == nil {
+		e.sample("up", 0)
+	} else {
+		e.sample("up", 1)
+		e.family("last_update_timestamp_seconds", "gauge", "Unix time of the last evaluation.")
+		e.sample("last_update_timestamp_seconds", float64(state.Updated.UnixMilli())/1000)
+		writeSnapshot(e, state.Snapshot)
+		writeRecommendations(e, state.Recommendations)
+		if len(state.Anomalies) > 0 {
+			e.family("anomaly_zscore", "gauge", "Deviation from normal for this host, in standard deviations, of metrics that are anomalous.")
+			for _, a := range state.Anomalies {
+				e.sample("anomaly_zscore", a.Z, "metric", a.Metric)
+			}
+		}
+	}
+	if s.collector != nil {
+		writeCollectorStats(e, s.collector.Stats())
+	}
+}
+
+func writeSnapshot(e exposition, m *metrics.SystemMetrics) {
+	values := m.Values()
+	for _, series := range metrics.AllSeries {
+		v, ok := values[series.Name]
+		if !ok {
+			continue
+		}
+		name := seriesName(series)
+		e.family(name, "gauge", series.Help+".")
+		e.sample(name, v)
+	}
+
+	if m.MemoryTotal > 0 {
+		gb := analysis.RecommendedRAM(float64(m.MemoryTotal)/gib, m.MemoryPercent(), float64(m.SwapUsed)/gib)
+		e.family("memory_recommended_bytes", "gauge", "RAM recommended for the current usage, including swapped-out memory.")
+		e.sample("memory_recommended_bytes", gb*gib)
+	}
+	if m.Uptime > 0 {
+		e.family("uptime_seconds", "gauge", "Time since boot.")
+		e.sample("uptime_seconds", m.Uptime.Seconds())
+	}
+	if len(m.Processes) > 0 {
+		// Summed by name: a PID label would add a series for every restart
+		byName := make(map[string]float64)
+		for _, p := range m.Processes {
+			byName[p.Name] += float64(p.RSS)
+		}
+		names := make([]string, 0, len(byName))
+		for name := range byName {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		e.family("process_resident_bytes", "gauge", "Resident memory of the largest processes, summed by process name.")
+		for _, name := range names {
+			e.sample("process_resident_bytes", byName[name], "name", name)
+		}
+	}
+	e.family("info", "gauge", "Hardware description; always 1.")
+	e.sample("info", 1, "cpu_model", m.CPUModel, "gpu_model", m.GPUModel, "memory_speed", m.MemorySpeed)
+}
+
+func writeRecommendations(e exposition, recs []analysis.Recommendation) {
+	// Rules that fire per subject, such as process.leak, share a label set;
+	// the value counts them
+	type key struct{ component, severity, rule string }
+	counts := make(map[key]int)
+	for _, rec := range recs {
+		counts[key{rec.Component, strings.ToLower(string(rec.Severity)), rec.Rule}]++
+	}
+	keys := make([]key, 0, len(counts))
+	for k := range counts {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].rule != keys[j].rule {
+			return keys[i].rule < keys[j].rule
+		}
+		return keys[i].severity < keys[j].severity
+	})
+
+	e.family("recommendation", "gauge", "Active recommendations by rule; absent when none are active.")
+	for _, k := range keys {
+		e.sample("recommendation", float64(counts[k]), "component", k.component, "severity", k.severity, "rule", k.rule)
+	}
+
+	health := analysis.Health(recs)
+	e.family("health_score", "gauge", "Health score from 100, nothing to act on, down to 0.")
+	e.sample("health_score", float64(health.Score))
+	e.family("recommendations", "gauge", "Number of active recommendations by severity.")
+	for _, sev := range analysis.Severities {
+		e.sample("recommendations", float64(health.Counts[sev]), "severity", strings.ToLower(string(sev)))
+	}
+}
+
+func writeCollectorStats(e exposition, stats []metrics.CollectorStat) {
+	e.family("collector_duration_seconds", "gauge", "How long the last run of each collector took.")
+	for _, st := range stats {
+		e.sample("collector_duration_seconds", st.LastDuration.Seconds(), "collector", st.Name)
+	}
+	e.family("collector_success", "gauge", "Whether the last run of each collector succeeded.")
+	for _, st := range stats {
+		success := 0.0
+		if st.Runs > 0 && st.LastError == nil {
+			success = 1
+		}
+		e.sample("collector_success", success, "collector", st.Name)
+	}
+	e.family("collector_runs_total", "counter", "Runs of each collector.")
+	for _, st := range stats {
+		e.sample("collector_runs_total", float64(st.Runs), "collector", st.Name)
+	}
+	e.family("collector_errors_total", "counter", "Failed runs of each collector.")
+	for _, st := range stats {
+		e.sample("collector_errors_total", float64(st.Errors), "collector", st.Name)
+	}
+}
@@ -0,0 +1,63 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/xmarkclx/bottleneck-check/analysis"
+	"github.com/xmarkclx/bottleneck-check/metrics"
+)
+
+func scrape(t *testing.T, s *Server) string {
+	t.Helper()
+	w := httptest.NewRecorder()
+	s.serveMetrics(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if ct := w.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/plain; version=0.0.4") {
+		t.Errorf("Content-Type = %q", ct)
+	}
+	return w.Body.String()
+}
+
+func TestMetrics(t *testing.T) {
+	s := New(Options{})
+	if body := scrape(t, s); !strings.Contains(body, "\nbottleneck_up 0\n") {
+		t.Errorf("before the first update:\n%s", body)
+	}
+
+	m := &metrics.SystemMetrics{
+		Timestamp:   time.Now(),
+		CPUCores:    4,
+		CPUUsage:    12.5,
+		MemoryTotal: 8 * gib,
+		MemoryUsed:  2 * gib,
+		Processes: []metrics.ProcessInfo{
+			{PID: 10, Name: "worker", RSS: 100},
+			{PID: 11, Name: "worker", RSS: 50},
+			{PID: 12, Name: `we"ird`, RSS: 7},
+		},
+	}
+	recs := []analysis.Recommendation{
+		{Rule: analysis.RuleProcessLeak, Subject: "10", Component: "Memory", Severity: analysis.SeverityLow},
+		{Rule: analysis.RuleProcessLeak, Subject: "12", Component: "Memory", Severity: analysis.SeverityLow},
+	}
+	s.Update(m, recs, nil)
+	body := scrape(t, s)
+	for _, want := range []string{
+		"bottleneck_up 1",
+		"bottleneck_cpu_usage_percent 12.5",
+		`bottleneck_process_resident_bytes{name="we\"ird"} 7`,
+		`bottleneck_process_resident_bytes{name="worker"} 150`,
+		`bottleneck_recommendation{component="Memory",severity="low",rule="process.leak"} 2`,
+		`bottleneck_recommendations{severity="low"} 2`,
+	} {
+		if !strings.Contains(body, "\n"+want+"\n") {
+			t.Errorf("missing %s in:\n%s", want, body)
+		}
+	}
+	if strings.Contains(body, "pid=") {
+		t.Errorf("process series carry a pid label:\n%s", body)
+	}
+}
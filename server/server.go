@@ -0,0 +1,173 @@
+// Package server exposes a running monitor over HTTP.
+//
+// The monitor loop hands every evaluation to Server.Update; handlers only
+// read the latest state, so a slow or stuck client never holds up sampling.
+// /metrics serves the state in the Prometheus text exposition format, and
+// /v1/ is a JSON API using the types of package schema, and / is a web
+// dashboard built on the API:
+//
+//	GET /v1/snapshot         latest snapshot
+//	GET /v1/recommendations  active recommendations and anomalies
+//	GET /v1/health           health summary; 503 while critical
+//	GET /v1/history          recorded points of ?metric= between ?from= and ?to=
+//	GET /v1/annotations      annotations between ?from= and ?to=
+//	POST /v1/annotations     record an annotation: {"text": ..., "time": ...}
+//	GET /v1/stream           Server-Sent Events: every evaluation, every
+//	                         recommendation raised, escalated or resolved,
+//	                         and every annotation made through the API
+//	POST /v1/stream/ticket   a single-use ticket that opens a stream as
+//	                         ?ticket= without the token, for EventSource
+package server
+
+import (
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/xmarkclx/bottleneck-check/analysis"
+	"github.com/xmarkclx/bottleneck-check/anomaly"
+	"github.com/xmarkclx/bottleneck-check/history"
+	"github.com/xmarkclx/bottleneck-check/metrics"
+)
+
+// Options configures a Server
+type Options struct {
+	// Collector reports collector durations and errors; optional
+	Collector *metrics.Collector
+	// Store answers /v1/history; without it the endpoint returns 404
+	Store *history.Store
+	// Token, if set, must be sent as "Authorization: Bearer <token>" on
+	// every request, except streams opened with a ticket
+	Token string
+	// MaxClients limits open streams; zero means DefaultMaxClients
+	MaxClients int
+}
+
+// Server serves the latest monitor state. It is safe for concurrent use.
+type Server struct {
+	collector  *metrics.Collector
+	store      *history.Store
+	token      string
+	maxClients int
+
+	// mu guards the state, the set of streaming clients and the stream
+	// tickets with their expiry times
+	mu      sync.RWMutex
+	state   State
+	clients map[*client]struct{}
+	tickets map[string]time.Time
+}
+
+// State is one evaluation of the monitor
+type State struct {
+	Snapshot        *metrics.SystemMetrics
+	Recommendations []analysis.Recommendation
+	Anomalies       []anomaly.Anomaly
+	Updated         time.Time
+}
+
+// New returns a Server with no state yet
+func New(opts Options) *Server {
+	if opts.MaxClients <= 0 {
+		opts.MaxClients = DefaultMaxClients
+	}
+	return &Server{
+		collector:  opts.Collector,
+		store:      opts.Store,
+		token:      opts.Token,
+		maxClients: opts.MaxClients,
+		clients:    make(map[*client]struct{}),
+		tickets:    make(map[string]time.Time),
+	}
+}
+
+// Update replaces the state served to clients and pushes it to streams
+func (s *Server) Update(m *metrics.SystemMetrics, recs []analysis.Recommendation, anomalies []anomaly.Anomaly) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	prev := s.state.Recommendations
+	s.state = State{Snapshot: m, Recommendations: recs, Anomalies: anomalies, Updated: time.Now()}
+	s.broadcast(prev)
+}
+
+// State returns the latest state; Snapshot is nil before the first Update
+func (s *Server) State() State {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.state
+}
+
+// Handler returns the HTTP handler for all endpoints. The dashboard at /
+// holds no data of its own, so only /metrics and /v1/ need the token.
+func (s *Server) Handler() http.Handler {
+	api := http.NewServeMux()
+	api.HandleFunc("/metrics", getOnly(s.serveMetrics))
+	api.HandleFunc("/v1/snapshot", getOnly(s.serveSnapshot))
+	api.HandleFunc("/v1/recommendations", getOnly(s.serveRecommendations))
+	api.HandleFunc("/v1/health", getOnly(s.serveHealth))
+	api.HandleFunc("/v1/history", getOnly(s.serveHistory))
+	api.HandleFunc("/v1/annotations", getOrPost(s.serveAnnotations))
+	api.HandleFunc("/v1/stream", getOnly(s.serveStream))
+	api.HandleFunc("/v1/stream/ticket", s.serveTicket)
+
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", s.authorize(api))
+	mux.Handle("/v1/", s.authorize(api))
+	mux.Handle("/", getOnly(dashboard().ServeHTTP))
+	return mux
+}
+
+// authorize rejects requests without the bearer token in the Authorization
+// header. The token is never accepted in the URL, where it would be logged;
+// clients such as EventSource that can't set headers open /v1/stream with a
+// ticket from /v1/stream/ticket instead.
+func (s *Server) authorize(next http.Handler) http.Handler {
+	if s.token == "" {
+		return next
+	}
+	want := []byte(s.token)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got, bearer := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !(bearer && subtle.ConstantTimeCompare([]byte(got), want) == 1) &&
+			!(r.URL.Path == "/v1/stream" && s.redeemTicket(r.URL.Query().Get("ticket"))) {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="bottleneck-check"`)
+			writeError(w, http.StatusUnauthorized, errors.New("missing or invalid bearer token"))
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// Listen opens a TCP address such as "127.0.0.1:9310", or a Unix socket
+// given as "unix:/path/to/socket". A socket file left behind by a process
+// that is no longer listening is replaced.
+func Listen(addr string) (net.Listener, error) {
+	path, ok := strings.CutPrefix(addr, "unix:")
+	if !ok {
+		return net.Listen("tcp", addr)
+	}
+	if info, err := os.Stat(path); err == nil && info.Mode()&os.ModeSocket != 0 {
+		if conn, err := net.Dial("unix", path); err == nil {
+			conn.Close()
+			return nil, fmt.Errorf("%s is in use by another process", path)
+		}
+		if err := os.Remove(path); err != nil {
+			return nil, err
+		}
+	}
+	return net.Listen("unix", path)
+}
+
+// URL returns the base URL to reach a listener, for messages
+func URL(l net.Listener) string {
+	if l.Addr().Network() == "unix" {
+		return "unix:" + l.Addr().String()
+	}
+	return "http://" + l.Addr().String()
+}
@@ -0,0 +1,183 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/xmarkclx/bottleneck-check/analysis"
+	"github.com/xmarkclx/bottleneck-check/history"
+	"github.com/xmarkclx/bottleneck-check/schema"
+)
+
+// DefaultMaxClients is how many streams may be open at once when Options
+// leaves MaxClients zero
+const DefaultMaxClients = 16
+
+const (
+	// clientBuffer is how many events may queue for a stream before the
+	// client is considered too slow and dropped
+	clientBuffer = 32
+	// keepalive is how often an idle stream gets a comment, so proxies
+	// don't close it
+	keepalive = 15 * time.Second
+	// writeTimeout bounds each write to a stream
+	writeTimeout = 10 * time.Second
+)
+
+// Stream event names
+const (
+	// eventTick carries a schema.Document of kind "tick" for each evaluation
+	eventTick = "tick"
+	// eventTransition carries a schema.Transition for each recommendation
+	// that was raised, escalated, de-escalated or resolved
+	eventTransition = "transition"
+	// eventAnnotation carries each annotation recorded through the API
+	eventAnnotation = "annotation"
+)
+
+// client is one open stream. Events are queued on events; dropped is closed
+// when the client is disconnected by the server.
+type client struct {
+	events  chan []byte
+	dropped chan struct{}
+}
+
+// encodeEvent frames a value as a Server-Sent Event
+func encodeEvent(name string, v any) []byte {
+	data, err := json.Marshal(v)
+	if err != nil {
+		// Only non-finite floats can fail, and schema filters them out
+		data, _ = json.Marshal(errorResponse{Error: err.Error()})
+	}
+	var b bytes.Buffer
+	b.WriteString("event: " + name + "\ndata: ")
+	b.Write(data)
+	b.WriteString("\n\n")
+	return b.Bytes()
+}
+
+func tickEvent(state State) []byte {
+	return encodeEvent(eventTick, schema.New(schema.KindTick, state.Snapshot, state.Recommendations, state.Anomalies))
+}
+
+// broadcast queues events for every client, dropping the ones whose queue
+// is full instead of waiting for them. s.mu must be held.
+func (s *Server) broadcast(prev []analysis.Recommendation) {
+	if len(s.clients) == 0 {
+		return
+	}
+	events := [][]byte{tickEvent(s.state)}
+	for _, t := range analysis.Transitions(prev, s.state.Recommendations, s.state.Updated) {
+		events = append(events, encodeEvent(eventTransition, schema.NewTransition(t)))
+	}
+	for c := range s.clients {
+		if !c.enqueue(events) {
+			s.drop(c)
+		}
+	}
+}
+
+// announce pushes an annotation to every client
+func (s *Server) announce(a history.Annotation) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	event := encodeEvent(eventAnnotation, annotation{Time: a.Time, Text: a.Text, Source: a.Source})
+	for c := range s.clients {
+		if !c.enqueue([][]byte{event}) {
+			s.drop(c)
+		}
+	}
+}
+
+// enqueue queues events without blocking and reports whether all fit
+func (c *client) enqueue(events [][]byte) bool {
+	for _, event := range events {
+		select {
+		case c.events <- event:
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// drop disconnects a client. s.mu must be held.
+func (s *Server) drop(c *client) {
+	if _, ok := s.clients[c]; ok {
+		delete(s.clients, c)
+		close(c.dropped)
+	}
+}
+
+// Close disconnects all streaming clients, e.g. before shutting down the
+// HTTP server, which would otherwise wait for them
+func (s *Server) Close() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for c := range s.clients {
+		s.drop(c)
+	}
+}
+
+// serveStream pushes a tick event for every evaluation and a transition
+// event for every recommendation change, as Server-Sent Events
+func (s *Server) serveStream(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	if len(s.clients) >= s.maxClients {
+		s.mu.Unlock()
+		writeError(w, http.StatusServiceUnavailable, errors.New("too many streaming clients"))
+		return
+	}
+	c := &client{events: make(chan []byte, clientBuffer), dropped: make(chan struct{})}
+	s.clients[c] = struct{}{}
+	state := s.state
+	s.mu.Unlock()
+	defer func() {
+		s.mu.Lock()
+		s.drop(c)
+		s.mu.Unlock()
+	}()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no")
+	rc := http.NewResponseController(w)
+	write := func(b []byte) error {
+		rc.SetWriteDeadline(time.Now().Add(writeTimeout))
+		if _, err := w.Write(b); err != nil {
+			return err
+		}
+		return rc.Flush()
+	}
+
+	// Start with the current state so clients needn't wait for the next tick
+	first := []byte(": connected\n\n")
+	if state.Snapshot != nil {
+		first = tickEvent(state)
+	}
+	if write(first) != nil {
+		return
+	}
+
+	ticker := time.NewTicker(keepalive)
+	defer ticker.Stop()
+	for {
+		select {
+		case event := <-c.events:
+			if write(event) != nil {
+				return
+			}
+		case <-ticker.C:
+			if write([]byte(": keepalive\n\n")) != nil {
+				return
+			}
+		case <-c.dropped:
+			return
+		case <-r.Context().Done():
+			return
+		}
+	}
+}
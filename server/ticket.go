@@ -0,0 +1,61 @@
+package server
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// ticketLifetime is how long a stream ticket can be redeemed after it is
+// issued
+const ticketLifetime = 30 * time.Second
+
+// ticketResponse is the body of POST /v1/stream/ticket
+type ticketResponse struct {
+	Ticket  string    `json:"ticket"`
+	Expires time.Time `json:"expires"`
+}
+
+// serveTicket issues a single-use ticket that opens one stream without the
+// token. EventSource can't send headers, and a token in the URL would end
+// up in access logs, proxy logs and browser history.
+func (s *Server) serveTicket(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", "POST")
+		writeError(w, http.StatusMethodNotAllowed, fmt.Errorf("method %s not allowed", r.Method))
+		return
+	}
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	ticket := hex.EncodeToString(b[:])
+	now := time.Now()
+	expires := now.Add(ticketLifetime)
+
+	s.mu.Lock()
+	for t, exp := range s.tickets {
+		if now.After(exp) {
+			delete(s.tickets, t)
+		}
+	}
+	s.tickets[ticket] = expires
+	s.mu.Unlock()
+	writeJSON(w, http.StatusOK, ticketResponse{Ticket: ticket, Expires: expires})
+}
+
+// redeemTicket reports whether ticket was issued and has neither expired
+// nor been used
+func (s *Server) redeemTicket(ticket string) bool {
+	if ticket == "" {
+		return false
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	expires, ok := s.tickets[ticket]
+	delete(s.tickets, ticket)
+	return ok && time.Now().Before(expires)
+}
@@ -0,0 +1,350 @@
+// Dashboard for bottleneck-check serve. It loads recent history once, then
+// follows /v1/stream for live updates. No external dependencies.
+"use strict";
+
+const WINDOW_MS = 30 * 60 * 1000;
+const TOKEN_KEY = "bottleneck-check-token";
+// Wait before opening a stream again after it failed
+const RECONNECT_MS = 3000;
+const GIB = 1024 * 1024 * 1024;
+
+const CHARTS = {
+  "cpu.usage": { value: s => s.cpu.usage_percent, max: () => 100, format: v => v.toFixed(1) + "%" },
+  "cpu.load1": { value: s => s.cpu.load_average[0], max: (s, top) => Math.max(s.cpu.cores, top) * 1.1, format: v => v.toFixed(2) },
+  "memory.used_percent": { value: s => s.memory.used_percent, max: () => 100, format: v => v.toFixed(1) + "%" },
+  "swap.used_bytes": { value: s => s.memory.swap_used_bytes, max: (s, top) => Math.max(s.memory.swap_total_bytes, top, GIB), format: bytes },
+};
+const CAPTIONS = { "cpu.usage": "c-cpu", "cpu.load1": "c-load", "memory.used_percent": "c-memory", "swap.used_bytes": "c-swap" };
+
+const SEVERITIES = [
+  ["critical", "🚨 CRITICAL"],
+  ["high", "⚠️ HIGH"],
+  ["medium", "📋 MEDIUM"],
+  ["low", "💡 LOW"],
+];
+
+const series = {};
+let annotations = [];
+let latest = null;
+let stream = null;
+
+const $ = id => document.getElementById(id);
+
+function bytes(v) {
+  if (v >= GIB) return (v / GIB).toFixed(1) + "GB";
+  return (v / (1024 * 1024)).toFixed(0) + "MB";
+}
+
+function duration(seconds) {
+  seconds = Math.round(seconds);
+  const h = Math.floor(seconds / 3600), m = Math.floor(seconds % 3600 / 60), s = seconds % 60;
+  if (h > 0) return h + "h" + m + "m";
+  if (m > 0) return m + "m" + s + "s";
+  return s + "s";
+}
+
+function uptime(seconds) {
+  const days = Math.floor(seconds / 86400), hours = Math.floor(seconds % 86400 / 3600), minutes = Math.floor(seconds % 3600 / 60);
+  if (days > 0) return `${days} days, ${hours} hours, ${minutes} minutes`;
+  if (hours > 0) return `${hours} hours, ${minutes} minutes`;
+  return `${minutes} minutes`;
+}
+
+// level mirrors the color thresholds of the terminal quick status
+function level(value, warn, bad) {
+  if (value > bad) return "bad";
+  if (value > warn) return "warn";
+  return "good";
+}
+
+function setValue(id, text, cls) {
+  const el = $(id);
+  el.textContent = text;
+  el.className = "value " + (cls || "");
+}
+
+function token() {
+  return localStorage.getItem(TOKEN_KEY) || "";
+}
+
+async function api(path, method = "GET") {
+  const headers = token() ? { Authorization: "Bearer " + token() } : {};
+  const resp = await fetch(path, { method, headers });
+  if (resp.status === 401) {
+    throw Object.assign(new Error("unauthorized"), { unauthorized: true });
+  }
+  return resp;
+}
+
+function askToken() {
+  const dialog = $("login");
+  dialog.addEventListener("close", () => {
+    localStorage.setItem(TOKEN_KEY, $("token").value.trim());
+    start();
+  }, { once: true });
+  dialog.showModal();
+}
+
+async function loadHistory() {
+  const from = "30m";
+  await Promise.all(Object.keys(CHARTS).map(async metric => {
+    const resp = await api(`v1/history?metric=${metric}&from=${from}&res=raw`);
+    if (!resp.ok) return; // history recording is off; charts fill from the stream
+    const body = await resp.json();
+    series[metric] = body.points.map(p => ({ t: Date.parse(p.time), v: p.avg }));
+  }));
+}
+
+// loadAnnotations fetches the annotations in the chart window; those made
+// through the API also arrive on the stream, others on the next reload
+async function loadAnnotations() {
+  const resp = await api("v1/annotations?from=30m");
+  if (!resp.ok) return;
+  const body = await resp.json();
+  annotations = body.annotations.map(a => ({ t: Date.parse(a.time), text: a.text }));
+  drawCharts();
+}
+
+function push(doc) {
+  const s = doc.snapshot;
+  if (!s) return;
+  const t = Date.parse(s.time);
+  for (const [metric, chart] of Object.entries(CHARTS)) {
+    const points = series[metric] || (series[metric] = []);
+    if (points.length && points[points.length - 1].t >= t) continue;
+    points.push({ t, v: chart.value(s) });
+    while (points.length && points[0].t < t - WINDOW_MS) points.shift();
+  }
+}
+
+function render(doc) {
+  latest = doc;
+  const s = doc.snapshot;
+  $("host").textContent = doc.host || "";
+  $("updated").textContent = "Last updated: " + new Date(doc.time).toLocaleTimeString();
+  const health = $("health");
+  health.textContent = `health ${doc.health.score} · ${doc.health.status}`;
+  health.className = "badge " + { ok: "good", degraded: "warn", critical: "bad" }[doc.health.status];
+  if (!s) return;
+
+  const cores = s.cpu.cores;
+  setValue("q-cpu", s.cpu.usage_percent.toFixed(1) + "%", level(s.cpu.usage_percent, 60, 80));
+  setValue("q-load", s.cpu.load_average[0].toFixed(2), level(s.cpu.load_average[0], cores, cores * 1.5));
+  setValue("q-memory", s.memory.used_percent.toFixed(1) + "%", level(s.memory.used_percent, 75, 90));
+  const swap = s.memory.swap_used_bytes;
+  setValue("q-swap", swap > 0 ? bytes(swap) : "none", swap > 2 * GIB ? "bad" : swap > 0 ? "warn" : "good");
+  setValue("q-pressure", s.memory.pressure, { normal: "good", warning: "warn", critical: "bad" }[s.memory.pressure]);
+
+  renderRecommendations(doc.recommendations);
+  renderAnomalies(doc.anomalies);
+  renderDetails(s);
+  drawCharts();
+}
+
+function renderRecommendations(recs) {
+  const list = $("rec-list");
+  list.replaceChildren();
+  if (recs.length === 0) {
+    const ok = document.createElement("p");
+    ok.className = "ok";
+    ok.textContent = "✅ Great! No bottlenecks detected. Your system appears to be running optimally.";
+    list.append(ok);
+    return;
+  }
+  for (const [severity, title] of SEVERITIES) {
+    const group = recs.filter(r => r.severity === severity);
+    if (group.length === 0) continue;
+    const section = document.createElement("div");
+    section.className = "group";
+    const heading = document.createElement("h3");
+    heading.className = severity;
+    heading.textContent = title;
+    section.append(heading);
+    for (const rec of group) {
+      const item = document.createElement("div");
+      item.className = "rec";
+      const reason = document.createElement("div");
+      reason.className = severity;
+      reason.textContent = `• ${rec.component} (${rec.reason})`;
+      if (rec.duration_seconds) {
+        const held = document.createElement("span");
+        held.className = "held";
+        held.textContent = " — for " + duration(rec.duration_seconds);
+        reason.append(held);
+      }
+      const suggestion = document.createElement("div");
+      suggestion.className = "suggestion";
+      suggestion.textContent = "→ " + rec.suggestion;
+      item.append(reason, suggestion);
+      section.append(item);
+    }
+    list.append(section);
+  }
+}
+
+function renderAnomalies(anomalies) {
+  $("anomalies-panel").hidden = anomalies.length === 0;
+  $("anomalies").replaceChildren(...anomalies.map(a => {
+    const li = document.createElement("li");
+    li.className = Math.abs(a.z) >= 4 ? "bad" : "warn";
+    li.textContent = `${a.metric}: ${a.value.toFixed(1)}, normally ${a.expected.toFixed(1)} ± ${a.stddev.toFixed(1)} (${a.z > 0 ? "+" : ""}${a.z.toFixed(1)}σ)`;
+    return li;
+  }));
+}
+
+function renderDetails(s) {
+  $("d-cpu").textContent = `${s.cpu.model} (${s.cpu.cores} cores)`;
+  $("d-gpu").textContent = s.gpu.model || "unknown";
+  $("d-ram").textContent = (s.memory.total_bytes / GIB).toFixed(1) + "GB";
+  $("d-usage").textContent = s.cpu.usage_percent.toFixed(1) + "%";
+  const [l1, l5, l15] = s.cpu.load_average;
+  $("d-load").textContent = `${l1.toFixed(2)} (1m), ${l5.toFixed(2)} (5m), ${l15.toFixed(2)} (15m)`;
+  $("d-memory").textContent = `${s.memory.used_percent.toFixed(1)}% (${(s.memory.used_bytes / GIB).toFixed(1)}GB used)`;
+  $("d-swap").textContent = s.memory.swap_used_bytes > 0 ? bytes(s.memory.swap_used_bytes) : "none";
+  $("d-pressure").textContent = s.memory.pressure;
+  $("d-uptime").textContent = s.uptime_seconds > 0 ? uptime(s.uptime_seconds) : "unknown";
+  $("processes").replaceChildren(...s.processes.map(p => {
+    const row = document.createElement("tr");
+    for (const [text, cls] of [[p.pid, ""], [p.name, ""], [bytes(p.rss_bytes), "num"]]) {
+      const cell = document.createElement("td");
+      cell.textContent = text;
+      cell.className = cls;
+      row.append(cell);
+    }
+    return row;
+  }));
+}
+
+function drawCharts() {
+  if (!latest || !latest.snapshot) return;
+  const now = Date.parse(latest.snapshot.time);
+  const style = getComputedStyle(document.documentElement);
+  for (const canvas of document.querySelectorAll("canvas[data-metric]")) {
+    const metric = canvas.dataset.metric;
+    const chart = CHARTS[metric];
+    const points = series[metric] || [];
+    const top = points.reduce((m, p) => Math.max(m, p.v), 0);
+    const max = chart.max(latest.snapshot, top) || 1;
+    const last = points[points.length - 1];
+    $(CAPTIONS[metric]).textContent = last ? chart.format(last.v) : "";
+
+    const ratio = window.devicePixelRatio || 1;
+    const width = canvas.clientWidth, height = canvas.clientHeight;
+    canvas.width = width * ratio;
+    canvas.height = height * ratio;
+    const ctx = canvas.getContext("2d");
+    ctx.scale(ratio, ratio);
+    ctx.clearRect(0, 0, width, height);
+
+    ctx.strokeStyle = style.getPropertyValue("--border");
+    ctx.lineWidth = 1;
+    for (let i = 0; i <= 4; i++) {
+      const y = Math.round(height * i / 4) + 0.5;
+      ctx.beginPath();
+      ctx.moveTo(0, y);
+      ctx.lineTo(width, y);
+      ctx.stroke();
+    }
+    if (points.length < 2) continue;
+
+    const x = t => (t - (now - WINDOW_MS)) / WINDOW_MS * width;
+    const y = v => height - Math.min(v / max, 1) * (height - 2) - 1;
+    const color = style.getPropertyValue(metric.startsWith("cpu") ? "--blue" : "--purple");
+    ctx.beginPath();
+    points.forEach((p, i) => i === 0 ? ctx.moveTo(x(p.t), y(p.v)) : ctx.lineTo(x(p.t), y(p.v)));
+    ctx.strokeStyle = color;
+    ctx.lineWidth = 1.5;
+    ctx.stroke();
+    ctx.lineTo(x(last.t), height);
+    ctx.lineTo(x(points[0].t), height);
+    ctx.closePath();
+    ctx.globalAlpha = 0.15;
+    ctx.fillStyle = color;
+    ctx.fill();
+    ctx.globalAlpha = 1;
+
+    // Annotations as dashed lines, labelled on the first chart
+    ctx.strokeStyle = ctx.fillStyle = style.getPropertyValue("--yellow");
+    ctx.setLineDash([3, 3]);
+    ctx.lineWidth = 1;
+    ctx.font = "11px sans-serif";
+    const first = canvas === document.querySelector("canvas[data-metric]");
+    for (const a of annotations) {
+      if (a.t < now - WINDOW_MS || a.t > now) continue;
+      const ax = Math.round(x(a.t)) + 0.5;
+      ctx.beginPath();
+      ctx.moveTo(ax, 0);
+      ctx.lineTo(ax, height);
+      ctx.stroke();
+      if (first) ctx.fillText(a.text, Math.min(ax + 3, width - ctx.measureText(a.text).width - 2), 11);
+    }
+    ctx.setLineDash([]);
+  }
+}
+
+async function connect() {
+  // EventSource can't send headers, and the token mustn't go in the URL,
+  // so the stream is opened with a single-use ticket
+  let query = "";
+  if (token()) {
+    const resp = await api("v1/stream/ticket", "POST");
+    if (!resp.ok) throw new Error("no stream ticket: " + resp.status);
+    query = "?ticket=" + (await resp.json()).ticket;
+  }
+  if (stream) stream.close();
+  stream = new EventSource("v1/stream" + query);
+  const badge = $("connection");
+  stream.onopen = () => {
+    badge.textContent = "live";
+    badge.className = "badge online";
+  };
+  stream.onerror = () => {
+    badge.textContent = "reconnecting";
+    badge.className = "badge offline";
+    // EventSource reconnects on its own, but a used ticket is refused, so
+    // once it gives up connect again with a new one
+    if (stream.readyState === EventSource.CLOSED) {
+      setTimeout(reconnect, RECONNECT_MS);
+    }
+  };
+  stream.addEventListener("tick", e => {
+    const doc = JSON.parse(e.data);
+    push(doc);
+    render(doc);
+  });
+  stream.addEventListener("annotation", e => {
+    const a = JSON.parse(e.data);
+    annotations.push({ t: Date.parse(a.time), text: a.text });
+    drawCharts();
+  });
+}
+
+async function reconnect() {
+  try {
+    await connect();
+  } catch (err) {
+    if (err.unauthorized) {
+      askToken();
+      return;
+    }
+    setTimeout(reconnect, RECONNECT_MS);
+  }
+}
+
+async function start() {
+  if (stream) stream.close();
+  try {
+    await Promise.all([loadHistory(), loadAnnotations()]);
+  } catch (err) {
+    if (err.unauthorized) {
+      askToken();
+      return;
+    }
+  }
+  reconnect();
+}
+
+window.addEventListener("resize", drawCharts);
+// Pick up annotations made from the command line or the monitor
+setInterval(() => loadAnnotations().catch(() => {}), 60 * 1000);
+start();
@@ -0,0 +1,137 @@
+package statusline
+
+import (
+	"encoding/json"
+	"fmt"
+	"html"
+	"io"
+	"math"
+	"strings"
+	"text/template"
+
+	"github.com/xmarkclx/bottleneck-check/analysis"
+)
+
+// Format is an output format for status lines
+type Format string
+
+const (
+	// FormatText prints the templated line, one per update
+	FormatText Format = "text"
+	// FormatI3bar speaks the i3bar protocol, which swaybar shares
+	FormatI3bar Format = "i3bar"
+	// FormatWaybar prints one JSON object per update for a waybar custom
+	// module with "return-type": "json"
+	FormatWaybar Format = "waybar"
+)
+
+// ParseFormat accepts text, i3bar, swaybar or waybar
+func ParseFormat(s string) (Format, error) {
+	switch f := Format(s); f {
+	case FormatText, FormatI3bar, FormatWaybar:
+		return f, nil
+	case "swaybar":
+		return FormatI3bar, nil
+	}
+	return "", fmt.Errorf("unknown status format %q (want text, i3bar, swaybar or waybar)", s)
+}
+
+// BlockName identifies the status block in i3bar click events
+const BlockName = "bottleneck-check"
+
+// i3barHeader starts the i3bar protocol: a header, then an endless array
+// of status lines
+const i3barHeader = `{"version":1}` + "\n[\n"
+
+// i3barBlock is one block of an i3bar status line
+type i3barBlock struct {
+	Name      string `json:"name"`
+	FullText  string `json:"full_text"`
+	ShortText string `json:"short_text,omitempty"`
+	Color     string `json:"color,omitempty"`
+	Urgent    bool   `json:"urgent,omitempty"`
+	Markup    string `json:"markup"`
+}
+
+// waybarOutput is a waybar custom module update. Waybar reads text and
+// tooltip as Pango markup.
+type waybarOutput struct {
+	Text    string   `json:"text"`
+	Alt     string   `json:"alt"`
+	Tooltip string   `json:"tooltip"`
+	Class   []string `json:"class"`
+	// Percentage picks the module's format-icons: the busier of CPU and
+	// memory
+	Percentage int `json:"percentage"`
+}
+
+// Emitter writes status lines in one format
+type Emitter struct {
+	w        io.Writer
+	format   Format
+	template *template.Template
+	short    *template.Template
+	// lines counts the lines written, as the i3bar protocol separates
+	// them with commas
+	lines int
+}
+
+// NewEmitter returns an emitter of lines formatted by t, the text of the
+// i3bar block and the waybar module
+func NewEmitter(w io.Writer, format Format, t *template.Template) *Emitter {
+	return &Emitter{
+		w:        w,
+		format:   format,
+		template: t,
+		short:    template.Must(template.New("short").Parse(shortTemplate)),
+	}
+}
+
+// Emit writes one status line
+func (e *Emitter) Emit(l Line) error {
+	text, err := l.Format(e.template)
+	if err != nil {
+		return err
+	}
+
+	var out []byte
+	switch e.format {
+	case FormatI3bar:
+		short, err := l.Format(e.short)
+		if err != nil {
+			return err
+		}
+		block, err := json.Marshal([]i3barBlock{{
+			Name:      BlockName,
+			FullText:  text,
+			ShortText: short,
+			Color:     l.Color,
+			Urgent:    l.Status == analysis.HealthCritical,
+			Markup:    "none",
+		}})
+		if err != nil {
+			return err
+		}
+		if e.lines == 0 {
+			out = append([]byte(i3barHeader), block...)
+		} else {
+			out = append([]byte{','}, block...)
+		}
+	case FormatWaybar:
+		out, err = json.Marshal(waybarOutput{
+			Text:       html.EscapeString(text),
+			Alt:        strings.ToLower(l.Severity),
+			Tooltip:    html.EscapeString(l.Tooltip()),
+			Class:      []string{l.Status, strings.ToLower(l.Severity)},
+			Percentage: int(math.Round(max(l.CPU, l.Memory))),
+		})
+		if err != nil {
+			return err
+		}
+	default:
+		out = []byte(text)
+	}
+	e.lines++
+	_, err = e.w.Write(append(out, '\n'))
+	return err
+}
@@ -0,0 +1,105 @@
+package statusline
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+	"text/template"
+
+	"github.com/xmarkclx/bottleneck-check/analysis"
+)
+
+func TestEmitI3bar(t *testing.T) {
+	var b bytes.Buffer
+	e := NewEmitter(&b, FormatI3bar, mustParse(t, "{{.Severity}} {{printf \"%.0f\" .CPU}}%"))
+	lines := []Line{
+		{CPU: 12, Severity: SeverityOK, Status: analysis.HealthOK, Color: Color("")},
+		{CPU: 99, Severity: "CRITICAL", Status: analysis.HealthCritical, Color: Color(analysis.SeverityCritical)},
+	}
+	for _, l := range lines {
+		if err := e.Emit(l); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// A header, the opening of the endless array, then one array of blocks
+	// per line, all but the first prefixed with a comma
+	out := b.String()
+	body, ok := strings.CutPrefix(out, "{\"version\":1}\n[\n")
+	if !ok {
+		t.Fatalf("output doesn't start with the i3bar header:\n%s", out)
+	}
+	rows := strings.Split(strings.TrimSuffix(body, "\n"), "\n")
+	if len(rows) != 2 || strings.HasPrefix(rows[0], ",") || !strings.HasPrefix(rows[1], ",") {
+		t.Fatalf("lines are not framed as i3bar expects:\n%s", body)
+	}
+
+	want := [][]i3barBlock{
+		{{Name: BlockName, FullText: "OK 12%", ShortText: "CPU 12% MEM 0% OK", Color: "#5fd75f", Markup: "none"}},
+		{{Name: BlockName, FullText: "CRITICAL 99%", ShortText: "CPU 99% MEM 0% CRITICAL", Color: "#ff5f5f", Urgent: true, Markup: "none"}},
+	}
+	for i, row := range rows {
+		var got []i3barBlock
+		if err := json.Unmarshal([]byte(strings.TrimPrefix(row, ",")), &got); err != nil {
+			t.Fatalf("line %d: %v", i, err)
+		}
+		if !reflect.DeepEqual(got, want[i]) {
+			t.Errorf("line %d = %+v, want %+v", i, got, want[i])
+		}
+	}
+}
+
+func TestEmitWaybar(t *testing.T) {
+	var b bytes.Buffer
+	e := NewEmitter(&b, FormatWaybar, mustParse(t, "<{{.Severity}}> & {{.Count}}"))
+	l := Line{
+		CPU:      40.4,
+		Memory:   71.6,
+		Severity: "HIGH",
+		Status:   analysis.HealthDegraded,
+		Count:    2,
+		Recommendations: []analysis.Recommendation{
+			{Severity: analysis.SeverityHigh, Component: "Memory", Reason: `Swap at 3.1GB <"firefox" & co>`},
+			{Severity: analysis.SeverityLow, Component: "CPU", Reason: "Load 4.2"},
+		},
+	}
+	if err := e.Emit(l); err != nil {
+		t.Fatal(err)
+	}
+	if err := e.Emit(l); err != nil {
+		t.Fatal(err)
+	}
+
+	// One object per line, with no framing
+	rows := strings.Split(strings.TrimSuffix(b.String(), "\n"), "\n")
+	if len(rows) != 2 {
+		t.Fatalf("got %d lines, want 2:\n%s", len(rows), b.String())
+	}
+	var got waybarOutput
+	if err := json.Unmarshal([]byte(rows[1]), &got); err != nil {
+		t.Fatal(err)
+	}
+	// Text and tooltip are Pango markup, so the template's and the
+	// recommendations' own markup characters are escaped
+	want := waybarOutput{
+		Text:       "&lt;HIGH&gt; &amp; 2",
+		Alt:        "high",
+		Tooltip:    "HIGH Memory: Swap at 3.1GB &lt;&#34;firefox&#34; &amp; co&gt;\nLOW CPU: Load 4.2",
+		Class:      []string{analysis.HealthDegraded, "high"},
+		Percentage: 72,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Emit() =\n%+v\nwant\n%+v", got, want)
+	}
+}
+
+func mustParse(t *testing.T, text string) *template.Template {
+	t.Helper()
+	tmpl, err := Parse(text)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tmpl
+}
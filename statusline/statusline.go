@@ -0,0 +1,130 @@
+// Package statusline condenses a snapshot and its recommendations into one
+// line for window manager bars and terminal multiplexers: plain text from a
+// template for tmux and the like, or the JSON that i3bar, swaybar and waybar
+// custom modules read.
+package statusline
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/xmarkclx/bottleneck-check/analysis"
+	"github.com/xmarkclx/bottleneck-check/metrics"
+)
+
+// DefaultTemplate mirrors the monitor's quick status
+const DefaultTemplate = `CPU: {{printf "%.1f" .CPU}}% | Load: {{printf "%.2f" .Load}} | Memory: {{printf "%.1f" .Memory}}%` +
+	`{{if .Swap}} | Swap: {{printf "%.1f" .Swap}}GB{{end}} | {{.Severity}}{{if .Count}} ({{.Count}}){{end}}`
+
+// shortTemplate is the i3bar short_text, shown when the bar runs out of room
+const shortTemplate = `CPU {{printf "%.0f" .CPU}}% MEM {{printf "%.0f" .Memory}}% {{.Severity}}`
+
+// SeverityOK is the severity shown while nothing is active
+const SeverityOK = "OK"
+
+// Line is what a status line shows, and the data its template is executed
+// on
+type Line struct {
+	// CPU is the usage percentage across all cores
+	CPU float64
+	// Load is the 1-minute load average, on Cores logical cores
+	Load  float64
+	Cores int
+	// Memory is the percentage of RAM used
+	Memory float64
+	// Swap is the swap used in GB
+	Swap float64
+	// Severity is the worst active severity, or OK
+	Severity string
+	// Status is the health status: ok, degraded or critical
+	Status string
+	// Score is the health score, from 100 down to 0
+	Score int
+	// Count is how many recommendations are active
+	Count int
+	// Color is the severity's color as #rrggbb, which tmux, i3bar and
+	// waybar all accept
+	Color string
+	Time  time.Time
+	// Recommendations are the active recommendations, most urgent first
+	Recommendations []analysis.Recommendation
+}
+
+// New summarizes a snapshot and the recommendations active for it
+func New(m *metrics.SystemMetrics, recommendations []analysis.Recommendation) Line {
+	health := analysis.Health(recommendations)
+	l := Line{
+		CPU:             m.CPUUsage,
+		Load:            m.LoadAverage[0],
+		Cores:           m.CPUCores,
+		Memory:          m.MemoryPercent(),
+		Swap:            float64(m.SwapUsed) / (1024 * 1024 * 1024),
+		Severity:        SeverityOK,
+		Status:          health.Status,
+		Score:           health.Score,
+		Count:           len(recommendations),
+		Color:           Color(health.Worst),
+		Time:            m.Timestamp,
+		Recommendations: append([]analysis.Recommendation(nil), recommendations...),
+	}
+	sort.SliceStable(l.Recommendations, func(i, j int) bool {
+		return l.Recommendations[i].Severity.Rank() > l.Recommendations[j].Severity.Rank()
+	})
+	if health.Worst != "" {
+		l.Severity = string(health.Worst)
+	}
+	return l
+}
+
+// Color returns the color of a severity, green when there is none, matching
+// the monitor's colors
+func Color(s analysis.Severity) string {
+	switch s {
+	case analysis.SeverityCritical:
+		return "#ff5f5f"
+	case analysis.SeverityHigh, analysis.SeverityMedium:
+		return "#ffd75f"
+	}
+	return "#5fd75f"
+}
+
+// Parse parses a line template in text/template syntax, e.g.
+// "{{.Severity}} cpu {{printf \"%.0f\" .CPU}}%". It is tried on an empty
+// line so a misspelled field is reported now rather than on every update.
+func Parse(text string) (*template.Template, error) {
+	t, err := template.New("status").Parse(text)
+	if err != nil {
+		return nil, err
+	}
+	if err := t.Execute(io.Discard, Line{}); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// Format executes a template on the line. Line breaks become spaces, as a
+// status line is one line.
+func (l Line) Format(t *template.Template) (string, error) {
+	var b strings.Builder
+	if err := t.Execute(&b, l); err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(strings.ReplaceAll(b.String(), "\n", " ")), nil
+}
+
+// Tooltip lists the active recommendations, one per line, most urgent
+// first
+func (l Line) Tooltip() string {
+	if len(l.Recommendations) == 0 {
+		return "No active recommendations"
+	}
+	lines := make([]string, 0, len(l.Recommendations))
+	for _, rec := range l.Recommendations {
+		lines = append(lines, fmt.Sprintf("%s %s: %s", rec.Severity, rec.Component, rec.Reason))
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,405 @@
+package tui
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/xmarkclx/bottleneck-check/analysis"
+	"github.com/xmarkclx/bottleneck-check/anomaly"
+	"github.com/xmarkclx/bottleneck-check/events"
+	"github.com/xmarkclx/bottleneck-check/history"
+	"github.com/xmarkclx/bottleneck-check/metrics"
+	"github.com/xmarkclx/bottleneck-check/render"
+)
+
+// State is everything the monitor shows, as of its last refresh
+type State struct {
+	Snapshot        *metrics.SystemMetrics
+	Recommendations []analysis.Recommendation
+	Anomalies       []anomaly.Anomaly
+	// History is the sampler's in-memory history, oldest first
+	History []metrics.SystemMetrics
+	// Trend holds the charted metrics for longer than History
+	Trend   *Trend
+	Updated time.Time
+
+	SampleInterval time.Duration
+	Refresh        time.Duration
+	// Window is how long usage alerts must hold before they're raised
+	Window time.Duration
+
+	// StoreDir is where history is recorded, empty when it isn't
+	StoreDir string
+	StoreErr error
+	Baseline *anomaly.Baseline
+
+	// Events are the recommendation transitions logged, oldest first.
+	// EventsPath is the file they're kept in, empty if only in memory.
+	Events     []events.Event
+	EventsPath string
+	EventsErr  error
+	// Annotations are those of the last day, oldest first
+	Annotations []history.Annotation
+
+	// Profile is what the terminal can show, set by the app. Without
+	// colors, views mark with symbols what they would only highlight.
+	Profile render.Profile
+}
+
+// View is one screen of the monitor
+type View interface {
+	// Title is shown in the tab bar
+	Title() string
+	// Key switches to the view
+	Key() rune
+	// Render writes the view's content for a terminal width cells wide.
+	// Longer lines are wrapped and the content scrolls if it's taller than
+	// the screen.
+	Render(w io.Writer, s *State, width int)
+}
+
+// KeyHandler is implemented by views that handle keys of their own. The
+// view sees each key before the app; HandleKey reports whether it used it.
+type KeyHandler interface {
+	HandleKey(k Key) bool
+}
+
+// Fitter is implemented by views that fit their content to the screen
+// themselves, such as tables that keep a selection in sight. The app tells
+// them how many rows they have before each Render and doesn't scroll them.
+type Fitter interface {
+	Fit(rows int)
+}
+
+// Hinter is implemented by views with keys of their own, to show in the
+// footer instead of the scrolling hints
+type Hinter interface {
+	Hints() string
+}
+
+// Redrawer is implemented by views that gather data in the background
+// rather than in Render, so drawing and keys never wait on it. The app
+// gives them a function to call when they have something new to draw.
+type Redrawer interface {
+	SetRedraw(redraw func())
+}
+
+// Action tells the caller what to do after a key press
+type Action int
+
+const (
+	ActionNone Action = iota
+	ActionQuit
+	ActionRefresh
+	// ActionAnnotate means an annotation was entered; see Annotation
+	ActionAnnotate
+)
+
+// App is the full-screen monitor: a header with a tab per view, the
+// current view's scrollable content and a footer with key hints
+type App struct {
+	views    []View
+	current  int
+	previous int
+	// scroll is each view's scroll offset, kept while other views are shown
+	scroll []int
+	state  State
+	host   string
+	// profile is what the terminal can show; frames are adapted to it
+	profile render.Profile
+
+	width, height int
+	body          []string
+
+	// annotation is the note being typed after pressing a, and annotating
+	// whether the prompt is open; annotateAt is when it was opened
+	annotation string
+	annotating bool
+	annotateAt time.Time
+	// notice is shown in the footer until the next key
+	notice    string
+	noticeErr bool
+	// redraws receives when a view has something new to draw
+	redraws chan struct{}
+}
+
+// header and footer rows around the view's content
+const (
+	headerRows = 3
+	footerRows = 1
+)
+
+// NewApp returns an app showing views, the first of them initially. A help
+// view listing every view's key is added at the end.
+func NewApp(views ...View) *App {
+	a := &App{width: 80, height: 24, profile: render.Full, redraws: make(chan struct{}, 1)}
+	a.views = append(append([]View{}, views...), &helpView{app: a})
+	for _, v := range a.views {
+		if r, ok := v.(Redrawer); ok {
+			r.SetRedraw(a.requestRedraw)
+		}
+	}
+	a.scroll = make([]int, len(a.views))
+	a.host, _ = os.Hostname()
+	return a
+}
+
+// SetState replaces what the views show
+func (a *App) SetState(s State) {
+	a.state = s
+}
+
+// SetProfile sets what the terminal can show, by default everything
+func (a *App) SetProfile(p render.Profile) {
+	a.profile = p
+}
+
+// Redraws receives when a view has gathered something new in the
+// background; draw a new frame then
+func (a *App) Redraws() <-chan struct{} {
+	return a.redraws
+}
+
+// requestRedraw is safe to call from any goroutine; requests made before
+// the next frame is drawn are merged
+func (a *App) requestRedraw() {
+	select {
+	case a.redraws <- struct{}{}:
+	default:
+	}
+}
+
+// Resize sets the terminal size the frame is laid out for
+func (a *App) Resize(width, height int) {
+	a.width, a.height = width, height
+}
+
+// HandleKey applies a key press
+func (a *App) HandleKey(k Key) Action {
+	a.notice = ""
+	if a.annotating {
+		return a.annotateKey(k)
+	}
+	if h, ok := a.views[a.current].(KeyHandler); ok && h.HandleKey(k) {
+		return ActionNone
+	}
+
+	switch {
+	case k.Code == KeyCtrlC || k.Is('q'):
+		return ActionQuit
+	case k.Is('r'):
+		return ActionRefresh
+	case k.Code == KeyEsc:
+		a.show(0)
+	case k.Code == KeyBackspace:
+		a.show(a.previous)
+	case k.Code == KeyTab || k.Code == KeyRight:
+		a.show((a.current + 1) % len(a.views))
+	case k.Code == KeyLeft:
+		a.show((a.current + len(a.views) - 1) % len(a.views))
+	case k.Code == KeyUp || k.Is('k'):
+		a.scrollBy(-1)
+	case k.Code == KeyDown || k.Is('j'):
+		a.scrollBy(1)
+	case k.Code == KeyPgUp || k.Is('b'):
+		a.scrollBy(-a.bodyHeight())
+	case k.Code == KeyPgDn || k.Is(' '):
+		a.scrollBy(a.bodyHeight())
+	case k.Code == KeyHome:
+		a.scroll[a.current] = 0
+	case k.Code == KeyEnd:
+		a.scroll[a.current] = len(a.body)
+	case k.Is('?'):
+		a.show(len(a.views) - 1)
+	case k.Is('a'):
+		a.annotating, a.annotation, a.annotateAt = true, "", time.Now()
+	case k.Code == KeyRune && k.Rune >= '1' && k.Rune <= '9':
+		if i := int(k.Rune - '1'); i < len(a.views) {
+			a.show(i)
+		}
+	case k.Code == KeyRune:
+		for i, v := range a.views {
+			if v.Key() == k.Rune {
+				a.show(i)
+			}
+		}
+	}
+	return ActionNone
+}
+
+// annotateKey edits the annotation prompt
+func (a *App) annotateKey(k Key) Action {
+	switch {
+	case k.Code == KeyCtrlC:
+		return ActionQuit
+	case k.Code == KeyEsc:
+		a.annotating = false
+	case k.Code == KeyEnter:
+		a.annotating = false
+		if strings.TrimSpace(a.annotation) != "" {
+			return ActionAnnotate
+		}
+	case k.Code == KeyBackspace:
+		if n := len([]rune(a.annotation)); n > 0 {
+			a.annotation = string([]rune(a.annotation)[:n-1])
+		}
+	case k.Code == KeyRune:
+		a.annotation += string(k.Rune)
+	}
+	return ActionNone
+}
+
+// Annotation returns the text of the annotation just entered and when the
+// prompt for it was opened, the moment it marks
+func (a *App) Annotation() (string, time.Time) {
+	return strings.TrimSpace(a.annotation), a.annotateAt
+}
+
+// Notify shows a message in the footer until the next key press
+func (a *App) Notify(message string, isErr bool) {
+	a.notice, a.noticeErr = message, isErr
+}
+
+// show switches to a view, remembering the current one for Backspace. The
+// view left behind keeps its scroll position and any state of its own.
+func (a *App) show(view int) {
+	if view != a.current {
+		a.previous, a.current = a.current, view
+	}
+}
+
+func (a *App) scrollBy(lines int) {
+	a.scroll[a.current] += lines
+}
+
+func (a *App) bodyHeight() int {
+	return max(a.height-headerRows-footerRows, 1)
+}
+
+// Frame lays out the screen: exactly one line per terminal row, none wider
+// than the terminal
+func (a *App) Frame() []string {
+	var buf bytes.Buffer
+	height := a.bodyHeight()
+	if f, ok := a.views[a.current].(Fitter); ok {
+		f.Fit(height)
+	}
+	if a.state.Snapshot == nil {
+		fmt.Fprintf(&buf, "%sCollecting the first sample...%s\n", render.ColorYellow, render.ColorReset)
+	} else {
+		state := a.state
+		state.Profile = a.profile
+		a.views[a.current].Render(&buf, &state, a.width)
+	}
+	// Adapt before wrapping, as symbols may change width in ASCII
+	a.body = a.body[:0]
+	for _, line := range strings.Split(strings.TrimRight(a.profile.Adapt(buf.String()), "\n"), "\n") {
+		a.body = append(a.body, Wrap(line, a.width)...)
+	}
+
+	// Keep the scroll offset within the content, even after a resize
+	top := min(a.scroll[a.current], len(a.body)-height)
+	top = max(top, 0)
+	a.scroll[a.current] = top
+
+	frame := make([]string, 0, a.height)
+	frame = append(frame, a.titleLine(), a.tabLine(), render.ColorBlue+strings.Repeat("─", a.width)+render.ColorReset)
+	for row := 0; row < height; row++ {
+		line := ""
+		if top+row < len(a.body) {
+			line = a.body[top+row]
+		}
+		frame = append(frame, line)
+	}
+	frame = append(frame, a.footerLine(top, height))
+
+	// A terminal too short for the header and footer still gets one row
+	// per line
+	if len(frame) > a.height {
+		frame = frame[:max(a.height, 1)]
+	}
+	for i, line := range frame {
+		frame[i] = Truncate(a.profile.Adapt(line), a.width)
+	}
+	return frame
+}
+
+func (a *App) titleLine() string {
+	left := render.ColorBold + render.ColorCyan + "🔍 System Bottleneck Monitor" + render.ColorReset
+	right := a.host
+	if !a.state.Updated.IsZero() {
+		right += " · updated " + a.state.Updated.Format("15:04:05")
+	}
+	return spread(a.profile.Adapt(left), a.profile.Adapt(right), a.width)
+}
+
+// tabLine lists the views with their keys, or just their titles if that
+// doesn't fit
+func (a *App) tabLine() string {
+	line := a.tabs(true)
+	if Width(line) > a.width {
+		line = a.tabs(false)
+	}
+	return line
+}
+
+func (a *App) tabs(withKeys bool) string {
+	var b strings.Builder
+	for i, v := range a.views {
+		label := " " + v.Title() + " "
+		if withKeys {
+			label = fmt.Sprintf(" %s [%c] ", v.Title(), v.Key())
+		}
+		switch {
+		case i == a.current && !a.profile.Color:
+			// Without reverse video, bracket the current view instead
+			b.WriteString("<" + label[1:len(label)-1] + ">")
+		case i == a.current:
+			b.WriteString("\x1b[7m" + render.ColorBold + label + render.ColorReset)
+		default:
+			b.WriteString(label)
+		}
+		b.WriteString(" ")
+	}
+	return b.String()
+}
+
+func (a *App) footerLine(top, height int) string {
+	switch {
+	case a.annotating:
+		return fmt.Sprintf("Annotate %s: %s█  %s(Enter saves, Esc cancels)%s",
+			a.annotateAt.Format("15:04:05"), a.annotation, render.ColorCyan, render.ColorReset)
+	case a.notice != "":
+		color := render.ColorGreen
+		if a.noticeErr {
+			color = render.ColorRed
+		}
+		return color + a.notice + render.ColorReset
+	}
+	if h, ok := a.views[a.current].(Hinter); ok {
+		return spread(a.profile.Adapt(h.Hints()), "", a.width)
+	}
+	hints := fmt.Sprintf("%s↑↓%s scroll  %sTab%s next  %sEsc%s overview  %sa%s annotate  %sr%s refresh  %sq%s quit",
+		render.ColorGreen, render.ColorReset, render.ColorGreen, render.ColorReset,
+		render.ColorGreen, render.ColorReset, render.ColorGreen, render.ColorReset,
+		render.ColorGreen, render.ColorReset, render.ColorGreen, render.ColorReset)
+	position := ""
+	if len(a.body) > height {
+		position = fmt.Sprintf("%d-%d/%d", top+1, min(top+height, len(a.body)), len(a.body))
+	}
+	return spread(a.profile.Adapt(hints), position, a.width)
+}
+
+// spread puts left and right at either end of a line width cells wide,
+// dropping right if they don't both fit
+func spread(left, right string, width int) string {
+	gap := width - Width(left) - Width(right)
+	if right == "" || gap < 1 {
+		return left
+	}
+	return left + strings.Repeat(" ", gap) + right
+}
@@ -0,0 +1,160 @@
+package tui
+
+import (
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/xmarkclx/bottleneck-check/analysis"
+	"github.com/xmarkclx/bottleneck-check/metrics"
+	"github.com/xmarkclx/bottleneck-check/render"
+)
+
+// componentProcesses is how many processes a component view lists
+const componentProcesses = 10
+
+// ComponentView drills into one component: its readings, charts of its
+// history, its recommendations and the processes using the most of it.
+// Like every view it keeps its chart window and scroll position while
+// other views are shown.
+type ComponentView struct {
+	component string // as in analysis.Recommendation.Component
+	title     string
+	key       rune
+	readings  func(w io.Writer, m *metrics.SystemMetrics)
+	charts    []componentChart
+	// usage orders processes by their use of the component; nil when
+	// there is no per-process figure
+	usage     func(a, b metrics.ProcessInfo) bool
+	usageName string
+
+	window int // index into ChartWindows
+}
+
+// componentChart is one chart of a ComponentView
+type componentChart struct {
+	title  string
+	metric string
+	top    func(m *metrics.SystemMetrics) float64
+	format func(float64) string
+	// when reports whether the chart applies to this system; nil means
+	// always
+	when func(m *metrics.SystemMetrics) bool
+}
+
+// NewCPUView returns the CPU drill-down view
+func NewCPUView() *ComponentView {
+	return &ComponentView{
+		component: "CPU",
+		title:     "🖥️  CPU",
+		key:       'c',
+		readings: func(w io.Writer, m *metrics.SystemMetrics) {
+			fmt.Fprintf(w, "  Model: %s (%d cores)\n", m.CPUModel, m.CPUCores)
+			fmt.Fprintf(w, "  Usage: %.1f%%\n", m.CPUUsage)
+			fmt.Fprintf(w, "  Load Averages: %.2f (1m), %.2f (5m), %.2f (15m)\n",
+				m.LoadAverage[0], m.LoadAverage[1], m.LoadAverage[2])
+		},
+		charts: []componentChart{
+			{title: "Usage", metric: metrics.MetricCPUUsage, top: fixed(100), format: render.FormatValue("percent")},
+			// Load has no fixed ceiling; scale it to its peak
+			{title: "Load (1m)", metric: metrics.MetricLoad1, top: fixed(0), format: render.FormatValue("")},
+		},
+		usage:     func(a, b metrics.ProcessInfo) bool { return a.CPUPercent > b.CPUPercent },
+		usageName: "CPU",
+	}
+}
+
+// NewMemoryView returns the memory drill-down view
+func NewMemoryView() *ComponentView {
+	return &ComponentView{
+		component: "Memory",
+		title:     "💾 Memory",
+		key:       'm',
+		readings: func(w io.Writer, m *metrics.SystemMetrics) {
+			fmt.Fprintf(w, "  RAM: %s used / %s total (%.1f%%)\n", render.FormatBytes(m.MemoryUsed), render.FormatBytes(m.MemoryTotal), m.MemoryPercent())
+			if m.SwapTotal > 0 {
+				fmt.Fprintf(w, "  Swap: %s used / %s total\n", render.FormatBytes(m.SwapUsed), render.FormatBytes(m.SwapTotal))
+			}
+			fmt.Fprintf(w, "  Pressure: %s\n", m.MemPressure)
+			if m.MemorySpeed != "" {
+				fmt.Fprintf(w, "  Speed: %s\n", m.MemorySpeed)
+			}
+		},
+		charts: []componentChart{
+			{title: "RAM", metric: metrics.MetricMemoryUsedPercent, top: fixed(100), format: render.FormatValue("percent")},
+			{title: "Swap", metric: metrics.MetricSwapUsedBytes, top: func(m *metrics.SystemMetrics) float64 { return float64(m.SwapTotal) },
+				format: render.FormatValue("bytes"), when: func(m *metrics.SystemMetrics) bool { return m.SwapTotal > 0 }},
+			{title: "Pressure", metric: metrics.MetricMemoryPressure, top: fixed(2), format: pressureName},
+		},
+		usage:     func(a, b metrics.ProcessInfo) bool { return a.RSS > b.RSS },
+		usageName: "memory",
+	}
+}
+
+// NewGPUView returns the GPU drill-down view. GPU usage isn't measured, so
+// it has no charts or process list.
+func NewGPUView() *ComponentView {
+	return &ComponentView{
+		component: "GPU",
+		title:     "🎮 GPU",
+		key:       'g',
+		readings: func(w io.Writer, m *metrics.SystemMetrics) {
+			fmt.Fprintf(w, "  Model: %s\n", m.GPUModel)
+			fmt.Fprintf(w, "  GPU usage isn't measured on this system yet.\n")
+		},
+	}
+}
+
+func fixed(top float64) func(*metrics.SystemMetrics) float64 {
+	return func(*metrics.SystemMetrics) float64 { return top }
+}
+
+func (c *ComponentView) Title() string { return c.component }
+func (c *ComponentView) Key() rune     { return c.key }
+
+func (c *ComponentView) HandleKey(k Key) bool {
+	if !k.Is('w') || len(c.charts) == 0 {
+		return false
+	}
+	c.window = (c.window + 1) % len(ChartWindows)
+	return true
+}
+
+func (c *ComponentView) Render(w io.Writer, s *State, width int) {
+	m := s.Snapshot
+	fmt.Fprintf(w, "%s%s%s\n", render.ColorBold+render.ColorCyan, c.title, render.ColorReset)
+	c.readings(w, m)
+
+	fmt.Fprintln(w)
+	if len(c.charts) > 0 && s.Trend != nil {
+		windowBar(w, c.window)
+		for _, chart := range c.charts {
+			if chart.when != nil && !chart.when(m) {
+				continue
+			}
+			drawChart(w, s, ChartWindows[c.window], width, chart.title, chart.metric, chartHeight, chart.top(m), chart.format)
+		}
+		chartAnnotations(w, s, ChartWindows[c.window])
+	}
+
+	var recs []analysis.Recommendation
+	for _, rec := range s.Recommendations {
+		if rec.Component == c.component {
+			recs = append(recs, rec)
+		}
+	}
+	fmt.Fprintf(w, "%sRecommendations%s\n", render.ColorBold, render.ColorReset)
+	if len(recs) == 0 {
+		fmt.Fprintf(w, "%s✅ No %s bottlenecks detected%s\n", render.ColorGreen, c.component, render.ColorReset)
+	} else {
+		render.RecommendationList(w, recs)
+	}
+
+	if c.usage == nil {
+		return
+	}
+	procs := append([]metrics.ProcessInfo(nil), m.Processes...)
+	sort.SliceStable(procs, func(i, j int) bool { return c.usage(procs[i], procs[j]) })
+	fmt.Fprintf(w, "\n%sTop Processes by %s%s\n", render.ColorBold, c.usageName, render.ColorReset)
+	render.TopProcesses(w, procs, componentProcesses)
+}
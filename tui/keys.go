@@ -0,0 +1,99 @@
+package tui
+
+import "unicode/utf8"
+
+// KeyCode identifies a key that doesn't produce a character
+type KeyCode int
+
+const (
+	// KeyRune is a printable character, held in Key.Rune
+	KeyRune KeyCode = iota
+	KeyUp
+	KeyDown
+	KeyLeft
+	KeyRight
+	KeyPgUp
+	KeyPgDn
+	KeyHome
+	KeyEnd
+	KeyEnter
+	KeyEsc
+	KeyTab
+	KeyBackspace
+	KeyDelete
+	KeyCtrlC
+)
+
+// Key is one key press read in raw mode
+type Key struct {
+	Code KeyCode
+	Rune rune
+}
+
+// Is reports whether k is the character r
+func (k Key) Is(r rune) bool {
+	return k.Code == KeyRune && k.Rune == r
+}
+
+// escapes maps the terminal sequences after ESC to keys. Terminals differ,
+// so several sequences map to the same key.
+var escapes = map[string]KeyCode{
+	"[A": KeyUp, "[B": KeyDown, "[C": KeyRight, "[D": KeyLeft,
+	"OA": KeyUp, "OB": KeyDown, "OC": KeyRight, "OD": KeyLeft,
+	"[H": KeyHome, "[F": KeyEnd, "OH": KeyHome, "OF": KeyEnd,
+	"[1~": KeyHome, "[7~": KeyHome, "[4~": KeyEnd, "[8~": KeyEnd,
+	"[5~": KeyPgUp, "[6~": KeyPgDn, "[3~": KeyDelete,
+}
+
+// decodeKeys splits one read from the terminal into keys. A read holding
+// only ESC is the Escape key; unknown escape sequences are dropped.
+func decodeKeys(b []byte) []Key {
+	var keys []Key
+	for len(b) > 0 {
+		switch c := b[0]; {
+		case c == 0x1b:
+			if len(b) == 1 {
+				return append(keys, Key{Code: KeyEsc})
+			}
+			n := escapeLength(b)
+			if code, ok := escapes[string(b[1:n])]; ok {
+				keys = append(keys, Key{Code: code})
+			} else if n == 1 {
+				keys = append(keys, Key{Code: KeyEsc})
+			}
+			b = b[n:]
+			continue
+		case c == '\r' || c == '\n':
+			keys = append(keys, Key{Code: KeyEnter})
+		case c == '\t':
+			keys = append(keys, Key{Code: KeyTab})
+		case c == 0x7f || c == 0x08:
+			keys = append(keys, Key{Code: KeyBackspace})
+		case c == 0x03:
+			keys = append(keys, Key{Code: KeyCtrlC})
+		case c < 0x20:
+			// Other control characters have no binding
+		default:
+			r, size := utf8.DecodeRune(b)
+			keys = append(keys, Key{Code: KeyRune, Rune: r})
+			b = b[size:]
+			continue
+		}
+		b = b[1:]
+	}
+	return keys
+}
+
+// escapeLength returns the length of the escape sequence at the start of b:
+// ESC, then "[" or "O", parameters, and a final letter or "~"
+func escapeLength(b []byte) int {
+	if len(b) < 2 || b[1] != '[' && b[1] != 'O' {
+		return 1
+	}
+	for i := 2; i < len(b); i++ {
+		if c := b[i]; c >= 'A' && c <= 'Z' || c >= 'a' && c <= 'z' || c == '~' {
+			return i + 1
+		}
+	}
+	return len(b)
+}
@@ -0,0 +1,36 @@
+package tui
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDecodeKeys(t *testing.T) {
+	r := func(c rune) Key { return Key{Code: KeyRune, Rune: c} }
+	k := func(code KeyCode) Key { return Key{Code: code} }
+	tests := []struct {
+		name string
+		in   string
+		want []Key
+	}{
+		{"letters", "qp", []Key{r('q'), r('p')}},
+		{"utf-8", "ü€", []Key{r('ü'), r('€')}},
+		{"controls", "\r\n\t\x7f\x08\x03", []Key{k(KeyEnter), k(KeyEnter), k(KeyTab), k(KeyBackspace), k(KeyBackspace), k(KeyCtrlC)}},
+		{"unbound controls", "\x01a\x1f", []Key{r('a')}},
+		{"escape alone", "\x1b", []Key{k(KeyEsc)}},
+		{"escape twice", "\x1b\x1b", []Key{k(KeyEsc), k(KeyEsc)}},
+		{"escape then a letter", "\x1bq", []Key{k(KeyEsc), r('q')}},
+		{"arrows", "\x1b[A\x1b[B\x1bOC\x1bOD", []Key{k(KeyUp), k(KeyDown), k(KeyRight), k(KeyLeft)}},
+		{"home and end", "\x1b[H\x1b[4~\x1b[1~\x1bOF", []Key{k(KeyHome), k(KeyEnd), k(KeyHome), k(KeyEnd)}},
+		{"paging and delete", "\x1b[5~\x1b[6~\x1b[3~", []Key{k(KeyPgUp), k(KeyPgDn), k(KeyDelete)}},
+		{"unknown sequences are dropped", "a\x1b[Z\x1b[1;5Cb", []Key{r('a'), r('b')}},
+		{"truncated sequence", "a\x1b[", []Key{r('a')}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := decodeKeys([]byte(tt.in)); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("decodeKeys(%q) = %v, want %v", tt.in, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,494 @@
+package tui
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/xmarkclx/bottleneck-check/procs"
+	"github.com/xmarkclx/bottleneck-check/render"
+)
+
+// rateWarmup is how long the first listing waits to measure CPU and I/O
+// rates, which need two readings
+const rateWarmup = 500 * time.Millisecond
+
+// detailRows is the height of the details of the selected process, with
+// the blank line above them
+const detailRows = 5
+
+// promptKind is what a ProcessView prompt asks for
+type promptKind int
+
+const (
+	promptFilter promptKind = iota
+	promptNice
+	promptConfirm
+)
+
+// prompt is the question a ProcessView is waiting on. While one is open,
+// the view takes every key but Ctrl-C.
+type prompt struct {
+	kind  promptKind
+	input string
+	// The confirmed action applies to proc: a signal, or a renice to nice
+	// when action is empty
+	action procs.Action
+	nice   int
+	proc   procs.Process
+}
+
+// ProcessView is a table of every process, sortable by CPU, memory, swap
+// or I/O and filtered by name or user. Actions on the selected process ask
+// for confirmation before they run. Processes are listed, details read and
+// actions run in the background, as they wait on the system.
+type ProcessView struct {
+	table  *procs.Table
+	redraw func()
+
+	// mu guards the view against the background work: the listing, which
+	// replaces all and err, reading details and running actions
+	mu      sync.Mutex
+	all     []procs.Process
+	err     error
+	listed  bool      // a listing has completed
+	listing bool      // a listing is running
+	wanted  time.Time // State.Updated of the latest listing; zero forces a new one
+
+	sort   int // index into procs.SortKeys
+	filter string
+
+	// The selection follows its PID when the table is re-sorted or
+	// refreshed
+	selected int32
+	cursor   int
+	top      int
+	rows     int
+
+	// details are of the process detailsPID, read in the background once
+	// per listing; nil if it has exited or they haven't been read yet
+	details        *procs.Details
+	detailsPID     int32
+	detailsRead    bool
+	readingDetails bool
+
+	prompt     *prompt
+	message    string
+	messageErr bool
+}
+
+// NewProcessView returns the process table view
+func NewProcessView() *ProcessView {
+	return &ProcessView{table: procs.NewTable()}
+}
+
+func (*ProcessView) Title() string { return "Processes" }
+func (*ProcessView) Key() rune     { return 'p' }
+
+func (v *ProcessView) Fit(rows int) {
+	v.rows = rows
+}
+
+func (v *ProcessView) SetRedraw(redraw func()) {
+	v.redraw = redraw
+}
+
+func (v *ProcessView) Hints() string {
+	key := func(k string) string { return render.ColorGreen + k + render.ColorReset }
+	if v.prompt != nil {
+		return key("Enter") + " ok  " + key("Esc") + " cancel"
+	}
+	return fmt.Sprintf("%s select  %s sort  %s filter  %s term  %s kill  %s stop  %s cont  %s nice  %s quit",
+		key("↑↓"), key("s"), key("/"), key("T"), key("K"), key("S"), key("C"), key("N"), key("q"))
+}
+
+func (v *ProcessView) HandleKey(k Key) bool {
+	if k.Code == KeyCtrlC {
+		return false
+	}
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if v.prompt != nil {
+		v.promptKey(k)
+		return true
+	}
+
+	visible := v.visible()
+	switch {
+	case k.Code == KeyUp || k.Is('k'):
+		v.move(visible, v.cursor-1)
+	case k.Code == KeyDown || k.Is('j'):
+		v.move(visible, v.cursor+1)
+	case k.Code == KeyPgUp:
+		v.move(visible, v.cursor-v.tableRows())
+	case k.Code == KeyPgDn || k.Is(' '):
+		v.move(visible, v.cursor+v.tableRows())
+	case k.Code == KeyHome:
+		v.move(visible, 0)
+	case k.Code == KeyEnd:
+		v.move(visible, len(visible)-1)
+	case k.Is('s'):
+		v.sort = (v.sort + 1) % len(procs.SortKeys)
+	case k.Is('/'):
+		v.prompt = &prompt{kind: promptFilter, input: v.filter}
+	case k.Is('T'), k.Is('K'), k.Is('S'), k.Is('C'), k.Is('N'):
+		if v.cursor >= len(visible) {
+			return true
+		}
+		p := &prompt{kind: promptConfirm, proc: visible[v.cursor]}
+		switch k.Rune {
+		case 'T':
+			p.action = procs.ActionTerminate
+		case 'K':
+			p.action = procs.ActionKill
+		case 'S':
+			p.action = procs.ActionSuspend
+		case 'C':
+			p.action = procs.ActionResume
+		case 'N':
+			p.kind = promptNice
+		}
+		v.prompt = p
+		v.message = ""
+	default:
+		return false
+	}
+	return true
+}
+
+// promptKey edits or answers the open prompt
+func (v *ProcessView) promptKey(k Key) {
+	p := v.prompt
+	if p.kind == promptConfirm {
+		v.prompt = nil
+		if !k.Is('y') && !k.Is('Y') {
+			v.setMessage("Cancelled", false)
+			return
+		}
+		v.run(p)
+		return
+	}
+
+	switch {
+	case k.Code == KeyEsc:
+		if p.kind == promptFilter {
+			v.filter = ""
+		}
+		v.prompt = nil
+	case k.Code == KeyEnter:
+		v.prompt = nil
+		if p.kind == promptNice {
+			nice, err := strconv.Atoi(strings.TrimSpace(p.input))
+			if err != nil || nice < procs.MinNice || nice > procs.MaxNice {
+				v.setMessage(fmt.Sprintf("Nice value must be a number from %d to %d", procs.MinNice, procs.MaxNice), true)
+				return
+			}
+			v.prompt = &prompt{kind: promptConfirm, nice: nice, proc: p.proc}
+		}
+	case k.Code == KeyBackspace:
+		if n := len([]rune(p.input)); n > 0 {
+			p.input = string([]rune(p.input)[:n-1])
+		}
+	case k.Code == KeyRune:
+		if p.kind == promptNice && !strings.ContainsRune("-0123456789", k.Rune) {
+			return
+		}
+		p.input += string(k.Rune)
+	}
+	if p.kind == promptFilter && v.prompt != nil {
+		v.filter = p.input
+	}
+}
+
+// run starts a confirmed action in the background; when it is done, its
+// outcome is shown and the processes are relisted
+func (v *ProcessView) run(p *prompt) {
+	target := fmt.Sprintf("%d (%s)", p.proc.PID, p.proc.Name)
+	v.setMessage("Working on "+target+"...", false)
+	go func() {
+		ctx := context.Background()
+		var msg string
+		var err error
+		if p.action == "" {
+			err = procs.Renice(ctx, p.proc.PID, p.proc.Started, p.nice)
+			msg = fmt.Sprintf("Reniced %s to %d", target, p.nice)
+			if err != nil {
+				msg = fmt.Sprintf("Couldn't renice %s: %v", target, err)
+			}
+		} else {
+			err = procs.Do(ctx, p.proc.PID, p.proc.Started, p.action)
+			msg = fmt.Sprintf("Done: %s %s", p.action.Describe(), target)
+			if err != nil {
+				msg = fmt.Sprintf("Couldn't %s %s: %v", p.action.Describe(), target, err)
+			}
+		}
+
+		v.mu.Lock()
+		v.setMessage(msg, err != nil)
+		if err == nil {
+			v.wanted = time.Time{}
+		}
+		v.mu.Unlock()
+		v.requestRedraw()
+	}()
+}
+
+func (v *ProcessView) requestRedraw() {
+	if v.redraw != nil {
+		v.redraw()
+	}
+}
+
+func (v *ProcessView) setMessage(msg string, isErr bool) {
+	v.message, v.messageErr = msg, isErr
+}
+
+// visible returns the listed processes after filtering and sorting
+func (v *ProcessView) visible() []procs.Process {
+	list := procs.Filter(append([]procs.Process(nil), v.all...), v.filter)
+	procs.Sort(list, procs.SortKeys[v.sort])
+	return list
+}
+
+// move selects the row at index, clamped to the table
+func (v *ProcessView) move(visible []procs.Process, index int) {
+	if len(visible) == 0 {
+		return
+	}
+	v.cursor = min(max(index, 0), len(visible)-1)
+	v.selected = visible[v.cursor].PID
+}
+
+// tableRows is how many processes fit under the status lines and header,
+// leaving room for the details
+func (v *ProcessView) tableRows() int {
+	return max(v.rows-3-detailRows, 3)
+}
+
+func (v *ProcessView) Render(w io.Writer, s *State, width int) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if !v.listing && !v.wanted.Equal(s.Updated) {
+		v.wanted, v.listing = s.Updated, true
+		go v.list()
+	}
+	visible := v.visible()
+
+	// Keep the selected process selected, wherever it moved to
+	v.cursor = min(v.cursor, max(len(visible)-1, 0))
+	for i, p := range visible {
+		if p.PID == v.selected {
+			v.cursor = i
+			break
+		}
+	}
+	if len(visible) > 0 {
+		v.selected = visible[v.cursor].PID
+	}
+
+	rows := v.tableRows()
+	v.top = min(max(v.top, v.cursor-rows+1), v.cursor)
+	v.top = max(min(v.top, len(visible)-rows), 0)
+
+	line := func(s string) { fmt.Fprintln(w, Truncate(s, width)) }
+	line(v.statusLine(len(visible)))
+	line(v.promptLine())
+	if v.err != nil {
+		line(fmt.Sprintf("%sCouldn't list processes: %v%s", render.ColorRed, v.err, render.ColorReset))
+		return
+	}
+	if !v.listed {
+		line("Listing processes...")
+		return
+	}
+
+	nameWidth := max(width-56, 10)
+	line(fmt.Sprintf("%s%7s %-10s %-*s %6s %7s %7s %8s %3s%s", render.ColorBold,
+		"PID", "USER", nameWidth, "NAME", "CPU%", "RSS", "SWAP", "IO/s", "NI", render.ColorReset))
+	for i := v.top; i < v.top+rows; i++ {
+		if i >= len(visible) {
+			fmt.Fprintln(w)
+			continue
+		}
+		p := visible[i]
+		row := fmt.Sprintf("%7d %-10s %-*s %6.1f %7s %7s %8s %3d",
+			p.PID, clip(p.User, 10), nameWidth, clip(p.Name, nameWidth), p.CPUPercent,
+			compactBytes(float64(p.RSS)), compactBytes(float64(p.Swap)), compactBytes(p.IORate), p.Nice)
+		switch {
+		case i == v.cursor && !s.Profile.Color:
+			// Without reverse video, point at the selected row instead
+			row = ">" + strings.TrimPrefix(row, " ")
+		case i == v.cursor:
+			row = "\x1b[7m" + Pad(row, width) + render.ColorReset
+		}
+		line(row)
+	}
+
+	fmt.Fprintln(w)
+	if len(visible) > 0 {
+		v.renderDetails(w, visible[v.cursor], width)
+	}
+}
+
+// list reads the processes in the background and asks for a redraw; the
+// first listing also waits to measure rates. Only one runs at a time.
+func (v *ProcessView) list() {
+	ctx := context.Background()
+	v.mu.Lock()
+	first := !v.listed
+	v.mu.Unlock()
+	if first {
+		v.table.List(ctx)
+		time.Sleep(rateWarmup)
+	}
+	all, err := v.table.List(ctx)
+
+	v.mu.Lock()
+	v.all, v.err = all, err
+	v.listed, v.listing = true, false
+	v.detailsRead = false
+	v.mu.Unlock()
+	v.requestRedraw()
+}
+
+// readDetails reads the details of one process in the background and asks
+// for a redraw. Only one read runs at a time; the next redraw starts
+// another if the selection moved meanwhile.
+func (v *ProcessView) readDetails(pid int32) {
+	details, _ := procs.ReadDetails(context.Background(), pid)
+
+	v.mu.Lock()
+	v.readingDetails = false
+	if pid == v.detailsPID {
+		v.details, v.detailsRead = details, true
+	}
+	v.mu.Unlock()
+	v.requestRedraw()
+}
+
+func (v *ProcessView) statusLine(shown int) string {
+	var b strings.Builder
+	b.WriteString("Sort:")
+	for i, key := range procs.SortKeys {
+		if i == v.sort {
+			fmt.Fprintf(&b, " %s[%s]%s", render.ColorGreen+render.ColorBold, key, render.ColorReset)
+		} else {
+			fmt.Fprintf(&b, "  %s ", key)
+		}
+	}
+	if v.filter != "" {
+		fmt.Fprintf(&b, "   Filter: %s%s%s", render.ColorYellow, v.filter, render.ColorReset)
+	}
+	fmt.Fprintf(&b, "   %d of %d processes", shown, len(v.all))
+	return b.String()
+}
+
+func (v *ProcessView) promptLine() string {
+	p := v.prompt
+	if p == nil {
+		if v.message == "" {
+			return ""
+		}
+		color := render.ColorGreen
+		if v.messageErr {
+			color = render.ColorRed
+		}
+		return color + v.message + render.ColorReset
+	}
+	target := fmt.Sprintf("%d (%s)", p.proc.PID, p.proc.Name)
+	switch p.kind {
+	case promptFilter:
+		return "Filter by name or user: " + p.input + "█"
+	case promptNice:
+		return fmt.Sprintf("New nice value for %s, now %d (%d to %d): %s█", target, p.proc.Nice, procs.MinNice, procs.MaxNice, p.input)
+	}
+	question := fmt.Sprintf("Renice %s from %d to %d?", target, p.proc.Nice, p.nice)
+	if p.action != "" {
+		question = fmt.Sprintf("%s %s?", capitalize(p.action.Describe()), target)
+	}
+	return fmt.Sprintf("%s%s%s [y/N]", render.ColorYellow+render.ColorBold, question, render.ColorReset)
+}
+
+// renderDetails shows the selected process, starting to read its details
+// when they haven't been read since the last listing
+func (v *ProcessView) renderDetails(w io.Writer, p procs.Process, width int) {
+	if v.detailsPID != p.PID {
+		v.detailsPID, v.details, v.detailsRead = p.PID, nil, false
+	}
+	if !v.detailsRead && !v.readingDetails {
+		v.readingDetails = true
+		go v.readDetails(p.PID)
+	}
+	line := func(s string) { fmt.Fprintln(w, Truncate(s, width)) }
+	label := func(s string) string { return render.ColorBlue + s + render.ColorReset }
+	line(fmt.Sprintf("%s %d %s · user %s · state %s · nice %d", label("Selected:"), p.PID, p.Name, p.User, p.Status, p.Nice))
+	d := v.details
+	if !v.detailsRead && d == nil {
+		// Until they are read again, the last details stay on screen
+		line("Reading details...")
+		return
+	}
+	if d == nil {
+		line("The process has exited.")
+		return
+	}
+	line(label("Command: ") + d.Cmdline)
+	cgroup := d.Cgroup
+	if cgroup == "" {
+		cgroup = "-"
+	}
+	line(label("Cgroup: ") + cgroup)
+	files := "unknown"
+	if d.OpenFiles >= 0 {
+		files = strconv.Itoa(int(d.OpenFiles))
+	}
+	started := "-"
+	if !d.Started.IsZero() {
+		started = d.Started.Format("2006-01-02 15:04:05")
+	}
+	line(fmt.Sprintf("%s %d · %s %s · %s %d · %s %s", label("Threads:"), d.Threads, label("Open files:"), files, label("Parent:"), d.PPID, label("Started:"), started))
+}
+
+// compactBytes formats a size or rate in at most 6 cells, e.g. "512K",
+// "279M" or "1.5G"; zero is "-"
+func compactBytes(b float64) string {
+	switch {
+	case b <= 0:
+		return "-"
+	case b < 1024:
+		return fmt.Sprintf("%.0fB", b)
+	case b < 1024*1024:
+		return fmt.Sprintf("%.0fK", b/1024)
+	case b < 1024*1024*1024:
+		return fmt.Sprintf("%.0fM", b/(1024*1024))
+	}
+	return fmt.Sprintf("%.1fG", b/(1024*1024*1024))
+}
+
+// clip cuts plain text to width cells, marking the cut with an ellipsis
+func clip(s string, width int) string {
+	if Width(s) <= width {
+		return s
+	}
+	var b strings.Builder
+	cells := 0
+	for _, r := range s {
+		if cells+RuneWidth(r) > width-1 {
+			break
+		}
+		b.WriteRune(r)
+		cells += RuneWidth(r)
+	}
+	return b.String() + "…"
+}
+
+func capitalize(s string) string {
+	if s == "" {
+		return s
+	}
+	return strings.ToUpper(s[:1]) + s[1:]
+}
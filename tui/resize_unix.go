@@ -0,0 +1,28 @@
+//go:build !windows
+
+package tui
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// watchResize forwards SIGWINCH
+func watchResize(ctx context.Context, _ *Terminal, resized chan<- struct{}) {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGWINCH)
+	defer signal.Stop(signals)
+	for {
+		select {
+		case <-signals:
+			select {
+			case resized <- struct{}{}:
+			default:
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
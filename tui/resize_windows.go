@@ -0,0 +1,31 @@
+//go:build windows
+
+package tui
+
+import (
+	"context"
+	"time"
+)
+
+// watchResize polls the size, since Windows consoles have no SIGWINCH
+func watchResize(ctx context.Context, t *Terminal, resized chan<- struct{}) {
+	width, height := t.Size()
+	ticker := time.NewTicker(250 * time.Millisecond)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			w, h := t.Size()
+			if w == width && h == height {
+				continue
+			}
+			width, height = w, h
+			select {
+			case resized <- struct{}{}:
+			default:
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
@@ -0,0 +1,51 @@
+package tui
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+)
+
+// Synchronized output: terminals that support it show the whole frame at
+// once; others ignore the sequences
+const (
+	beginSync = "\x1b[?2026h"
+	endSync   = "\x1b[?2026l"
+)
+
+// Screen draws frames of lines to a terminal. It rewrites only the lines
+// that changed since the previous frame and never clears the screen between
+// frames, so redraws don't flicker.
+type Screen struct {
+	out  io.Writer
+	prev []string
+}
+
+// NewScreen returns a Screen writing to out
+func NewScreen(out io.Writer) *Screen {
+	return &Screen{out: out}
+}
+
+// Invalidate forces the next Draw to repaint everything, e.g. after a
+// resize
+func (s *Screen) Invalidate() {
+	s.prev = nil
+	io.WriteString(s.out, clearScreen)
+}
+
+// Draw shows a frame with one line per terminal row. Lines must already
+// fit the terminal width.
+func (s *Screen) Draw(frame []string) error {
+	var buf bytes.Buffer
+	buf.WriteString(beginSync)
+	for row, line := range frame {
+		if row < len(s.prev) && s.prev[row] == line {
+			continue
+		}
+		fmt.Fprintf(&buf, "\x1b[%d;1H%s%s\x1b[K", row+1, line, resetStyle)
+	}
+	buf.WriteString(endSync)
+	s.prev = frame
+	_, err := s.out.Write(buf.Bytes())
+	return err
+}
@@ -0,0 +1,102 @@
+package tui
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+
+	"golang.org/x/term"
+
+	"github.com/xmarkclx/bottleneck-check/render"
+)
+
+// ErrNotTerminal is returned by Open when stdin or stdout isn't a terminal,
+// or the terminal can't move the cursor: TERM is dumb or a Windows console
+// lacks escape sequence support
+var ErrNotTerminal = errors.New("not a terminal")
+
+// Escape sequences for the full-screen mode
+const (
+	enterAltScreen = "\x1b[?1049h"
+	exitAltScreen  = "\x1b[?1049l"
+	hideCursor     = "\x1b[?25l"
+	showCursor     = "\x1b[?25h"
+	clearScreen    = "\x1b[2J"
+)
+
+// Terminal is the controlling terminal in raw mode on the alternate screen
+type Terminal struct {
+	in    *os.File
+	out   *os.File
+	saved *term.State
+}
+
+// Open switches the terminal to raw mode and the alternate screen. Call
+// Close to restore it.
+func Open() (*Terminal, error) {
+	in, out := os.Stdin, os.Stdout
+	if !term.IsTerminal(int(in.Fd())) || !term.IsTerminal(int(out.Fd())) {
+		return nil, ErrNotTerminal
+	}
+	if os.Getenv("TERM") == "dumb" || !render.EnableVirtualTerminal(out) {
+		return nil, ErrNotTerminal
+	}
+	saved, err := term.MakeRaw(int(in.Fd()))
+	if err != nil {
+		return nil, err
+	}
+	io.WriteString(out, enterAltScreen+hideCursor+clearScreen)
+	return &Terminal{in: in, out: out, saved: saved}, nil
+}
+
+// Close leaves the alternate screen and restores the terminal mode
+func (t *Terminal) Close() error {
+	io.WriteString(t.out, showCursor+exitAltScreen)
+	return term.Restore(int(t.in.Fd()), t.saved)
+}
+
+// Size returns the terminal width and height in cells
+func (t *Terminal) Size() (width, height int) {
+	width, height, err := term.GetSize(int(t.out.Fd()))
+	if err != nil || width <= 0 || height <= 0 {
+		return 80, 24
+	}
+	return width, height
+}
+
+// Write writes directly to the terminal
+func (t *Terminal) Write(p []byte) (int, error) {
+	return t.out.Write(p)
+}
+
+// Keys reads key presses until ctx is cancelled or stdin closes. This is
+// the only reader of stdin, so no key is lost to a competing reader.
+func (t *Terminal) Keys(ctx context.Context) <-chan Key {
+	keys := make(chan Key, 16)
+	go func() {
+		defer close(keys)
+		buf := make([]byte, 64)
+		for {
+			n, err := t.in.Read(buf)
+			if err != nil {
+				return
+			}
+			for _, k := range decodeKeys(buf[:n]) {
+				select {
+				case keys <- k:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+	return keys
+}
+
+// Resizes signals when the terminal size changes
+func (t *Terminal) Resizes(ctx context.Context) <-chan struct{} {
+	resized := make(chan struct{}, 1)
+	go watchResize(ctx, t, resized)
+	return resized
+}
@@ -0,0 +1,172 @@
+package tui
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+const resetStyle = "\x1b[0m"
+
+// RuneWidth returns the number of cells a rune takes: 0 for combining
+// marks and format characters such as variation selectors, 2 for wide East
+// Asian characters and emoji, 1 otherwise
+func RuneWidth(r rune) int {
+	switch {
+	case r == 0 || unicode.In(r, unicode.Mn, unicode.Me, unicode.Cf):
+		return 0
+	case r >= 0x1100 && r <= 0x115f,
+		r >= 0x2e80 && r <= 0xa4cf,
+		r >= 0xac00 && r <= 0xd7a3,
+		r >= 0xf900 && r <= 0xfaff,
+		r >= 0xfe30 && r <= 0xfe4f,
+		r >= 0xff00 && r <= 0xff60,
+		r >= 0xffe0 && r <= 0xffe6,
+		r >= 0x1f300 && r <= 0x1faff:
+		return 2
+	}
+	return 1
+}
+
+// escapeAt returns the length of the ANSI escape sequence starting at s[i],
+// or 0 if there is none
+func escapeAt(s string, i int) int {
+	if s[i] != 0x1b || i+1 >= len(s) || s[i+1] != '[' {
+		return 0
+	}
+	for j := i + 2; j < len(s); j++ {
+		if c := s[j]; c >= 0x40 && c <= 0x7e {
+			return j - i + 1
+		}
+	}
+	return len(s) - i
+}
+
+// Width returns the number of cells s takes, ignoring escape sequences
+func Width(s string) int {
+	width := 0
+	for i := 0; i < len(s); {
+		if n := escapeAt(s, i); n > 0 {
+			i += n
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(s[i:])
+		width += RuneWidth(r)
+		i += size
+	}
+	return width
+}
+
+// Truncate cuts s to at most width cells, keeping escape sequences intact
+func Truncate(s string, width int) string {
+	cells := 0
+	for i := 0; i < len(s); {
+		if n := escapeAt(s, i); n > 0 {
+			i += n
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(s[i:])
+		if cells+RuneWidth(r) > width {
+			return s[:i] + resetStyle
+		}
+		cells += RuneWidth(r)
+		i += size
+	}
+	return s
+}
+
+// Pad extends s with spaces to width cells
+func Pad(s string, width int) string {
+	return s + strings.Repeat(" ", max(width-Width(s), 0))
+}
+
+// Wrap breaks a line into lines of at most width cells, at spaces where
+// possible. Continuation lines keep the line's indentation, plus the width
+// of a leading bullet, and the colors active where the break fell.
+func Wrap(line string, width int) []string {
+	if width <= 0 || Width(line) <= width {
+		return []string{line}
+	}
+	indent := continuationIndent(line)
+	if indent >= width/2 {
+		indent = 0
+	}
+
+	var lines []string
+	var cur strings.Builder
+	cells := 0
+	style := ""   // escape sequences active at the current position
+	prefix := 0   // bytes of indentation and style that start cur
+	breakAt := -1 // byte offset in cur just after the last space
+	breakStyle := ""
+
+	flush := func(upTo int, nextStyle string) {
+		text := cur.String()
+		lines = append(lines, strings.TrimRight(text[:upTo], " ")+resetStyle)
+		rest := strings.TrimLeft(text[upTo:], " ")
+		start := strings.Repeat(" ", indent) + nextStyle
+		cur.Reset()
+		cur.WriteString(start + rest)
+		prefix = len(start)
+		cells = indent + Width(rest)
+		breakAt = -1
+	}
+
+	for i := 0; i < len(line); {
+		if n := escapeAt(line, i); n > 0 {
+			seq := line[i : i+n]
+			if seq == resetStyle || seq == "\x1b[m" {
+				style = ""
+			} else if seq[len(seq)-1] == 'm' {
+				style += seq
+			}
+			cur.WriteString(seq)
+			i += n
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(line[i:])
+		w := RuneWidth(r)
+		if cells+w > width {
+			if breakAt > prefix {
+				flush(breakAt, breakStyle)
+			} else {
+				flush(cur.Len(), style)
+			}
+		}
+		cur.WriteRune(r)
+		cells += w
+		if r == ' ' {
+			breakAt, breakStyle = cur.Len(), style
+		}
+		i += size
+	}
+	lines = append(lines, cur.String())
+	return lines
+}
+
+// continuationIndent is the indentation of a line's text after leading
+// spaces and a bullet such as "• " or "→ "
+func continuationIndent(line string) int {
+	plain := stripEscapes(line)
+	trimmed := strings.TrimLeft(plain, " ")
+	indent := len(plain) - len(trimmed)
+	for _, bullet := range []string{"• ", "→ ", "- "} {
+		if strings.HasPrefix(trimmed, bullet) {
+			return indent + Width(bullet)
+		}
+	}
+	return indent
+}
+
+func stripEscapes(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); {
+		if n := escapeAt(s, i); n > 0 {
+			i += n
+			continue
+		}
+		b.WriteByte(s[i])
+		i++
+	}
+	return b.String()
+}
@@ -0,0 +1,132 @@
+package tui
+
+import (
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/xmarkclx/bottleneck-check/metrics"
+)
+
+// DefaultTrendKeep is how long a Trend keeps readings: the longest chart
+// window
+const DefaultTrendKeep = 24 * time.Hour
+
+// trendMetrics are the metrics a Trend keeps
+var trendMetrics = []string{
+	metrics.MetricCPUUsage,
+	metrics.MetricLoad1,
+	metrics.MetricMemoryUsedPercent,
+	metrics.MetricSwapUsedBytes,
+	metrics.MetricMemoryPressure,
+}
+
+// Trend keeps the charted metrics of every sample in memory. The sampler's
+// own history holds whole snapshots, so it covers only the last half hour;
+// a day of these readings takes a few megabytes.
+type Trend struct {
+	mu     sync.Mutex
+	keep   time.Duration
+	times  []time.Time
+	values [][]float64 // per sample, in trendMetrics order; NaN if missing
+}
+
+// NewTrend returns a Trend keeping readings for keep, or DefaultTrendKeep
+// if keep isn't positive
+func NewTrend(keep time.Duration) *Trend {
+	if keep <= 0 {
+		keep = DefaultTrendKeep
+	}
+	return &Trend{keep: keep}
+}
+
+// Add records a snapshot's readings and drops those older than the keep
+// period
+func (t *Trend) Add(m *metrics.SystemMetrics) {
+	values := m.Values()
+	row := make([]float64, len(trendMetrics))
+	for i, name := range trendMetrics {
+		v, ok := values[name]
+		if !ok {
+			v = math.NaN()
+		}
+		row[i] = v
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.times = append(t.times, m.Timestamp)
+	t.values = append(t.values, row)
+
+	// Trim in batches so each sample doesn't copy the whole day
+	cutoff := m.Timestamp.Add(-t.keep)
+	cut := sort.Search(len(t.times), func(i int) bool { return !t.times[i].Before(cutoff) })
+	if cut > 0 && (cut >= 1024 || cut*4 >= len(t.times)) {
+		t.times = append([]time.Time(nil), t.times[cut:]...)
+		t.values = append([][]float64(nil), t.values[cut:]...)
+	}
+}
+
+// Record adds snapshots until the channel closes
+func (t *Trend) Record(snapshots <-chan metrics.SystemMetrics) {
+	for m := range snapshots {
+		t.Add(&m)
+	}
+}
+
+// Columns splits the span ending at end into n equal columns and returns
+// the peak of a metric in each, so short spikes stay visible. Columns
+// narrower than the sampling interval repeat the previous reading; columns
+// without readings nearby, e.g. before the monitor started, are NaN.
+func (t *Trend) Columns(metric string, span time.Duration, n int, end time.Time) []float64 {
+	index := -1
+	for i, name := range trendMetrics {
+		if name == metric {
+			index = i
+		}
+	}
+	columns := make([]float64, n)
+	for i := range columns {
+		columns[i] = math.NaN()
+	}
+	if index < 0 || n <= 0 || span <= 0 {
+		return columns
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	start := end.Add(-span)
+	step := span / time.Duration(n)
+	latest := make([]time.Time, n)
+	first := sort.Search(len(t.times), func(i int) bool { return t.times[i].After(start) })
+	for i := first; i < len(t.times) && !t.times[i].After(end); i++ {
+		v := t.values[i][index]
+		if math.IsNaN(v) {
+			continue
+		}
+		col := min(max(int(t.times[i].Sub(start)/step), 0), n-1)
+		if math.IsNaN(columns[col]) || v > columns[col] {
+			columns[col] = v
+		}
+		latest[col] = t.times[i]
+	}
+
+	// Bridge columns that fall between two samples
+	var prev time.Time
+	for col := range columns {
+		if !math.IsNaN(columns[col]) {
+			prev = latest[col]
+			continue
+		}
+		colStart := start.Add(time.Duration(col) * step)
+		if col > 0 && !prev.IsZero() && colStart.Sub(prev) < max(step, maxSampleGap) {
+			columns[col] = columns[col-1]
+		}
+	}
+	return columns
+}
+
+// maxSampleGap is the longest time between samples that charts draw as
+// continuous
+const maxSampleGap = 10 * time.Second
@@ -0,0 +1,242 @@
+package tui
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/xmarkclx/bottleneck-check/events"
+	"github.com/xmarkclx/bottleneck-check/history"
+	"github.com/xmarkclx/bottleneck-check/metrics"
+	"github.com/xmarkclx/bottleneck-check/render"
+)
+
+// OverviewView shows the live status, recommendations and anomalies
+type OverviewView struct{}
+
+func (OverviewView) Title() string { return "Overview" }
+func (OverviewView) Key() rune     { return 'o' }
+
+func (OverviewView) Render(w io.Writer, s *State, width int) {
+	render.QuickStatus(w, s.Snapshot, recent(s.History, render.QuickTrendSpan))
+	fmt.Fprintln(w)
+	render.SystemStatus(w, s.Snapshot)
+	render.Recommendations(w, s.Recommendations)
+	render.Anomalies(w, s.Anomalies)
+}
+
+// DetailsView shows hardware details, recent history and the anomaly
+// baseline
+type DetailsView struct{}
+
+func (DetailsView) Title() string { return "Details" }
+func (DetailsView) Key() rune     { return 'd' }
+
+func (DetailsView) Render(w io.Writer, s *State, width int) {
+	render.DetailedInfo(w, s.Snapshot)
+	render.HistorySummary(w, s.History)
+	if s.StoreDir == "" {
+		return
+	}
+	fmt.Fprintf(w, "\n%sRecording history to:%s %s\n", render.ColorBlue, render.ColorReset, s.StoreDir)
+	if s.StoreErr != nil {
+		fmt.Fprintf(w, "  %sLast write failed: %v%s\n", render.ColorRed, s.StoreErr, render.ColorReset)
+	}
+	render.AnomalyBaseline(w, s.Baseline)
+}
+
+// ChartWindows are the spans the charts view cycles through
+var ChartWindows = []time.Duration{5 * time.Minute, time.Hour, 24 * time.Hour}
+
+// ChartsView charts CPU, memory, swap and memory pressure over a window
+// chosen with the w key
+type ChartsView struct {
+	window int // index into ChartWindows
+}
+
+// chartHeight is the height of each chart in rows
+const chartHeight = 5
+
+func (*ChartsView) Title() string { return "Charts" }
+func (*ChartsView) Key() rune     { return 't' }
+
+func (c *ChartsView) HandleKey(k Key) bool {
+	if !k.Is('w') {
+		return false
+	}
+	c.window = (c.window + 1) % len(ChartWindows)
+	return true
+}
+
+func (c *ChartsView) Render(w io.Writer, s *State, width int) {
+	windowBar(w, c.window)
+	if s.Trend == nil {
+		return
+	}
+	span := ChartWindows[c.window]
+	percent := render.FormatValue("percent")
+	drawChart(w, s, span, width, "CPU", metrics.MetricCPUUsage, chartHeight, 100, percent)
+	drawChart(w, s, span, width, "Memory", metrics.MetricMemoryUsedPercent, chartHeight, 100, percent)
+	if s.Snapshot.SwapTotal > 0 {
+		drawChart(w, s, span, width, "Swap", metrics.MetricSwapUsedBytes, chartHeight, float64(s.Snapshot.SwapTotal), render.FormatValue("bytes"))
+	}
+	drawChart(w, s, span, width, "Memory Pressure", metrics.MetricMemoryPressure, 3, 2, pressureName)
+	chartAnnotations(w, s, span)
+}
+
+// windowBar shows the chart windows with the current one highlighted
+func windowBar(w io.Writer, current int) {
+	fmt.Fprintf(w, "Window:")
+	for i, d := range ChartWindows {
+		if i == current {
+			fmt.Fprintf(w, " %s[%s]%s", render.ColorGreen+render.ColorBold, render.SpanLabel(d), render.ColorReset)
+		} else {
+			fmt.Fprintf(w, "  %s ", render.SpanLabel(d))
+		}
+	}
+	fmt.Fprintf(w, "  (press %sw%s to change)\n\n", render.ColorGreen, render.ColorReset)
+}
+
+// drawChart charts a metric from the trend over span, as wide as the
+// terminal allows after the scale on the left, with the annotations in it
+// marked on the axis
+func drawChart(w io.Writer, s *State, span time.Duration, width int, title, metric string, height int, top float64, format func(float64) string) {
+	columns := max(width-12, 10) * 2
+	values := s.Trend.Columns(metric, span, columns, s.Updated)
+	start, step := s.Updated.Add(-span), span/time.Duration(columns)
+	var marks []render.Mark
+	for i, a := range annotationsIn(s, span) {
+		col := min(int(a.Time.Sub(start)/step), columns-1)
+		marks = append(marks, render.Mark{Column: col, Label: render.MarkLabel(i)})
+	}
+	render.TimeChart(w, title, values, height, top, span, format, marks)
+	fmt.Fprintln(w)
+}
+
+// annotationsIn returns the annotations made in the last span
+func annotationsIn(s *State, span time.Duration) []history.Annotation {
+	start := s.Updated.Add(-span)
+	var in []history.Annotation
+	for _, a := range s.Annotations {
+		if !a.Time.Before(start) && !a.Time.After(s.Updated) {
+			in = append(in, a)
+		}
+	}
+	return in
+}
+
+// chartAnnotations lists the annotations marked on charts over span, if any
+func chartAnnotations(w io.Writer, s *State, span time.Duration) {
+	annotations := annotationsIn(s, span)
+	if len(annotations) == 0 {
+		return
+	}
+	fmt.Fprintf(w, "%sAnnotations%s\n", render.ColorBold, render.ColorReset)
+	render.AnnotationList(w, annotations)
+	fmt.Fprintln(w)
+}
+
+// EventsView is a timeline of recommendations being raised, escalated,
+// de-escalated and resolved, under a strip of the last day's worst severity
+type EventsView struct{}
+
+// stripSpan is how far back the events view's severity strip goes
+const stripSpan = 24 * time.Hour
+
+func (EventsView) Title() string { return "Events" }
+func (EventsView) Key() rune     { return 'e' }
+
+func (EventsView) Render(w io.Writer, s *State, width int) {
+	columns := max(width-4, 10)
+	end := s.Updated
+	if end.IsZero() {
+		end = time.Now()
+	}
+	worst := events.Worst(s.Events, end.Add(-stripSpan), end, columns)
+	fmt.Fprintf(w, "%sWorst severity, last %d hours%s\n", render.ColorBold, int(stripSpan.Hours()), render.ColorReset)
+	fmt.Fprintf(w, "  %s\n", render.SeverityStrip(worst))
+	left := "-" + render.SpanLabel(stripSpan)
+	fmt.Fprintf(w, "  %s%s%s\n\n", left, strings.Repeat(" ", max(columns-len(left)-3, 1)), "now")
+
+	render.Timeline(w, s.Events, s.Annotations)
+	if s.EventsPath == "" {
+		fmt.Fprintf(w, "\n%sEvents are kept in memory only and lost on exit%s\n", render.ColorYellow, render.ColorReset)
+		return
+	}
+	fmt.Fprintf(w, "\n%sLogging events to:%s %s\n", render.ColorBlue, render.ColorReset, s.EventsPath)
+	if s.EventsErr != nil {
+		fmt.Fprintf(w, "  %sLast write failed: %v%s\n", render.ColorRed, s.EventsErr, render.ColorReset)
+	}
+}
+
+// pressureName labels memory pressure levels
+func pressureName(level float64) string {
+	switch {
+	case level >= 2:
+		return "critical"
+	case level >= 1:
+		return "warning"
+	}
+	return "normal"
+}
+
+// recent returns the samples in the last span of history
+func recent(history []metrics.SystemMetrics, span time.Duration) []metrics.SystemMetrics {
+	if len(history) == 0 {
+		return nil
+	}
+	cutoff := history[len(history)-1].Timestamp.Add(-span)
+	i := sort.Search(len(history), func(i int) bool { return !history[i].Timestamp.Before(cutoff) })
+	return history[i:]
+}
+
+// helpView explains the monitor and lists every view's key
+type helpView struct {
+	app *App
+}
+
+func (*helpView) Title() string { return "Help" }
+func (*helpView) Key() rune     { return 'h' }
+
+func (h *helpView) Render(w io.Writer, s *State, width int) {
+	fmt.Fprintf(w, "%sWhat This Tool Does:%s\n", render.ColorBlue, render.ColorReset)
+	fmt.Fprintf(w, "• Continuously monitors CPU, Memory, and GPU performance\n")
+	fmt.Fprintf(w, "• Provides real-time bottleneck detection\n")
+	fmt.Fprintf(w, "• Raises usage alerts only once they hold for most of the last %s\n", s.Window)
+	fmt.Fprintf(w, "• Shows detailed upgrade recommendations by default\n")
+	fmt.Fprintf(w, "• Samples in the background every %s and refreshes every %s\n\n", s.SampleInterval, s.Refresh)
+
+	fmt.Fprintf(w, "%sKeys:%s\n", render.ColorCyan, render.ColorReset)
+	for i, v := range h.app.views {
+		fmt.Fprintf(w, "%s%c%s or %s%d%s - %s\n", render.ColorGreen, v.Key(), render.ColorReset, render.ColorGreen, i+1, render.ColorReset, v.Title())
+	}
+	fmt.Fprintf(w, "%sTab%s / %s←→%s - Next or previous view\n", render.ColorGreen, render.ColorReset, render.ColorGreen, render.ColorReset)
+	fmt.Fprintf(w, "%sEsc%s - Back to the overview\n", render.ColorGreen, render.ColorReset)
+	fmt.Fprintf(w, "%sBackspace%s - Back to the previous view\n", render.ColorGreen, render.ColorReset)
+	fmt.Fprintf(w, "%s↑↓%s or %sj k%s - Scroll a line\n", render.ColorGreen, render.ColorReset, render.ColorGreen, render.ColorReset)
+	fmt.Fprintf(w, "%sPgUp PgDn%s or %sb Space%s - Scroll a page\n", render.ColorGreen, render.ColorReset, render.ColorGreen, render.ColorReset)
+	fmt.Fprintf(w, "%sHome End%s - Jump to the top or bottom\n", render.ColorGreen, render.ColorReset)
+	fmt.Fprintf(w, "%sw%s - Change the time window of the charts in this view\n", render.ColorGreen, render.ColorReset)
+	fmt.Fprintf(w, "%sa%s - Annotate this moment, e.g. \"started nightly build\"\n", render.ColorGreen, render.ColorReset)
+	fmt.Fprintf(w, "%sr%s - Refresh now\n", render.ColorGreen, render.ColorReset)
+	fmt.Fprintf(w, "%sq%s or %sCtrl-C%s - Quit\n\n", render.ColorGreen, render.ColorReset, render.ColorGreen, render.ColorReset)
+
+	fmt.Fprintf(w, "%sStatus Indicators:%s\n", render.ColorPurple, render.ColorReset)
+	fmt.Fprintf(w, "%s• Green%s - Good performance\n", render.ColorGreen, render.ColorReset)
+	fmt.Fprintf(w, "%s• Yellow%s - Moderate usage/warning\n", render.ColorYellow, render.ColorReset)
+	fmt.Fprintf(w, "%s• Red%s - High usage/critical issue\n\n", render.ColorRed, render.ColorReset)
+
+	fmt.Fprintf(w, "%sRecommendation Levels:%s\n", render.ColorYellow, render.ColorReset)
+	fmt.Fprintf(w, "🚨 CRITICAL - Immediate action required\n")
+	fmt.Fprintf(w, "⚠️  HIGH - Should address soon\n")
+	fmt.Fprintf(w, "📋 MEDIUM - Consider for future upgrades\n")
+	fmt.Fprintf(w, "💡 LOW - Optional improvements\n\n")
+
+	fmt.Fprintf(w, "%sTips for Best Results:%s\n", render.ColorGreen, render.ColorReset)
+	fmt.Fprintf(w, "• Let it run for a few minutes to see usage patterns\n")
+	fmt.Fprintf(w, "• Detailed advice is shown automatically - watch for changes\n")
+	fmt.Fprintf(w, "• Use during your typical workload for accurate assessment\n")
+	fmt.Fprintf(w, "• Address CRITICAL issues first for best performance gains\n")
+}